@@ -0,0 +1,67 @@
+// Command refresh rebuilds daily price history and catalog state once and
+// exits. It is meant for cron and CI: runs never overlap, each run is recorded
+// in catalog_refresh_runs, and any failure exits with status 1.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"tlamasite/apps/api-go/internal/config"
+	"tlamasite/apps/api-go/internal/db"
+	"tlamasite/apps/api-go/internal/refresh"
+)
+
+func main() {
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+	if err := run(); err != nil {
+		log.Fatalf("refresh failed: %v", err)
+	}
+}
+
+func run() error {
+	cfg, err := config.LoadRefresh()
+	if err != nil {
+		return err
+	}
+	// A signal cancels the run; the transaction rolls back and the run is
+	// still recorded as failed.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	pool, err := db.NewPool(ctx, cfg.DatabaseURL, db.PoolOptions{
+		DatabaseRole:   cfg.DatabaseRole,
+		MaxConns:       1,
+		SimpleProtocol: cfg.SimpleProtocol,
+	})
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	result, err := refresh.NewRunner(pool).Run(ctx, refresh.Options{
+		Lookback:    cfg.Lookback,
+		LockTimeout: cfg.LockTimeout,
+	})
+	if err != nil && result.ID != 0 {
+		return fmt.Errorf("run %d: %w", result.ID, err)
+	}
+	if err != nil {
+		return err
+	}
+	slog.Info("refresh_completed",
+		"run_id", result.ID,
+		"since", result.Since,
+		"duration_ms", result.Duration.Milliseconds(),
+		"history", result.History,
+		"state", result.State,
+	)
+	return nil
+}
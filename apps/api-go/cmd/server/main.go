@@ -12,12 +12,19 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"tlamasite/apps/api-go/internal/admin"
+	"tlamasite/apps/api-go/internal/alerts"
+	"tlamasite/apps/api-go/internal/bgg"
 	"tlamasite/apps/api-go/internal/cache"
 	"tlamasite/apps/api-go/internal/catalog"
+	"tlamasite/apps/api-go/internal/collections"
 	"tlamasite/apps/api-go/internal/config"
 	"tlamasite/apps/api-go/internal/db"
 	api "tlamasite/apps/api-go/internal/http"
+	"tlamasite/apps/api-go/internal/mail"
+	"tlamasite/apps/api-go/internal/sellerstats"
 	"tlamasite/apps/api-go/internal/snapshots"
+	"tlamasite/apps/api-go/internal/webhooks"
 )
 
 var (
@@ -49,7 +56,75 @@ func run() error {
 	if cacheClient != nil {
 		defer cacheClient.Close()
 	}
-	return serve(buildServer(cfg, buildHandler(cfg, pool, cacheClient)))
+
+	adminRuntime, err := startAdmin(context.Background(), cfg)
+	if err != nil {
+		return err
+	}
+	defer adminRuntime.stop()
+	sellerStats := startSellerStats(cfg, pool)
+	defer sellerStats.stop()
+	priceWatches, err := startPriceWatches(cfg, pool)
+	if err != nil {
+		return err
+	}
+	defer priceWatches.stop()
+	catalogRepository := catalog.NewRepository(pool, catalog.RepositoryOptions{
+		SummaryRelation: cfg.CatalogSummaryRelation,
+	})
+	service := buildService(cfg, pool, catalogRepository, cacheClient, sellerStats.store)
+	server := buildServer(
+		cfg,
+		buildHandler(cfg, service),
+		api.NewFeedHandler(service, cfg.SiteURL),
+		adminRuntime.handler,
+		priceWatches.handler,
+		api.NewCollectionHandler(collections.NewService(collections.NewRepository(pool), catalogRepository)),
+		api.NewImportHandler(bgg.NewService(bgg.NewRepository(pool), catalogRepository)),
+	)
+	if adminServer := buildAdminServer(cfg, adminRuntime.handler); adminServer != nil {
+		return serve(server, adminServer)
+	}
+	return serve(server)
+}
+
+type adminRunner struct {
+	handler *api.AdminHandler
+	stop    func()
+}
+
+// startAdmin opens a small pool under the maintenance role because raw
+// snapshots and webhook secrets are not readable by the public API role.
+// Admin routes and the webhook dispatcher stay off unless at least one token
+// is configured; without one nobody could manage subscriptions.
+func startAdmin(ctx context.Context, cfg config.Config) (adminRunner, error) {
+	if len(cfg.AdminTokenHashes) == 0 && len(cfg.AdminTokens) == 0 {
+		return adminRunner{stop: func() {}}, nil
+	}
+	pool, err := db.NewPool(ctx, cfg.DatabaseURL, db.PoolOptions{
+		DatabaseRole:    cfg.AdminDatabaseRole,
+		MaxConns:        cfg.AdminDBMaxConns,
+		MaxConnIdleTime: cfg.DBMaxConnIdleTime,
+		MaxConnLifetime: cfg.DBMaxConnLifetime,
+		SimpleProtocol:  cfg.DBSimpleProtocol,
+	})
+	if err != nil {
+		return adminRunner{}, err
+	}
+	dispatcher := webhooks.NewDispatcher(
+		webhooks.NewRepository(pool),
+		webhooks.NewClient(cfg.WebhookTimeout),
+		cfg.WebhookPollInterval,
+		cfg.WebhookMaxAttempts,
+	)
+	stopDispatcher := runInBackground(dispatcher.Run)
+	return adminRunner{
+		handler: api.NewAdminHandler(admin.NewRepository(pool), cfg.AdminTokenHashes, cfg.AdminTokens),
+		stop: func() {
+			stopDispatcher()
+			pool.Close()
+		},
+	}, nil
 }
 
 func openPool(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
@@ -63,15 +138,87 @@ func openPool(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
 	})
 }
 
-func buildHandler(
+type sellerStatsRunner struct {
+	store *sellerstats.Store
+	stop  func()
+}
+
+// startSellerStats runs the competitiveness job in the background so requests
+// only read the last computed report.
+func startSellerStats(cfg config.Config, pool *pgxpool.Pool) sellerStatsRunner {
+	store := sellerstats.NewStore()
+	job := sellerstats.NewJob(
+		sellerstats.NewRepository(pool),
+		store,
+		cfg.SellerStatsInterval,
+		cfg.SellerStatsTimeout,
+	)
+	return sellerStatsRunner{store: store, stop: runInBackground(job.Run)}
+}
+
+type priceWatchRunner struct {
+	handler *api.WatchHandler
+	stop    func()
+}
+
+// startPriceWatches mounts the watch API and runs the evaluator only when a
+// token secret is configured. Every instance evaluates; row locks keep each
+// alert to a single mail.
+func startPriceWatches(cfg config.Config, pool *pgxpool.Pool) (priceWatchRunner, error) {
+	if cfg.WatchTokenSecret == "" {
+		return priceWatchRunner{stop: func() {}}, nil
+	}
+	sender, err := mail.NewSMTPSender(mail.SMTPOptions{
+		Addr:       cfg.SMTPAddr,
+		Username:   cfg.SMTPUsername,
+		Password:   cfg.SMTPPassword,
+		From:       cfg.SMTPFrom,
+		RequireTLS: cfg.SMTPRequireTLS,
+	})
+	if err != nil {
+		return priceWatchRunner{}, err
+	}
+	repository := alerts.NewRepository(pool)
+	tokens := alerts.NewTokens(cfg.WatchTokenSecret)
+	links := alerts.NewLinks(cfg.SiteURL)
+	job := alerts.NewJob(
+		repository,
+		tokens,
+		sender,
+		links,
+		cfg.WatchPollInterval,
+		cfg.WatchEvaluationTimeout,
+	)
+	return priceWatchRunner{
+		handler: api.NewWatchHandler(alerts.NewService(repository, tokens, sender, links)),
+		stop:    runInBackground(job.Run),
+	}, nil
+}
+
+// runInBackground starts a job and returns a function that cancels it and
+// waits for it to return.
+func runInBackground(run func(context.Context)) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func buildService(
 	cfg config.Config,
 	pool *pgxpool.Pool,
+	catalogRepository *catalog.Repository,
 	cacheClient cache.Client,
-) *api.Handler {
-	service := api.NewService(
-		catalog.NewRepository(pool, catalog.RepositoryOptions{
-			SummaryRelation: cfg.CatalogSummaryRelation,
-		}),
+	sellerStats *sellerstats.Store,
+) *api.Service {
+	return api.NewService(
+		catalogRepository,
 		snapshots.NewRepository(pool),
 		cacheClient,
 		api.ServiceOptions{
@@ -82,9 +229,14 @@ func buildHandler(
 				Product:    cfg.CacheTTLProduct,
 				Discounts:  cfg.CacheTTLDiscounts,
 				PriceRange: cfg.CacheTTLPriceRange,
+				Sellers:    cfg.CacheTTLSellers,
 			},
+			SellerStats: sellerStats,
 		},
 	)
+}
+
+func buildHandler(cfg config.Config, service *api.Service) *api.Handler {
 	return api.NewHandler(service, cfg.MaxPageSize, api.BuildInfo{
 		Version: version,
 		Commit:  commit,
@@ -92,17 +244,56 @@ func buildHandler(
 	})
 }
 
-func buildServer(cfg config.Config, handler *api.Handler) *http.Server {
+func buildServer(
+	cfg config.Config,
+	handler *api.Handler,
+	feedHandler *api.FeedHandler,
+	adminHandler *api.AdminHandler,
+	watchHandler *api.WatchHandler,
+	collectionHandler *api.CollectionHandler,
+	importHandler *api.ImportHandler,
+) *http.Server {
 	return &http.Server{
 		Addr: cfg.ServerAddress,
 		Handler: api.NewRouter(handler, api.RouterOptions{
 			AllowedOrigin:     cfg.FrontendOrigin,
 			TrustedProxyCIDRs: cfg.TrustedProxyCIDRs,
+			Feeds:             feedHandler,
+			Admin:             adminHandler,
+			SeparateAdminV1:   cfg.AdminAddress != "",
+			Watches:           watchHandler,
+			Collections:       collectionHandler,
+			Imports:           importHandler,
 			Timeouts: api.RouteTimeouts{
 				Health: cfg.HealthTimeout, Ready: cfg.ReadyTimeout,
 				Catalog: cfg.CatalogTimeout, Search: cfg.SearchTimeout,
 				Product: cfg.ProductTimeout, Discounts: cfg.DiscountsTimeout,
 				Metadata: cfg.MetadataTimeout, PriceRange: cfg.PriceRangeTimeout,
+				Admin: cfg.AdminTimeout, Watches: cfg.WatchesTimeout,
+				Collections: cfg.CollectionsTimeout, Imports: cfg.ImportsTimeout,
+				AliasReview: cfg.AliasReviewTimeout,
+			},
+		}),
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// buildAdminServer serves /admin/v1 on API_ADMIN_ADDRESS, when set, so the
+// admin surface can listen on a private interface only.
+func buildAdminServer(cfg config.Config, adminHandler *api.AdminHandler) *http.Server {
+	if cfg.AdminAddress == "" || adminHandler == nil || len(cfg.AdminTokens) == 0 {
+		return nil
+	}
+	return &http.Server{
+		Addr: cfg.AdminAddress,
+		Handler: api.NewAdminRouter(adminHandler, api.RouterOptions{
+			TrustedProxyCIDRs: cfg.TrustedProxyCIDRs,
+			Timeouts: api.RouteTimeouts{
+				Admin: cfg.AdminTimeout, AliasReview: cfg.AliasReviewTimeout,
 			},
 		}),
 		ReadTimeout:       cfg.ReadTimeout,
@@ -126,23 +317,32 @@ func connectCache(cfg config.Config) cache.Client {
 	return client
 }
 
-func serve(server *http.Server) error {
-	errCh := make(chan error, 1)
-	go func() {
-		log.Printf("api listening on %s", server.Addr)
-		errCh <- server.ListenAndServe()
-	}()
+// serve runs every server until a signal arrives or one of them fails; the
+// others are then shut down too.
+func serve(servers ...*http.Server) error {
+	errCh := make(chan error, len(servers))
+	for _, server := range servers {
+		go func() {
+			log.Printf("api listening on %s", server.Addr)
+			errCh <- server.ListenAndServe()
+		}()
+	}
 	stopCh := make(chan os.Signal, 1)
 	signal.Notify(stopCh, syscall.SIGINT, syscall.SIGTERM)
+	var result error
 	select {
 	case err := <-errCh:
-		if err == nil || err == http.ErrServerClosed {
-			return nil
+		if err != nil && err != http.ErrServerClosed {
+			result = err
 		}
-		return err
 	case <-stopCh:
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		return server.Shutdown(ctx)
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
 }
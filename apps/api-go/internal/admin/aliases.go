@@ -0,0 +1,338 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"tlamasite/apps/api-go/internal/refresh"
+)
+
+const (
+	DefaultCandidateLimit = 50
+	MaxCandidateLimit     = 200
+)
+
+// AliasMatchRules lists the rules refresh_canonical_product_alias_candidates
+// proposes candidates under.
+var AliasMatchRules = []string{"same_seller_product_code", "shared_ean_review"}
+
+var (
+	ErrCandidateNotFound = errors.New("alias candidate not found")
+	ErrCandidateReviewed = errors.New("alias candidate has already been reviewed")
+	ErrAliasConflict     = errors.New("seller and product code are already mapped by another alias")
+	ErrRefreshRunning    = errors.New("a catalog refresh is running; retry the approval when it finishes")
+)
+
+// aliasApprovalLockTimeout bounds the wait for the catalog refresh lock. A
+// refresh run can hold it for minutes, far longer than a review request should
+// wait.
+const aliasApprovalLockTimeout = 5 * time.Second
+
+type AliasCandidateFilters struct {
+	MatchRule *string
+	Limit     int
+}
+
+// AliasCandidate is one pending canonical_product_alias_candidates row with
+// the current seller rows of the proposed product and of the candidate slug,
+// so both can be compared before approval.
+type AliasCandidate struct {
+	ID               int64             `json:"id"`
+	ProposedSlug     string            `json:"proposed_slug"`
+	Slug             *string           `json:"slug"`
+	Seller           *string           `json:"seller"`
+	ProductCode      *string           `json:"product_code"`
+	ProductName      *string           `json:"product_name"`
+	MatchRule        string            `json:"match_rule"`
+	Confidence       float64           `json:"confidence"`
+	Evidence         json.RawMessage   `json:"evidence"`
+	CreatedAt        time.Time         `json:"created_at"`
+	ProposedSellers  []CandidateSeller `json:"proposed_sellers"`
+	CandidateSellers []CandidateSeller `json:"candidate_sellers"`
+}
+
+type CandidateSeller struct {
+	Seller            string     `json:"seller"`
+	ProductCode       *string    `json:"product_code"`
+	ProductName       *string    `json:"product_name"`
+	LatestPrice       *float64   `json:"latest_price"`
+	CurrencyCode      *string    `json:"currency_code"`
+	AvailabilityLabel *string    `json:"availability_label"`
+	StockStatusLabel  *string    `json:"stock_status_label"`
+	SourceURL         *string    `json:"source_url"`
+	LatestScrapedAt   *time.Time `json:"latest_scraped_at"`
+}
+
+// AliasReview is the outcome of a review. History and Refresh carry the
+// summaries returned by the rebuild and refresh routines after an approval.
+type AliasReview struct {
+	ID           int64           `json:"id"`
+	Status       string          `json:"status"`
+	ProposedSlug string          `json:"proposed_slug"`
+	Notes        *string         `json:"notes"`
+	History      json.RawMessage `json:"history,omitempty"`
+	Refresh      json.RawMessage `json:"refresh,omitempty"`
+}
+
+const candidateSellersSelect = `
+  coalesce((
+    select jsonb_agg(
+      jsonb_build_object(
+        'seller', state.seller,
+        'product_code', state.product_code,
+        'product_name', state.product_name,
+        'latest_price', state.latest_price,
+        'currency_code', state.currency_code,
+        'availability_label', state.availability_label,
+        'stock_status_label', state.stock_status_label,
+        'source_url', state.source_url,
+        'latest_scraped_at', state.latest_scraped_at
+      )
+      order by public.seller_priority(state.seller), state.seller
+    )
+    from public.catalog_slug_seller_state state
+    where state.product_name_normalized = `
+
+// aliasCandidatesQuery lists pending candidates, strongest first. A candidate
+// slug that an approved alias already folds away has no seller rows left.
+const aliasCandidatesQuery = `
+select
+  candidate.id,
+  candidate.proposed_canonical_product_id,
+  candidate.product_name_normalized,
+  candidate.seller,
+  candidate.product_code,
+  candidate.product_name,
+  candidate.match_rule,
+  candidate.confidence::double precision,
+  candidate.evidence,
+  candidate.created_at,` + candidateSellersSelect + `candidate.proposed_canonical_product_id
+  ), '[]'::jsonb),` + candidateSellersSelect + `candidate.product_name_normalized
+  ), '[]'::jsonb)
+from public.canonical_product_alias_candidates candidate
+where candidate.status = 'pending'
+  and ($1::text is null or candidate.match_rule = $1::text)
+order by candidate.confidence desc, candidate.id asc
+limit $2;`
+
+const lockCandidateQuery = `
+select
+  proposed_canonical_product_id,
+  seller,
+  product_code,
+  product_name_normalized,
+  confidence,
+  status
+from public.canonical_product_alias_candidates
+where id = $1
+for update;`
+
+const insertCanonicalProductQuery = `
+insert into public.canonical_products (canonical_product_id, display_name, source)
+select $1, state.product_name, 'candidate_review'
+from (select 1) seed
+left join public.catalog_slug_state state on state.product_name_normalized = $1
+on conflict (canonical_product_id) do nothing;`
+
+const insertAliasQuery = `
+insert into public.canonical_product_aliases (
+  canonical_product_id, seller, product_code, product_name_normalized,
+  source, confidence, notes
+)
+values ($1, $2, $3, $4, 'candidate_review', $5, $6);`
+
+const approveCandidateQuery = `
+update public.canonical_product_alias_candidates
+set
+  status = 'approved',
+  notes = coalesce($2, notes),
+  updated_at = timezone('utc', now())
+where id = $1
+returning notes;`
+
+// rejectCandidateQuery only matches pending rows so a concurrent approval is
+// never overwritten.
+const rejectCandidateQuery = `
+update public.canonical_product_alias_candidates
+set
+  status = 'rejected',
+  notes = $2,
+  updated_at = timezone('utc', now())
+where id = $1
+  and status = 'pending'
+returning proposed_canonical_product_id, notes;`
+
+const candidateExistsQuery = `
+select exists (select 1 from public.canonical_product_alias_candidates where id = $1);`
+
+const rebuildAliasHistoryQuery = `
+select public.rebuild_catalog_daily_price_history_for_canonical_product($1)::text;`
+
+// refreshCatalogStateQuery rebuilds only the proposed and candidate slugs,
+// including raw-slug rows the alias folds away that no recent snapshot
+// touched.
+const refreshCatalogStateQuery = `select public.refresh_catalog_state_scoped(null, $1::text[])::text;`
+
+func (repository *Repository) AliasCandidates(
+	ctx context.Context,
+	filters AliasCandidateFilters,
+) ([]AliasCandidate, error) {
+	rows, err := repository.db.Query(ctx, aliasCandidatesQuery, filters.MatchRule, filters.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := make([]AliasCandidate, 0, filters.Limit)
+	for rows.Next() {
+		var candidate AliasCandidate
+		var evidence, proposedSellers, candidateSellers []byte
+		if err := rows.Scan(
+			&candidate.ID,
+			&candidate.ProposedSlug,
+			&candidate.Slug,
+			&candidate.Seller,
+			&candidate.ProductCode,
+			&candidate.ProductName,
+			&candidate.MatchRule,
+			&candidate.Confidence,
+			&evidence,
+			&candidate.CreatedAt,
+			&proposedSellers,
+			&candidateSellers,
+		); err != nil {
+			return nil, err
+		}
+		candidate.Evidence = json.RawMessage(evidence)
+		if err := json.Unmarshal(proposedSellers, &candidate.ProposedSellers); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(candidateSellers, &candidate.CandidateSellers); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, rows.Err()
+}
+
+// ApproveAliasCandidate stores the alias, marks the candidate approved,
+// rebuilds the product's daily history and refreshes catalog state in one
+// transaction, so a failure at any step leaves catalog identity unchanged.
+func (repository *Repository) ApproveAliasCandidate(
+	ctx context.Context,
+	id int64,
+	notes *string,
+) (AliasReview, error) {
+	tx, err := repository.db.Begin(ctx)
+	if err != nil {
+		return AliasReview{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Approval refreshes catalog state, so it must not overlap a refresh run.
+	if err := refresh.LockWithin(ctx, tx, aliasApprovalLockTimeout); err != nil {
+		if errors.Is(err, refresh.ErrLockTimeout) {
+			return AliasReview{}, ErrRefreshRunning
+		}
+		return AliasReview{}, err
+	}
+	var candidate struct {
+		ProposedSlug string
+		Seller       *string
+		ProductCode  *string
+		Slug         *string
+		Confidence   float64
+		Status       string
+	}
+	if err := tx.QueryRow(ctx, lockCandidateQuery, id).Scan(
+		&candidate.ProposedSlug,
+		&candidate.Seller,
+		&candidate.ProductCode,
+		&candidate.Slug,
+		&candidate.Confidence,
+		&candidate.Status,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return AliasReview{}, ErrCandidateNotFound
+		}
+		return AliasReview{}, err
+	}
+	if candidate.Status != "pending" {
+		return AliasReview{}, ErrCandidateReviewed
+	}
+	if _, err := tx.Exec(ctx, insertCanonicalProductQuery, candidate.ProposedSlug); err != nil {
+		return AliasReview{}, err
+	}
+	if _, err := tx.Exec(
+		ctx,
+		insertAliasQuery,
+		candidate.ProposedSlug,
+		candidate.Seller,
+		candidate.ProductCode,
+		candidate.Slug,
+		candidate.Confidence,
+		notes,
+	); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return AliasReview{}, ErrAliasConflict
+		}
+		return AliasReview{}, err
+	}
+
+	review := AliasReview{ID: id, Status: "approved", ProposedSlug: candidate.ProposedSlug}
+	if err := tx.QueryRow(ctx, approveCandidateQuery, id, notes).Scan(&review.Notes); err != nil {
+		return AliasReview{}, err
+	}
+	var history, refresh string
+	if err := tx.QueryRow(ctx, rebuildAliasHistoryQuery, candidate.ProposedSlug).Scan(&history); err != nil {
+		return AliasReview{}, err
+	}
+	if err := tx.QueryRow(ctx, refreshCatalogStateQuery, aliasRefreshSlugs(candidate.ProposedSlug, candidate.Slug)).Scan(&refresh); err != nil {
+		return AliasReview{}, err
+	}
+	review.History = json.RawMessage(history)
+	review.Refresh = json.RawMessage(refresh)
+	return review, tx.Commit(ctx)
+}
+
+// aliasRefreshSlugs lists the slugs an approval changes: the proposed product
+// and the candidate slug folded into it, when the candidate names one.
+func aliasRefreshSlugs(proposedSlug string, slug *string) []string {
+	slugs := []string{proposedSlug}
+	if slug != nil && *slug != "" && *slug != proposedSlug {
+		slugs = append(slugs, *slug)
+	}
+	return slugs
+}
+
+// RejectAliasCandidate only changes the candidate row; catalog identity is
+// untouched, so no refresh is needed.
+func (repository *Repository) RejectAliasCandidate(
+	ctx context.Context,
+	id int64,
+	notes *string,
+) (AliasReview, error) {
+	review := AliasReview{ID: id, Status: "rejected"}
+	err := repository.db.QueryRow(ctx, rejectCandidateQuery, id, notes).
+		Scan(&review.ProposedSlug, &review.Notes)
+	if err == nil {
+		return review, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return AliasReview{}, err
+	}
+	var exists bool
+	if err := repository.db.QueryRow(ctx, candidateExistsQuery, id).Scan(&exists); err != nil {
+		return AliasReview{}, err
+	}
+	if exists {
+		return AliasReview{}, ErrCandidateReviewed
+	}
+	return AliasReview{}, ErrCandidateNotFound
+}
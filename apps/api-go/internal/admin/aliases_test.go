@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAliasCandidatesQueryListsBothSellerSides(t *testing.T) {
+	for _, fragment := range []string{
+		"where state.product_name_normalized = candidate.proposed_canonical_product_id",
+		"where state.product_name_normalized = candidate.product_name_normalized",
+		"order by public.seller_priority(state.seller), state.seller",
+		"candidate.status = 'pending'",
+		"order by candidate.confidence desc, candidate.id asc",
+	} {
+		if !strings.Contains(aliasCandidatesQuery, fragment) {
+			t.Fatalf("alias candidates query missing %q", fragment)
+		}
+	}
+}
+
+func TestAliasReviewQueriesGuardPendingCandidates(t *testing.T) {
+	if !strings.Contains(lockCandidateQuery, "for update") {
+		t.Fatal("approval must lock the candidate row")
+	}
+	if !strings.Contains(rejectCandidateQuery, "and status = 'pending'") {
+		t.Fatal("rejection must not overwrite a reviewed candidate")
+	}
+	if !strings.Contains(insertCanonicalProductQuery, "on conflict (canonical_product_id) do nothing") {
+		t.Fatal("approval must keep an existing canonical product")
+	}
+	if !strings.Contains(refreshCatalogStateQuery, "refresh_catalog_state_scoped(null, $1::text[])") {
+		t.Fatal("approval must refresh only the slugs it changes")
+	}
+}
+
+func TestAliasRefreshSlugsCoverProposedAndCandidateSlugs(t *testing.T) {
+	candidate := "obrozeni"
+	if slugs := aliasRefreshSlugs("obrozeni-rebirth", &candidate); !reflect.DeepEqual(slugs, []string{"obrozeni-rebirth", "obrozeni"}) {
+		t.Fatalf("unexpected refresh slugs %#v", slugs)
+	}
+	if slugs := aliasRefreshSlugs("obrozeni-rebirth", nil); !reflect.DeepEqual(slugs, []string{"obrozeni-rebirth"}) {
+		t.Fatalf("a candidate without a slug refreshes only the proposed product: %#v", slugs)
+	}
+}
@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 500
+)
+
+// AuditEntry records one mutating /admin/v1 call, including calls refused for
+// a missing scope.
+type AuditEntry struct {
+	ID         int64     `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Actor      string    `json:"actor"`
+	Method     string    `json:"method"`
+	Route      string    `json:"route"`
+	Path       string    `json:"path"`
+	Status     int       `json:"status"`
+	RequestID  string    `json:"request_id"`
+	ClientIP   string    `json:"client_ip"`
+}
+
+type AuditFilters struct {
+	Actor *string
+	Limit int
+}
+
+const recordAuditQuery = `
+insert into public.admin_audit_log (actor, method, route, path, status, request_id, client_ip)
+values ($1, $2, $3, $4, $5, $6, $7);`
+
+const auditLogQuery = `
+select id, occurred_at, actor, method, route, path, status, request_id, client_ip
+from public.admin_audit_log
+where ($1::text is null or actor = $1)
+order by occurred_at desc, id desc
+limit $2;`
+
+func (repository *Repository) RecordAudit(ctx context.Context, entry AuditEntry) error {
+	_, err := repository.db.Exec(
+		ctx,
+		recordAuditQuery,
+		entry.Actor,
+		entry.Method,
+		entry.Route,
+		entry.Path,
+		entry.Status,
+		entry.RequestID,
+		entry.ClientIP,
+	)
+	return err
+}
+
+// AuditLog returns the newest entries first.
+func (repository *Repository) AuditLog(ctx context.Context, filters AuditFilters) ([]AuditEntry, error) {
+	rows, err := repository.db.Query(ctx, auditLogQuery, filters.Actor, filters.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]AuditEntry, 0, filters.Limit)
+	for rows.Next() {
+		var entry AuditEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.OccurredAt,
+			&entry.Actor,
+			&entry.Method,
+			&entry.Route,
+			&entry.Path,
+			&entry.Status,
+			&entry.RequestID,
+			&entry.ClientIP,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
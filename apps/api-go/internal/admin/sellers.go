@@ -0,0 +1,144 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	DefaultSellerPriority   = 100
+	DefaultStaleAfter       = 72 * time.Hour
+	DefaultShippingCurrency = "CZK"
+)
+
+var ErrSellerNotFound = errors.New("seller is not registered")
+
+// SellerRegistration is one catalog_sellers row. Shipping fields stay null
+// until they are confirmed with the shop; the public API estimates a total
+// price only from values that are set.
+type SellerRegistration struct {
+	Seller                string    `json:"seller"`
+	DisplayName           *string   `json:"display_name"`
+	HomepageURL           *string   `json:"homepage_url"`
+	LogoPath              *string   `json:"logo_path"`
+	Priority              int       `json:"priority"`
+	StaleAfterSeconds     int64     `json:"stale_after_seconds"`
+	ShippingCurrencyCode  string    `json:"shipping_currency_code"`
+	FreeShippingThreshold *float64  `json:"free_shipping_threshold"`
+	FlatShippingCost      *float64  `json:"flat_shipping_cost"`
+	PickupOptions         []string  `json:"pickup_options"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+const sellerRegistrationColumns = `
+  seller,
+  display_name,
+  homepage_url,
+  logo_path,
+  priority,
+  extract(epoch from stale_after)::bigint,
+  shipping_currency_code,
+  free_shipping_threshold::double precision,
+  flat_shipping_cost::double precision,
+  pickup_options,
+  updated_at`
+
+const sellerRegistryQuery = `
+select` + sellerRegistrationColumns + `
+from public.catalog_sellers
+order by priority asc, seller asc;`
+
+// saveSellerQuery replaces every managed column so a PUT body is the whole
+// registration; created_at survives updates.
+const saveSellerQuery = `
+insert into public.catalog_sellers (
+  seller, display_name, homepage_url, logo_path, priority, stale_after,
+  shipping_currency_code, free_shipping_threshold, flat_shipping_cost, pickup_options
+)
+values ($1, $2, $3, $4, $5, make_interval(secs => $6), $7, $8, $9, $10)
+on conflict (seller) do update set
+  display_name = excluded.display_name,
+  homepage_url = excluded.homepage_url,
+  logo_path = excluded.logo_path,
+  priority = excluded.priority,
+  stale_after = excluded.stale_after,
+  shipping_currency_code = excluded.shipping_currency_code,
+  free_shipping_threshold = excluded.free_shipping_threshold,
+  flat_shipping_cost = excluded.flat_shipping_cost,
+  pickup_options = excluded.pickup_options,
+  updated_at = now()
+returning` + sellerRegistrationColumns + `;`
+
+const deleteSellerQuery = `delete from public.catalog_sellers where seller = $1;`
+
+func (repository *Repository) Sellers(ctx context.Context) ([]SellerRegistration, error) {
+	rows, err := repository.db.Query(ctx, sellerRegistryQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sellers := make([]SellerRegistration, 0, 16)
+	for rows.Next() {
+		seller, err := scanSellerRegistration(rows)
+		if err != nil {
+			return nil, err
+		}
+		sellers = append(sellers, seller)
+	}
+	return sellers, rows.Err()
+}
+
+func (repository *Repository) SaveSeller(
+	ctx context.Context,
+	registration SellerRegistration,
+) (SellerRegistration, error) {
+	return scanSellerRegistration(repository.db.QueryRow(
+		ctx,
+		saveSellerQuery,
+		registration.Seller,
+		registration.DisplayName,
+		registration.HomepageURL,
+		registration.LogoPath,
+		registration.Priority,
+		registration.StaleAfterSeconds,
+		registration.ShippingCurrencyCode,
+		registration.FreeShippingThreshold,
+		registration.FlatShippingCost,
+		registration.PickupOptions,
+	))
+}
+
+// DeleteSeller unregisters a shop. Its offers stay in the catalog and fall
+// back to the registry defaults.
+func (repository *Repository) DeleteSeller(ctx context.Context, seller string) error {
+	tag, err := repository.db.Exec(ctx, deleteSellerQuery, seller)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSellerNotFound
+	}
+	return nil
+}
+
+func scanSellerRegistration(row pgx.Row) (SellerRegistration, error) {
+	var seller SellerRegistration
+	err := row.Scan(
+		&seller.Seller,
+		&seller.DisplayName,
+		&seller.HomepageURL,
+		&seller.LogoPath,
+		&seller.Priority,
+		&seller.StaleAfterSeconds,
+		&seller.ShippingCurrencyCode,
+		&seller.FreeShippingThreshold,
+		&seller.FlatShippingCost,
+		&seller.PickupOptions,
+		&seller.UpdatedAt,
+	)
+	return seller, err
+}
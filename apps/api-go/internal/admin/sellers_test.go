@@ -0,0 +1,24 @@
+package admin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSaveSellerQueryReplacesManagedColumns(t *testing.T) {
+	for _, fragment := range []string{
+		"on conflict (seller) do update set",
+		"make_interval(secs => $6)",
+		"free_shipping_threshold = excluded.free_shipping_threshold",
+		"pickup_options = excluded.pickup_options",
+		"updated_at = now()",
+		"returning",
+	} {
+		if !strings.Contains(saveSellerQuery, fragment) {
+			t.Fatalf("save seller query missing %q", fragment)
+		}
+	}
+	if strings.Contains(saveSellerQuery, "created_at =") {
+		t.Fatal("save seller query must keep created_at")
+	}
+}
@@ -0,0 +1,123 @@
+package admin
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SnapshotCursor is the keyset position of the last returned row.
+type SnapshotCursor struct {
+	ScrapedAt time.Time
+	ID        int64
+}
+
+var ErrInvalidCursor = errors.New("cursor is invalid")
+
+func (cursor SnapshotCursor) Encode() string {
+	raw := strconv.FormatInt(cursor.ScrapedAt.UnixMicro(), 10) + ":" + strconv.FormatInt(cursor.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeSnapshotCursor(encoded string) (SnapshotCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return SnapshotCursor{}, ErrInvalidCursor
+	}
+	micros, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return SnapshotCursor{}, ErrInvalidCursor
+	}
+	scrapedAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return SnapshotCursor{}, ErrInvalidCursor
+	}
+	parsedID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return SnapshotCursor{}, ErrInvalidCursor
+	}
+	return SnapshotCursor{ScrapedAt: time.UnixMicro(scrapedAt).UTC(), ID: parsedID}, nil
+}
+
+// Source slugs and seller/product-code aliases narrow the scan to indexed
+// candidates; canonical_product_slug then confirms each row's identity.
+const sellerSnapshotsSelect = `
+with requested_product as (
+  select public.canonical_product_slug(null, null, $1) as canonical_product_id
+),
+source_slugs as (
+  select requested.canonical_product_id as product_name_normalized
+  from requested_product requested
+  union
+  select lower(trim(alias.product_name_normalized))
+  from public.canonical_product_aliases alias
+  join requested_product requested
+    on requested.canonical_product_id = alias.canonical_product_id
+  where nullif(trim(coalesce(alias.product_name_normalized, '')), '') is not null
+),
+source_codes as (
+  select lower(trim(alias.product_code)) as product_code
+  from public.canonical_product_aliases alias
+  join requested_product requested
+    on requested.canonical_product_id = alias.canonical_product_id
+  where lower(trim(alias.seller)) = $2
+    and nullif(trim(coalesce(alias.product_code, '')), '') is not null
+)
+select
+  requested.canonical_product_id,
+  snapshot.id,
+  snapshot.product_name_normalized,
+  snapshot.product_code,
+  snapshot.product_name_original,
+  snapshot.currency_code::text,
+  snapshot.price_with_vat::double precision,
+  snapshot.list_price_with_vat::double precision,
+  snapshot.availability_label,
+  snapshot.stock_status_label,
+  snapshot.source_url,
+  snapshot.scraped_at
+from public.product_price_snapshots snapshot
+cross join requested_product requested
+where lower(coalesce(nullif(trim(snapshot.seller), ''), 'unknown')) = $2
+  and (
+    snapshot.product_name_normalized in (select product_name_normalized from source_slugs)
+    or lower(trim(snapshot.product_code)) in (select product_code from source_codes)
+  )
+  and public.canonical_product_slug(
+    snapshot.seller,
+    snapshot.product_code,
+    snapshot.product_name_normalized
+  ) = requested.canonical_product_id`
+
+func buildSellerSnapshotsQuery(filters SnapshotFilters) (string, []any) {
+	args := []any{filters.Slug, filters.Seller}
+	var builder strings.Builder
+	builder.WriteString(sellerSnapshotsSelect)
+	if filters.From != nil {
+		args = append(args, *filters.From)
+		fmt.Fprintf(&builder, "\n  and snapshot.scraped_at >= $%d", len(args))
+	}
+	if filters.To != nil {
+		args = append(args, *filters.To)
+		fmt.Fprintf(&builder, "\n  and snapshot.scraped_at < $%d", len(args))
+	}
+	if filters.Cursor != nil {
+		args = append(args, filters.Cursor.ScrapedAt, filters.Cursor.ID)
+		fmt.Fprintf(
+			&builder,
+			"\n  and (snapshot.scraped_at, snapshot.id) < ($%d, $%d)",
+			len(args)-1,
+			len(args),
+		)
+	}
+	args = append(args, filters.Limit+1)
+	fmt.Fprintf(
+		&builder,
+		"\norder by snapshot.scraped_at desc, snapshot.id desc\nlimit $%d;",
+		len(args),
+	)
+	return builder.String(), args
+}
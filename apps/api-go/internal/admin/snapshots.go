@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	DefaultSnapshotLimit = 100
+	MaxSnapshotLimit     = 500
+)
+
+// Repository reads raw scraper data through a pool that runs as the
+// maintenance role; the public API role cannot select raw snapshots.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+type SnapshotFilters struct {
+	Slug   string
+	Seller string
+	From   *time.Time
+	To     *time.Time
+	Cursor *SnapshotCursor
+	Limit  int
+}
+
+type Snapshot struct {
+	ID                    int64     `json:"id"`
+	ProductNameNormalized *string   `json:"product_name_normalized"`
+	ProductCode           *string   `json:"product_code"`
+	ProductName           *string   `json:"product_name"`
+	CurrencyCode          *string   `json:"currency_code"`
+	PriceWithVat          *float64  `json:"price_with_vat"`
+	ListPriceWithVat      *float64  `json:"list_price_with_vat"`
+	AvailabilityLabel     *string   `json:"availability_label"`
+	StockStatusLabel      *string   `json:"stock_status_label"`
+	SourceURL             *string   `json:"source_url"`
+	ScrapedAt             time.Time `json:"scraped_at"`
+}
+
+type SnapshotPage struct {
+	CanonicalProductID *string    `json:"canonical_product_id"`
+	Seller             string     `json:"seller"`
+	Rows               []Snapshot `json:"rows"`
+	NextCursor         *string    `json:"next_cursor"`
+}
+
+// SellerSnapshots pages newest-first through one seller's raw snapshots of a
+// canonical product, including rows scraped under approved alias slugs.
+func (repository *Repository) SellerSnapshots(
+	ctx context.Context,
+	filters SnapshotFilters,
+) (SnapshotPage, error) {
+	query, args := buildSellerSnapshotsQuery(filters)
+	rows, err := repository.db.Query(ctx, query, args...)
+	if err != nil {
+		return SnapshotPage{}, err
+	}
+	defer rows.Close()
+
+	page := SnapshotPage{Seller: filters.Seller, Rows: make([]Snapshot, 0, filters.Limit+1)}
+	for rows.Next() {
+		var snapshot Snapshot
+		if err := rows.Scan(
+			&page.CanonicalProductID,
+			&snapshot.ID,
+			&snapshot.ProductNameNormalized,
+			&snapshot.ProductCode,
+			&snapshot.ProductName,
+			&snapshot.CurrencyCode,
+			&snapshot.PriceWithVat,
+			&snapshot.ListPriceWithVat,
+			&snapshot.AvailabilityLabel,
+			&snapshot.StockStatusLabel,
+			&snapshot.SourceURL,
+			&snapshot.ScrapedAt,
+		); err != nil {
+			return SnapshotPage{}, err
+		}
+		page.Rows = append(page.Rows, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return SnapshotPage{}, err
+	}
+	return paginateSnapshots(page, filters.Limit), nil
+}
+
+func paginateSnapshots(page SnapshotPage, limit int) SnapshotPage {
+	if len(page.Rows) <= limit {
+		return page
+	}
+	page.Rows = page.Rows[:limit]
+	last := page.Rows[limit-1]
+	cursor := SnapshotCursor{ScrapedAt: last.ScrapedAt, ID: last.ID}.Encode()
+	page.NextCursor = &cursor
+	return page
+}
@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotCursorRoundTrip(t *testing.T) {
+	cursor := SnapshotCursor{
+		ScrapedAt: time.Date(2026, 3, 2, 8, 15, 30, 123456000, time.UTC),
+		ID:        9001,
+	}
+	decoded, err := DecodeSnapshotCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("decode cursor: %v", err)
+	}
+	if decoded != cursor {
+		t.Fatalf("unexpected cursor: %#v", decoded)
+	}
+	for _, invalid := range []string{"%%%", "bm8tc2VwYXJhdG9y", "YTpi"} {
+		if _, err := DecodeSnapshotCursor(invalid); err != ErrInvalidCursor {
+			t.Fatalf("%q: expected ErrInvalidCursor, got %v", invalid, err)
+		}
+	}
+}
+
+func TestBuildSellerSnapshotsQueryAddsRangeAndKeyset(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	query, args := buildSellerSnapshotsQuery(SnapshotFilters{
+		Slug:   "alpha",
+		Seller: "tlamagames",
+		From:   &from,
+		To:     &to,
+		Cursor: &SnapshotCursor{ScrapedAt: to, ID: 7},
+		Limit:  50,
+	})
+	for _, fragment := range []string{
+		"public.canonical_product_slug(null, null, $1)",
+		"'unknown')) = $2",
+		"snapshot.scraped_at >= $3",
+		"snapshot.scraped_at < $4",
+		"(snapshot.scraped_at, snapshot.id) < ($5, $6)",
+		"order by snapshot.scraped_at desc, snapshot.id desc\nlimit $7;",
+	} {
+		if !strings.Contains(query, fragment) {
+			t.Fatalf("query missing %q:\n%s", fragment, query)
+		}
+	}
+	if len(args) != 7 || args[6] != 51 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestPaginateSnapshotsTrimsLookaheadRow(t *testing.T) {
+	scrapedAt := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	page := paginateSnapshots(SnapshotPage{Rows: []Snapshot{
+		{ID: 3, ScrapedAt: scrapedAt},
+		{ID: 2, ScrapedAt: scrapedAt},
+		{ID: 1, ScrapedAt: scrapedAt.Add(-time.Hour)},
+	}}, 2)
+	if len(page.Rows) != 2 || page.NextCursor == nil {
+		t.Fatalf("unexpected page: %#v", page)
+	}
+	cursor, err := DecodeSnapshotCursor(*page.NextCursor)
+	if err != nil || cursor.ID != 2 || !cursor.ScrapedAt.Equal(scrapedAt) {
+		t.Fatalf("unexpected cursor: %#v (%v)", cursor, err)
+	}
+	if last := paginateSnapshots(SnapshotPage{Rows: page.Rows}, 2); last.NextCursor != nil {
+		t.Fatal("expected final page to omit next cursor")
+	}
+}
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"crypto/sha256"
+	"slices"
+)
+
+// Scopes granted to /admin/v1 tokens. Read scopes cover GET routes and write
+// scopes cover mutations of the same resource; "*" grants every scope.
+const (
+	ScopeAll           = "*"
+	ScopeSnapshotsRead = "snapshots:read"
+	ScopeSellersRead   = "sellers:read"
+	ScopeSellersWrite  = "sellers:write"
+	ScopeWebhooksRead  = "webhooks:read"
+	ScopeWebhooksWrite = "webhooks:write"
+	ScopeAuditRead     = "audit:read"
+	ScopeAliasesRead   = "aliases:read"
+	ScopeAliasesWrite  = "aliases:write"
+)
+
+var Scopes = []string{
+	ScopeAll,
+	ScopeSnapshotsRead,
+	ScopeSellersRead,
+	ScopeSellersWrite,
+	ScopeWebhooksRead,
+	ScopeWebhooksWrite,
+	ScopeAuditRead,
+	ScopeAliasesRead,
+	ScopeAliasesWrite,
+}
+
+// Token is one configured /admin/v1 bearer token. Only the SHA-256 digest of
+// the secret is kept; Name identifies the caller in the audit log.
+type Token struct {
+	Name   string
+	Digest [sha256.Size]byte
+	Scopes []string
+}
+
+func (token Token) Allows(scope string) bool {
+	return slices.Contains(token.Scopes, ScopeAll) || slices.Contains(token.Scopes, scope)
+}
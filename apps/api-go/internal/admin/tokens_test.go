@@ -0,0 +1,13 @@
+package admin
+
+import "testing"
+
+func TestTokenAllowsGrantedOrWildcardScopes(t *testing.T) {
+	reader := Token{Name: "dashboard", Scopes: []string{ScopeSellersRead}}
+	if !reader.Allows(ScopeSellersRead) || reader.Allows(ScopeSellersWrite) {
+		t.Fatalf("unexpected scope check for %#v", reader)
+	}
+	if operator := (Token{Name: "ops", Scopes: []string{ScopeAll}}); !operator.Allows(ScopeWebhooksWrite) {
+		t.Fatal("wildcard token must allow every scope")
+	}
+}
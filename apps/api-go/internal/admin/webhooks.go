@@ -0,0 +1,276 @@
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	DefaultDeliveryLimit = 50
+	MaxDeliveryLimit     = 200
+)
+
+// WebhookEventTypes lists the events the read-model triggers emit; the
+// subscription table rejects anything else.
+var WebhookEventTypes = []string{"price_drop", "back_in_stock", "new_product"}
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookSubscription is one catalog_webhook_subscriptions row. Secret is only
+// filled in the response that creates the subscription.
+type WebhookSubscription struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"event_types"`
+	Description *string   `json:"description"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type WebhookDeliveryFilters struct {
+	SubscriptionID int64
+	Status         *string
+	Limit          int
+}
+
+type WebhookDeliveryAttempt struct {
+	Attempt     int       `json:"attempt"`
+	StatusCode  *int      `json:"status_code"`
+	Error       *string   `json:"error"`
+	DurationMS  int       `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64                    `json:"id"`
+	EventID        int64                    `json:"event_id"`
+	EventType      string                   `json:"event_type"`
+	Payload        json.RawMessage          `json:"payload"`
+	Status         string                   `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	NextAttemptAt  time.Time                `json:"next_attempt_at"`
+	LastStatusCode *int                     `json:"last_status_code"`
+	LastError      *string                  `json:"last_error"`
+	DeliveredAt    *time.Time               `json:"delivered_at"`
+	CreatedAt      time.Time                `json:"created_at"`
+	AttemptLog     []WebhookDeliveryAttempt `json:"attempt_log"`
+}
+
+const webhookSubscriptionColumns = `
+  id,
+  url,
+  event_types,
+  description,
+  created_at,
+  updated_at`
+
+const webhooksQuery = `
+select` + webhookSubscriptionColumns + `
+from public.catalog_webhook_subscriptions
+order by id asc;`
+
+const createWebhookQuery = `
+insert into public.catalog_webhook_subscriptions (url, event_types, secret, description)
+values ($1, $2, $3, $4)
+returning` + webhookSubscriptionColumns + `;`
+
+const deleteWebhookQuery = `delete from public.catalog_webhook_subscriptions where id = $1;`
+
+const webhookExistsQuery = `
+select exists (select 1 from public.catalog_webhook_subscriptions where id = $1);`
+
+// webhookDeliveriesQuery returns the newest deliveries of one subscription
+// with their attempt log, oldest attempt first.
+const webhookDeliveriesQuery = `
+select
+  delivery.id,
+  event.id,
+  event.event_type,
+  event.payload,
+  delivery.status,
+  delivery.attempts,
+  delivery.next_attempt_at,
+  delivery.last_status_code,
+  delivery.last_error,
+  delivery.delivered_at,
+  delivery.created_at,
+  coalesce((
+    select jsonb_agg(
+      jsonb_build_object(
+        'attempt', attempt.attempt,
+        'status_code', attempt.status_code,
+        'error', attempt.error,
+        'duration_ms', attempt.duration_ms,
+        'attempted_at', attempt.attempted_at
+      )
+      order by attempt.attempt, attempt.id
+    )
+    from public.catalog_webhook_delivery_attempts attempt
+    where attempt.delivery_id = delivery.id
+  ), '[]'::jsonb)
+from public.catalog_webhook_deliveries delivery
+join public.catalog_webhook_events event on event.id = delivery.event_id
+where delivery.subscription_id = $1
+  and ($2::text is null or delivery.status = $2::text)
+order by delivery.id desc
+limit $3;`
+
+// retryWebhookDeliveryQuery requeues a delivery with a fresh attempt budget.
+// Delivered rows may be requeued too, so partners can ask for a redelivery.
+const retryWebhookDeliveryQuery = `
+update public.catalog_webhook_deliveries
+set
+  status = 'pending',
+  attempts = 0,
+  next_attempt_at = now(),
+  delivered_at = null
+where id = $1
+returning id;`
+
+func (repository *Repository) Webhooks(ctx context.Context) ([]WebhookSubscription, error) {
+	rows, err := repository.db.Query(ctx, webhooksQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := make([]WebhookSubscription, 0, 8)
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+// CreateWebhook stores a subscription with a freshly generated signing secret
+// and returns it once; later reads never include the secret.
+func (repository *Repository) CreateWebhook(
+	ctx context.Context,
+	subscription WebhookSubscription,
+) (WebhookSubscription, error) {
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	created, err := scanWebhookSubscription(repository.db.QueryRow(
+		ctx,
+		createWebhookQuery,
+		subscription.URL,
+		subscription.EventTypes,
+		secret,
+		subscription.Description,
+	))
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	created.Secret = secret
+	return created, nil
+}
+
+// DeleteWebhook removes a subscription together with its queued and logged
+// deliveries.
+func (repository *Repository) DeleteWebhook(ctx context.Context, id int64) error {
+	tag, err := repository.db.Exec(ctx, deleteWebhookQuery, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (repository *Repository) WebhookDeliveries(
+	ctx context.Context,
+	filters WebhookDeliveryFilters,
+) ([]WebhookDelivery, error) {
+	var exists bool
+	if err := repository.db.QueryRow(ctx, webhookExistsQuery, filters.SubscriptionID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrWebhookNotFound
+	}
+	rows, err := repository.db.Query(
+		ctx,
+		webhookDeliveriesQuery,
+		filters.SubscriptionID,
+		filters.Status,
+		filters.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]WebhookDelivery, 0, filters.Limit)
+	for rows.Next() {
+		var delivery WebhookDelivery
+		var payload, attempts []byte
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.LastStatusCode,
+			&delivery.LastError,
+			&delivery.DeliveredAt,
+			&delivery.CreatedAt,
+			&attempts,
+		); err != nil {
+			return nil, err
+		}
+		delivery.Payload = json.RawMessage(payload)
+		if err := json.Unmarshal(attempts, &delivery.AttemptLog); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (repository *Repository) RetryWebhookDelivery(ctx context.Context, id int64) error {
+	var retried int64
+	err := repository.db.QueryRow(ctx, retryWebhookDeliveryQuery, id).Scan(&retried)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrWebhookDeliveryNotFound
+	}
+	return err
+}
+
+func scanWebhookSubscription(row pgx.Row) (WebhookSubscription, error) {
+	var subscription WebhookSubscription
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&subscription.EventTypes,
+		&subscription.Description,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	)
+	return subscription, err
+}
+
+func newWebhookSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWebhookQueriesNeverReturnSecrets(t *testing.T) {
+	_, returning, _ := strings.Cut(createWebhookQuery, "returning")
+	for _, selected := range []string{webhooksQuery, webhookDeliveriesQuery, returning} {
+		if strings.Contains(selected, "secret") {
+			t.Fatalf("query exposes the signing secret:\n%s", selected)
+		}
+	}
+}
+
+func TestRetryWebhookDeliveryResetsAttemptBudget(t *testing.T) {
+	for _, fragment := range []string{
+		"status = 'pending'",
+		"attempts = 0",
+		"next_attempt_at = now()",
+		"where id = $1",
+	} {
+		if !strings.Contains(retryWebhookDeliveryQuery, fragment) {
+			t.Fatalf("retry query missing %q", fragment)
+		}
+	}
+}
+
+func TestNewWebhookSecretIsRandom(t *testing.T) {
+	first, err := newWebhookSecret()
+	if err != nil {
+		t.Fatalf("secret: %v", err)
+	}
+	second, _ := newWebhookSecret()
+	if first == second || !strings.HasPrefix(first, "whsec_") || len(first) != len("whsec_")+64 {
+		t.Fatalf("unexpected secrets %q / %q", first, second)
+	}
+}
@@ -0,0 +1,187 @@
+package alerts
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// matchingOfferPredicate selects fresh offers, in the watch currency, at or
+// below the target. Stale offers never trigger mail, for the same reason they
+// never select the catalog price.
+const matchingOfferPredicate = `
+    offer.product_name_normalized = watch.product_name_normalized
+    and (watch.seller is null or offer.seller = watch.seller)
+    and offer.currency_code = watch.currency_code
+    and offer.latest_price <= watch.target_price
+    and offer.latest_scraped_at > now() - public.catalog_seller_stale_after(offer.seller)`
+
+const latestRefreshQuery = `select max(updated_at) from public.catalog_slug_seller_state;`
+
+const expirePendingWatchesQuery = `
+delete from public.catalog_price_watches
+where confirmed_at is null
+  and created_at < now() - make_interval(secs => $1);`
+
+// A watch whose price went back above target is re-armed, so the next drop
+// below target mails again.
+const rearmWatchesQuery = `
+update public.catalog_price_watches watch
+set last_notified_price = null, updated_at = now()
+where watch.last_notified_price is not null
+  and not exists (
+    select 1
+    from public.catalog_slug_seller_state offer
+    where` + matchingOfferPredicate + `
+  );`
+
+// dueWatchesQuery locks the batch it returns; concurrent API instances skip
+// locked rows, so each alert is mailed by one instance only.
+const dueWatchesQuery = `
+select
+  watch.id,
+  watch.email,
+  watch.product_name_normalized,
+  state.product_name,
+  watch.seller,
+  watch.target_price::double precision,
+  watch.currency_code,
+  best.seller,
+  best.seller_name,
+  best.latest_price::double precision,
+  best.source_url
+from public.catalog_price_watches watch
+join lateral (
+  select
+    offer.seller,
+    coalesce(registry.display_name, offer.seller) as seller_name,
+    offer.latest_price,
+    offer.source_url
+  from public.catalog_slug_seller_state offer
+  left join public.catalog_sellers registry on registry.seller = offer.seller
+  where` + matchingOfferPredicate + `
+  order by offer.latest_price, public.seller_priority(offer.seller), offer.seller
+  limit 1
+) best on true
+left join public.catalog_slug_state state
+  on state.product_name_normalized = watch.product_name_normalized
+where watch.confirmed_at is not null
+  and watch.id > $1
+  and (watch.last_notified_price is null or best.latest_price < watch.last_notified_price)
+order by watch.id
+limit $2
+for update of watch skip locked;`
+
+const markNotifiedQuery = `
+update public.catalog_price_watches
+set last_notified_at = now(), last_notified_price = $2, updated_at = now()
+where id = $1;`
+
+type dueWatch struct {
+	storedWatch
+	OfferSeller     string
+	OfferSellerName string
+	OfferPrice      float64
+	OfferURL        *string
+}
+
+type notifyFunc func(context.Context, dueWatch) error
+
+func (repository *Repository) latestRefresh(ctx context.Context) (time.Time, error) {
+	var latest *time.Time
+	if err := repository.db.QueryRow(ctx, latestRefreshQuery).Scan(&latest); err != nil {
+		return time.Time{}, err
+	}
+	if latest == nil {
+		return time.Time{}, nil
+	}
+	return *latest, nil
+}
+
+func (repository *Repository) expirePending(ctx context.Context, olderThan time.Duration) (int64, error) {
+	tag, err := repository.db.Exec(ctx, expirePendingWatchesQuery, olderThan.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (repository *Repository) rearm(ctx context.Context) (int64, error) {
+	tag, err := repository.db.Exec(ctx, rearmWatchesQuery)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// notifyBatch mails one locked batch after afterID and records the offers that
+// were sent. A failed send leaves its watch due for the next evaluation. Sent
+// mail is recorded even when ctx ends mid-batch, so shutdown does not resend.
+func (repository *Repository) notifyBatch(
+	ctx context.Context,
+	afterID int64,
+	limit int,
+	notify notifyFunc,
+) (batchResult, error) {
+	tx, err := repository.db.Begin(ctx)
+	if err != nil {
+		return batchResult{}, err
+	}
+	recordCtx := context.WithoutCancel(ctx)
+	defer func() { _ = tx.Rollback(recordCtx) }()
+
+	due, err := scanDueWatches(ctx, tx, afterID, limit)
+	if err != nil {
+		return batchResult{}, err
+	}
+	result := batchResult{Rows: len(due)}
+	for _, watch := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		result.LastID = watch.ID
+		if err := notify(ctx, watch); err != nil {
+			result.Failed++
+			continue
+		}
+		if _, err := tx.Exec(recordCtx, markNotifiedQuery, watch.ID, watch.OfferPrice); err != nil {
+			return batchResult{}, err
+		}
+		result.Notified++
+	}
+	if err := tx.Commit(recordCtx); err != nil {
+		return batchResult{}, err
+	}
+	return result, ctx.Err()
+}
+
+func scanDueWatches(ctx context.Context, tx pgx.Tx, afterID int64, limit int) ([]dueWatch, error) {
+	rows, err := tx.Query(ctx, dueWatchesQuery, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := make([]dueWatch, 0, limit)
+	for rows.Next() {
+		var watch dueWatch
+		if err := rows.Scan(
+			&watch.ID,
+			&watch.Email,
+			&watch.Slug,
+			&watch.ProductName,
+			&watch.Seller,
+			&watch.TargetPrice,
+			&watch.CurrencyCode,
+			&watch.OfferSeller,
+			&watch.OfferSellerName,
+			&watch.OfferPrice,
+			&watch.OfferURL,
+		); err != nil {
+			return nil, err
+		}
+		due = append(due, watch)
+	}
+	return due, rows.Err()
+}
@@ -0,0 +1,168 @@
+package alerts
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"tlamasite/apps/api-go/internal/mail"
+)
+
+const (
+	DefaultPollInterval = time.Minute
+	evaluationBatchSize = 100
+)
+
+type evaluationStore interface {
+	latestRefresh(ctx context.Context) (time.Time, error)
+	expirePending(ctx context.Context, olderThan time.Duration) (int64, error)
+	rearm(ctx context.Context) (int64, error)
+	notifyBatch(ctx context.Context, afterID int64, limit int, notify notifyFunc) (batchResult, error)
+}
+
+type batchResult struct {
+	Rows     int
+	Notified int
+	Failed   int
+	LastID   int64
+}
+
+type evaluation struct {
+	Expired  int64
+	Rearmed  int64
+	Notified int
+	Failed   int
+}
+
+// Job evaluates watches after each catalog refresh. The refresh runs outside
+// the API, so the job polls the newest catalog_slug_seller_state write and
+// evaluates once per change. Failed deliveries are retried after the next
+// refresh rather than on every poll, so a bouncing address cannot flood the
+// relay.
+type Job struct {
+	store       evaluationStore
+	tokens      Tokens
+	sender      mail.Sender
+	links       Links
+	interval    time.Duration
+	timeout     time.Duration
+	lastRefresh time.Time
+}
+
+func NewJob(
+	repository *Repository,
+	tokens Tokens,
+	sender mail.Sender,
+	links Links,
+	interval time.Duration,
+	timeout time.Duration,
+) *Job {
+	return newJob(repository, tokens, sender, links, interval, timeout)
+}
+
+func newJob(
+	store evaluationStore,
+	tokens Tokens,
+	sender mail.Sender,
+	links Links,
+	interval time.Duration,
+	timeout time.Duration,
+) *Job {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	if timeout <= 0 {
+		timeout = interval
+	}
+	return &Job{
+		store:    store,
+		tokens:   tokens,
+		sender:   sender,
+		links:    links,
+		interval: interval,
+		timeout:  timeout,
+	}
+}
+
+// Run polls immediately and then on every interval until ctx is done.
+func (job *Job) Run(ctx context.Context) {
+	for {
+		if err := job.poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("price watch evaluation failed", "error", err)
+		}
+		timer := time.NewTimer(job.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (job *Job) poll(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, job.timeout)
+	defer cancel()
+	latest, err := job.store.latestRefresh(ctx)
+	if err != nil {
+		return err
+	}
+	if !latest.After(job.lastRefresh) {
+		return nil
+	}
+	startedAt := time.Now()
+	result, err := job.evaluate(ctx)
+	if err != nil {
+		return err
+	}
+	job.lastRefresh = latest
+	slog.Info("price watches evaluated",
+		"notified", result.Notified,
+		"failed", result.Failed,
+		"rearmed", result.Rearmed,
+		"expired", result.Expired,
+		"duration", time.Since(startedAt).String(),
+	)
+	return nil
+}
+
+func (job *Job) evaluate(ctx context.Context) (evaluation, error) {
+	expired, err := job.store.expirePending(ctx, ConfirmationWindow)
+	if err != nil {
+		return evaluation{}, err
+	}
+	rearmed, err := job.store.rearm(ctx)
+	if err != nil {
+		return evaluation{}, err
+	}
+	result := evaluation{Expired: expired, Rearmed: rearmed}
+	afterID := int64(0)
+	for {
+		batch, err := job.store.notifyBatch(ctx, afterID, evaluationBatchSize, job.notify)
+		result.Notified += batch.Notified
+		result.Failed += batch.Failed
+		if err != nil {
+			return result, err
+		}
+		if batch.Rows < evaluationBatchSize {
+			return result, nil
+		}
+		afterID = batch.LastID
+	}
+}
+
+func (job *Job) notify(ctx context.Context, due dueWatch) error {
+	message := alertMessage(
+		due,
+		job.links.unsubscribe(job.tokens.sign(purposeManage, due.ID, 0)),
+		job.links.product(due.Slug),
+	)
+	if err := job.sender.Send(ctx, message); err != nil {
+		slog.Warn("price watch alert not delivered", "watch_id", due.ID, "error", err)
+		return err
+	}
+	return nil
+}
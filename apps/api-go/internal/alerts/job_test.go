@@ -0,0 +1,184 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeEvaluationStore struct {
+	latest   time.Time
+	due      []dueWatch
+	notified []int64
+	batches  int
+	rearms   int
+}
+
+func (store *fakeEvaluationStore) latestRefresh(context.Context) (time.Time, error) {
+	return store.latest, nil
+}
+
+func (store *fakeEvaluationStore) expirePending(context.Context, time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func (store *fakeEvaluationStore) rearm(context.Context) (int64, error) {
+	store.rearms++
+	return 0, nil
+}
+
+// notifyBatch mimics the locked batch query: due rows after afterID, and only
+// successful sends stop being due.
+func (store *fakeEvaluationStore) notifyBatch(
+	ctx context.Context,
+	afterID int64,
+	limit int,
+	notify notifyFunc,
+) (batchResult, error) {
+	store.batches++
+	result := batchResult{}
+	remaining := store.due[:0]
+	for _, watch := range store.due {
+		if watch.ID <= afterID || result.Rows == limit {
+			remaining = append(remaining, watch)
+			continue
+		}
+		result.Rows++
+		result.LastID = watch.ID
+		if err := notify(ctx, watch); err != nil {
+			result.Failed++
+			remaining = append(remaining, watch)
+			continue
+		}
+		result.Notified++
+		store.notified = append(store.notified, watch.ID)
+	}
+	store.due = remaining
+	return result, nil
+}
+
+func testDueWatch(id int64) dueWatch {
+	name := "Azul"
+	url := "https://tlamagames.test/azul"
+	return dueWatch{
+		storedWatch: storedWatch{
+			ID:    id,
+			Email: "hrac@example.test",
+			Watch: Watch{Slug: "azul", ProductName: &name, TargetPrice: 700, CurrencyCode: "CZK"},
+		},
+		OfferSeller:     "tlamagames",
+		OfferSellerName: "Tlama Games",
+		OfferPrice:      649,
+		OfferURL:        &url,
+	}
+}
+
+func TestJobEvaluatesOncePerRefresh(t *testing.T) {
+	store := &fakeEvaluationStore{
+		latest: time.Date(2026, 3, 7, 6, 0, 0, 0, time.UTC),
+		due:    []dueWatch{testDueWatch(1), testDueWatch(2)},
+	}
+	sender := &recordingSender{}
+	job := newJob(store, NewTokens("test-secret-that-is-long-enough-for-hmac"), sender, NewLinks("https://www.deskovkylevne.test"), time.Minute, time.Second)
+
+	if err := job.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if len(store.notified) != 2 || len(sender.messages) != 2 {
+		t.Fatalf("expected two alerts, got %v / %d", store.notified, len(sender.messages))
+	}
+	message := sender.messages[0]
+	if message.Subject != "Azul za 649 CZK u Tlama Games" {
+		t.Fatalf("unexpected subject %q", message.Subject)
+	}
+	if !strings.Contains(message.Text, "Nabídka: https://tlamagames.test/azul") ||
+		!strings.Contains(message.Text, "Hlídali jste cenu 700 CZK") ||
+		!strings.HasPrefix(message.UnsubscribeURL, "https://www.deskovkylevne.test/hlidani-ceny/zrusit#") {
+		t.Fatalf("unexpected alert mail: %#v", message)
+	}
+
+	store.due = []dueWatch{testDueWatch(3)}
+	if err := job.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if store.batches != 1 || store.rearms != 1 {
+		t.Fatalf("unchanged read model must not be evaluated again: %d batches", store.batches)
+	}
+	store.latest = store.latest.Add(time.Hour)
+	if err := job.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if len(store.notified) != 3 {
+		t.Fatalf("expected the next refresh to be evaluated, got %v", store.notified)
+	}
+}
+
+func TestJobKeepsFailedAlertsDueAndPagesBatches(t *testing.T) {
+	due := make([]dueWatch, 0, evaluationBatchSize+1)
+	for id := range int64(evaluationBatchSize + 1) {
+		due = append(due, testDueWatch(id+1))
+	}
+	store := &fakeEvaluationStore{latest: time.Now(), due: due}
+	sender := &recordingSender{err: errors.New("relay down")}
+	job := newJob(store, NewTokens("test-secret-that-is-long-enough-for-hmac"), sender, NewLinks("https://www.deskovkylevne.test"), time.Minute, time.Second)
+
+	result, err := job.evaluate(context.Background())
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if result.Failed != evaluationBatchSize+1 || result.Notified != 0 || store.batches != 2 {
+		t.Fatalf("unexpected evaluation %#v after %d batches", result, store.batches)
+	}
+	if len(store.due) != evaluationBatchSize+1 {
+		t.Fatalf("failed alerts must stay due, %d left", len(store.due))
+	}
+}
+
+func TestTokensRoundTripAndBindPurpose(t *testing.T) {
+	tokens := NewTokens("test-secret-that-is-long-enough-for-hmac")
+	token := tokens.sign(purposeManage, 42, 0)
+	id, mac, ok := parseToken(token)
+	if !ok || id != 42 || !tokens.valid(purposeManage, id, 0, mac) {
+		t.Fatalf("token did not round-trip: %q", token)
+	}
+	if tokens.valid(purposeConfirm, id, 0, mac) || tokens.valid(purposeManage, 43, 0, mac) {
+		t.Fatal("token must be bound to its purpose and watch")
+	}
+	if _, _, ok := parseToken(token[:len(token)-2]); ok {
+		t.Fatal("truncated token parsed")
+	}
+}
+
+func TestEvaluationQueriesOnlyMatchFreshOffersAndLockBatches(t *testing.T) {
+	for _, query := range []string{dueWatchesQuery, rearmWatchesQuery} {
+		for _, fragment := range []string{
+			"offer.latest_price <= watch.target_price",
+			"offer.currency_code = watch.currency_code",
+			"now() - public.catalog_seller_stale_after(offer.seller)",
+		} {
+			if !strings.Contains(query, fragment) {
+				t.Fatalf("query lacks %q:\n%s", fragment, query)
+			}
+		}
+	}
+	for _, fragment := range []string{
+		"where watch.confirmed_at is not null",
+		"best.latest_price < watch.last_notified_price",
+		"for update of watch skip locked",
+	} {
+		if !strings.Contains(dueWatchesQuery, fragment) {
+			t.Fatalf("due query lacks %q", fragment)
+		}
+	}
+}
+
+func TestWatchCreationCountsUnderAPerEmailLock(t *testing.T) {
+	if !strings.Contains(lockEmailWatchesQuery, "pg_advisory_xact_lock(hashtext('tlamasite:price_watches:' || $1))") {
+		t.Fatal("watch creation must hold a transaction lock per email")
+	}
+	if !strings.Contains(countOtherWatchesQuery, "where email = $1") {
+		t.Fatal("the watch limit must be counted per email")
+	}
+}
@@ -0,0 +1,76 @@
+package alerts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tlamasite/apps/api-go/internal/mail"
+)
+
+func confirmationMessage(watch storedWatch, confirmURL string, unsubscribeURL string, productURL string) mail.Message {
+	name := productName(watch.Watch)
+	var text strings.Builder
+	text.WriteString("Dobrý den,\n\n")
+	fmt.Fprintf(
+		&text,
+		"někdo (nejspíš vy) požádal o upozornění, až cena hry %s klesne na %s %s nebo níž%s.\n\n",
+		name, formatPrice(watch.TargetPrice), watch.CurrencyCode, sellerScope(watch.Seller),
+	)
+	fmt.Fprintf(&text, "Hlídání potvrdíte zde:\n%s\n\n", confirmURL)
+	fmt.Fprintf(&text, "Detail hry: %s\n\n", productURL)
+	fmt.Fprintf(
+		&text,
+		"Pokud jste o upozornění nežádali, e-mail ignorujte. Nepotvrzené hlídání do %d hodin smažeme.\n",
+		int(ConfirmationWindow.Hours()),
+	)
+	fmt.Fprintf(&text, "Zrušit ho můžete i hned: %s\n", unsubscribeURL)
+	return mail.Message{
+		To:             watch.Email,
+		Subject:        "Potvrďte hlídání ceny: " + name,
+		Text:           text.String(),
+		UnsubscribeURL: unsubscribeURL,
+	}
+}
+
+func alertMessage(due dueWatch, unsubscribeURL string, productURL string) mail.Message {
+	name := productName(due.Watch)
+	price := formatPrice(due.OfferPrice)
+	var text strings.Builder
+	text.WriteString("Dobrý den,\n\n")
+	fmt.Fprintf(
+		&text,
+		"cena hry %s klesla na %s %s u prodejce %s. Hlídali jste cenu %s %s.\n\n",
+		name, price, due.CurrencyCode, due.OfferSellerName, formatPrice(due.TargetPrice), due.CurrencyCode,
+	)
+	if due.OfferURL != nil && *due.OfferURL != "" {
+		fmt.Fprintf(&text, "Nabídka: %s\n", *due.OfferURL)
+	}
+	fmt.Fprintf(&text, "Detail hry: %s\n\n", productURL)
+	text.WriteString("Další upozornění pošleme, až cena klesne ještě níž nebo až se po zdražení znovu dostane pod vaši hranici.\n\n")
+	fmt.Fprintf(&text, "Hlídání zrušíte zde: %s\n", unsubscribeURL)
+	return mail.Message{
+		To:             due.Email,
+		Subject:        fmt.Sprintf("%s za %s %s u %s", name, price, due.CurrencyCode, due.OfferSellerName),
+		Text:           text.String(),
+		UnsubscribeURL: unsubscribeURL,
+	}
+}
+
+func productName(watch Watch) string {
+	if watch.ProductName == nil || strings.TrimSpace(*watch.ProductName) == "" {
+		return watch.Slug
+	}
+	return *watch.ProductName
+}
+
+func sellerScope(seller *string) string {
+	if seller == nil {
+		return ""
+	}
+	return " u prodejce " + *seller
+}
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
@@ -0,0 +1,186 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository runs as the API role, which may write catalog_price_watches but
+// nothing else.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// The watch stores the currency of the offer it targets, so a seller that
+// switches currency stops matching rather than comparing unlike amounts.
+const resolveWatchProductQuery = `
+select
+  state.product_name_normalized,
+  state.product_name,
+  coalesce(offer.currency_code, state.currency_code, 'CZK')::text,
+  offer.seller is not null
+from public.catalog_slug_state state
+left join public.catalog_slug_seller_state offer
+  on offer.product_name_normalized = state.product_name_normalized
+  and offer.seller = $2
+where state.product_name_normalized = public.canonical_product_slug(null, null, $1);`
+
+// lockEmailWatchesQuery serializes watch creation per address so concurrent
+// requests cannot both pass the MaxWatchesPerEmail check.
+const lockEmailWatchesQuery = `
+select pg_advisory_xact_lock(hashtext('tlamasite:price_watches:' || $1));`
+
+const countOtherWatchesQuery = `
+select count(*)
+from public.catalog_price_watches
+where email = $1
+  and not (product_name_normalized = $2 and coalesce(seller, '') = coalesce($3, ''));`
+
+// Confirmed watches are left alone: without the emailed token nobody may
+// change or unconfirm someone else's watch.
+const upsertWatchQuery = `
+insert into public.catalog_price_watches (
+  product_name_normalized, seller, target_price, currency_code, email
+)
+values ($1, $2, $3, $4, $5)
+on conflict (email, product_name_normalized, coalesce(seller, '')) do update set
+  target_price = excluded.target_price,
+  currency_code = excluded.currency_code,
+  created_at = now(),
+  updated_at = now()
+where catalog_price_watches.confirmed_at is null
+  and catalog_price_watches.created_at < now() - make_interval(secs => $6)
+returning id, target_price::double precision, created_at;`
+
+const watchByIDQuery = `
+select
+  watch.id,
+  watch.email,
+  watch.created_at,
+  watch.product_name_normalized,
+  state.product_name,
+  watch.seller,
+  watch.target_price::double precision,
+  watch.currency_code,
+  watch.confirmed_at,
+  watch.last_notified_at
+from public.catalog_price_watches watch
+left join public.catalog_slug_state state
+  on state.product_name_normalized = watch.product_name_normalized
+where watch.id = $1;`
+
+const confirmWatchQuery = `
+update public.catalog_price_watches
+set confirmed_at = coalesce(confirmed_at, now()), updated_at = now()
+where id = $1 and created_at = $2
+returning confirmed_at;`
+
+const deleteWatchQuery = `delete from public.catalog_price_watches where id = $1;`
+
+func (repository *Repository) createWatch(ctx context.Context, request WatchRequest) (storedWatch, bool, error) {
+	watch := storedWatch{Email: request.Email, Watch: Watch{Seller: request.Seller}}
+	var offered bool
+	err := repository.db.QueryRow(ctx, resolveWatchProductQuery, request.Slug, request.Seller).Scan(
+		&watch.Slug,
+		&watch.ProductName,
+		&watch.CurrencyCode,
+		&offered,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storedWatch{}, false, ErrProductNotFound
+	}
+	if err != nil {
+		return storedWatch{}, false, err
+	}
+	if request.Seller != nil && !offered {
+		return storedWatch{}, false, ErrOfferNotFound
+	}
+
+	tx, err := repository.db.Begin(ctx)
+	if err != nil {
+		return storedWatch{}, false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, lockEmailWatchesQuery, request.Email); err != nil {
+		return storedWatch{}, false, err
+	}
+	var others int
+	if err := tx.QueryRow(
+		ctx, countOtherWatchesQuery, request.Email, watch.Slug, request.Seller,
+	).Scan(&others); err != nil {
+		return storedWatch{}, false, err
+	}
+	if others >= MaxWatchesPerEmail {
+		return storedWatch{}, false, ErrTooManyWatches
+	}
+
+	err = tx.QueryRow(
+		ctx,
+		upsertWatchQuery,
+		watch.Slug,
+		request.Seller,
+		request.TargetPrice,
+		watch.CurrencyCode,
+		request.Email,
+		ResendInterval.Seconds(),
+	).Scan(&watch.ID, &watch.TargetPrice, &watch.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return watch, false, nil
+	}
+	if err != nil {
+		return storedWatch{}, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return storedWatch{}, false, err
+	}
+	return watch, true, nil
+}
+
+func (repository *Repository) watchByID(ctx context.Context, id int64) (storedWatch, error) {
+	var watch storedWatch
+	err := repository.db.QueryRow(ctx, watchByIDQuery, id).Scan(
+		&watch.ID,
+		&watch.Email,
+		&watch.CreatedAt,
+		&watch.Slug,
+		&watch.ProductName,
+		&watch.Seller,
+		&watch.TargetPrice,
+		&watch.CurrencyCode,
+		&watch.ConfirmedAt,
+		&watch.LastNotifiedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storedWatch{}, ErrWatchNotFound
+	}
+	return watch, err
+}
+
+func (repository *Repository) confirmWatch(ctx context.Context, id int64, createdAt time.Time) (time.Time, error) {
+	var confirmedAt time.Time
+	err := repository.db.QueryRow(ctx, confirmWatchQuery, id, createdAt).Scan(&confirmedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, ErrWatchNotFound
+	}
+	return confirmedAt, err
+}
+
+func (repository *Repository) deleteWatch(ctx context.Context, id int64) error {
+	tag, err := repository.db.Exec(ctx, deleteWatchQuery, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWatchNotFound
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package alerts
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"net/url"
+	"strings"
+)
+
+const (
+	purposeConfirm = "confirm"
+	purposeManage  = "manage"
+
+	tokenIDBytes = 8
+)
+
+// Tokens signs watch ids for emailed links. Nothing is stored, so a leaked
+// database holds no usable links, and rotating the secret revokes all of them.
+type Tokens struct {
+	secret []byte
+}
+
+func NewTokens(secret string) Tokens {
+	return Tokens{secret: []byte(secret)}
+}
+
+func (tokens Tokens) sign(purpose string, id int64, version int64) string {
+	raw := binary.BigEndian.AppendUint64(make([]byte, 0, tokenIDBytes+sha256.Size), uint64(id))
+	raw = append(raw, tokens.mac(purpose, id, version)...)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func (tokens Tokens) valid(purpose string, id int64, version int64, mac []byte) bool {
+	return hmac.Equal(mac, tokens.mac(purpose, id, version))
+}
+
+func (tokens Tokens) mac(purpose string, id int64, version int64) []byte {
+	hash := hmac.New(sha256.New, tokens.secret)
+	hash.Write([]byte(purpose))
+	hash.Write([]byte{0})
+	hash.Write(binary.BigEndian.AppendUint64(nil, uint64(id)))
+	hash.Write(binary.BigEndian.AppendUint64(nil, uint64(version)))
+	return hash.Sum(nil)
+}
+
+func parseToken(token string) (int64, []byte, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(token))
+	if err != nil || len(raw) != tokenIDBytes+sha256.Size {
+		return 0, nil, false
+	}
+	id := int64(binary.BigEndian.Uint64(raw[:tokenIDBytes]))
+	if id <= 0 {
+		return 0, nil, false
+	}
+	return id, raw[tokenIDBytes:], true
+}
+
+const (
+	confirmPath     = "/hlidani-ceny/potvrdit"
+	unsubscribePath = "/hlidani-ceny/zrusit"
+	productPathBase = "/deskove-hry/"
+)
+
+// Links builds public site URLs for mail. Tokens travel in the fragment so
+// they never reach access logs; the site page posts them to the API.
+type Links struct {
+	siteURL string
+}
+
+func NewLinks(siteURL string) Links {
+	return Links{siteURL: strings.TrimRight(siteURL, "/")}
+}
+
+func (links Links) confirm(token string) string {
+	return links.siteURL + confirmPath + "#" + token
+}
+
+func (links Links) unsubscribe(token string) string {
+	return links.siteURL + unsubscribePath + "#" + token
+}
+
+func (links Links) product(slug string) string {
+	return links.siteURL + productPathBase + url.PathEscape(slug)
+}
@@ -0,0 +1,159 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"tlamasite/apps/api-go/internal/mail"
+)
+
+const (
+	// MaxWatchesPerEmail bounds how many products one address can watch, which
+	// also bounds how much mail a single address can be made to receive.
+	MaxWatchesPerEmail = 50
+	// ConfirmationWindow is how long a pending watch waits for its address to
+	// confirm before the evaluator deletes it.
+	ConfirmationWindow = 48 * time.Hour
+	// ResendInterval throttles confirmation mail when the same pending watch is
+	// requested repeatedly.
+	ResendInterval = 10 * time.Minute
+)
+
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrOfferNotFound   = errors.New("seller does not offer this product")
+	ErrWatchNotFound   = errors.New("price watch not found")
+	ErrTooManyWatches  = errors.New("too many price watches for this address")
+	ErrDeliveryFailed  = errors.New("confirmation email could not be sent")
+)
+
+// WatchRequest asks for a mail once the price of Slug drops to TargetPrice.
+// A nil Seller watches the cheapest fresh offer of any seller.
+type WatchRequest struct {
+	Slug        string
+	Seller      *string
+	TargetPrice float64
+	Email       string
+}
+
+// Watch is what the owner of a confirm or unsubscribe link may see. The
+// address is left out because the links are shareable.
+type Watch struct {
+	Slug           string     `json:"slug"`
+	ProductName    *string    `json:"product_name"`
+	Seller         *string    `json:"seller"`
+	TargetPrice    float64    `json:"target_price"`
+	CurrencyCode   string     `json:"currency_code"`
+	ConfirmedAt    *time.Time `json:"confirmed_at"`
+	LastNotifiedAt *time.Time `json:"last_notified_at"`
+}
+
+type storedWatch struct {
+	Watch
+	ID        int64
+	Email     string
+	CreatedAt time.Time
+}
+
+type watchStore interface {
+	createWatch(ctx context.Context, request WatchRequest) (storedWatch, bool, error)
+	watchByID(ctx context.Context, id int64) (storedWatch, error)
+	confirmWatch(ctx context.Context, id int64, createdAt time.Time) (time.Time, error)
+	deleteWatch(ctx context.Context, id int64) error
+}
+
+// Service implements double opt-in: a watch only sends alerts after the
+// address confirmed it through the emailed link.
+type Service struct {
+	store  watchStore
+	tokens Tokens
+	sender mail.Sender
+	links  Links
+	now    func() time.Time
+}
+
+func NewService(repository *Repository, tokens Tokens, sender mail.Sender, links Links) *Service {
+	return &Service{store: repository, tokens: tokens, sender: sender, links: links, now: time.Now}
+}
+
+// Create stores a pending watch and mails its confirmation link. Requests for
+// an already confirmed watch, or repeats within ResendInterval, succeed
+// without sending anything, so the response never reveals which addresses
+// watch what.
+func (service *Service) Create(ctx context.Context, request WatchRequest) error {
+	watch, created, err := service.store.createWatch(ctx, request)
+	if err != nil || !created {
+		return err
+	}
+	message := confirmationMessage(
+		watch,
+		service.links.confirm(service.tokens.sign(purposeConfirm, watch.ID, confirmVersion(watch))),
+		service.links.unsubscribe(service.tokens.sign(purposeManage, watch.ID, 0)),
+		service.links.product(watch.Slug),
+	)
+	if err := service.sender.Send(ctx, message); err != nil {
+		// Drop the watch so the address can retry at once instead of waiting
+		// out the resend interval.
+		_ = service.store.deleteWatch(context.WithoutCancel(ctx), watch.ID)
+		return fmt.Errorf("%w: %w", ErrDeliveryFailed, err)
+	}
+	return nil
+}
+
+// Confirm activates the watch behind a confirm token. Confirming twice is not
+// an error, because mail scanners and double clicks both replay links.
+func (service *Service) Confirm(ctx context.Context, token string) (Watch, error) {
+	watch, err := service.resolve(ctx, purposeConfirm, token)
+	if err != nil {
+		return Watch{}, err
+	}
+	if watch.ConfirmedAt == nil && service.now().Sub(watch.CreatedAt) > ConfirmationWindow {
+		return Watch{}, ErrWatchNotFound
+	}
+	confirmedAt, err := service.store.confirmWatch(ctx, watch.ID, watch.CreatedAt)
+	if err != nil {
+		return Watch{}, err
+	}
+	watch.ConfirmedAt = &confirmedAt
+	return watch.Watch, nil
+}
+
+// Delete removes a watch. Both the confirm and the unsubscribe token work, so
+// a mistaken request can be withdrawn from the confirmation mail.
+func (service *Service) Delete(ctx context.Context, token string) error {
+	watch, err := service.resolve(ctx, purposeManage, token)
+	if errors.Is(err, ErrWatchNotFound) {
+		watch, err = service.resolve(ctx, purposeConfirm, token)
+	}
+	if err != nil {
+		return err
+	}
+	return service.store.deleteWatch(ctx, watch.ID)
+}
+
+func (service *Service) resolve(ctx context.Context, purpose string, token string) (storedWatch, error) {
+	id, mac, ok := parseToken(token)
+	if !ok {
+		return storedWatch{}, ErrWatchNotFound
+	}
+	watch, err := service.store.watchByID(ctx, id)
+	if err != nil {
+		return storedWatch{}, err
+	}
+	version := int64(0)
+	if purpose == purposeConfirm {
+		version = confirmVersion(watch)
+	}
+	if !service.tokens.valid(purpose, id, version, mac) {
+		return storedWatch{}, ErrWatchNotFound
+	}
+	return watch, nil
+}
+
+// confirmVersion binds confirm links to one request: a repeated request
+// resets created_at, which invalidates links mailed for the previous target.
+func confirmVersion(watch storedWatch) int64 {
+	return watch.CreatedAt.UnixMicro()
+}
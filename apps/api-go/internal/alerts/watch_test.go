@@ -0,0 +1,243 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tlamasite/apps/api-go/internal/mail"
+)
+
+type fakeWatchStore struct {
+	watches map[int64]storedWatch
+	nextID  int64
+	// existing makes createWatch report an unchanged watch, as the upsert does
+	// for confirmed watches and repeats within the resend interval.
+	existing bool
+	err      error
+}
+
+func newFakeWatchStore() *fakeWatchStore {
+	return &fakeWatchStore{watches: map[int64]storedWatch{}}
+}
+
+func (store *fakeWatchStore) createWatch(_ context.Context, request WatchRequest) (storedWatch, bool, error) {
+	if store.err != nil || store.existing {
+		return storedWatch{}, false, store.err
+	}
+	store.nextID++
+	name := "Azul"
+	watch := storedWatch{
+		ID:        store.nextID,
+		Email:     request.Email,
+		CreatedAt: time.Date(2026, 3, 7, 8, 0, 0, 123456000, time.UTC),
+		Watch: Watch{
+			Slug:         request.Slug,
+			ProductName:  &name,
+			Seller:       request.Seller,
+			TargetPrice:  request.TargetPrice,
+			CurrencyCode: "CZK",
+		},
+	}
+	store.watches[watch.ID] = watch
+	return watch, true, nil
+}
+
+func (store *fakeWatchStore) watchByID(_ context.Context, id int64) (storedWatch, error) {
+	watch, ok := store.watches[id]
+	if !ok {
+		return storedWatch{}, ErrWatchNotFound
+	}
+	return watch, nil
+}
+
+func (store *fakeWatchStore) confirmWatch(_ context.Context, id int64, createdAt time.Time) (time.Time, error) {
+	watch, ok := store.watches[id]
+	if !ok || !watch.CreatedAt.Equal(createdAt) {
+		return time.Time{}, ErrWatchNotFound
+	}
+	if watch.ConfirmedAt == nil {
+		confirmedAt := createdAt.Add(time.Hour)
+		watch.ConfirmedAt = &confirmedAt
+		store.watches[id] = watch
+	}
+	return *watch.ConfirmedAt, nil
+}
+
+func (store *fakeWatchStore) deleteWatch(_ context.Context, id int64) error {
+	if _, ok := store.watches[id]; !ok {
+		return ErrWatchNotFound
+	}
+	delete(store.watches, id)
+	return nil
+}
+
+type recordingSender struct {
+	mu       sync.Mutex
+	messages []mail.Message
+	err      error
+}
+
+func (sender *recordingSender) Send(_ context.Context, message mail.Message) error {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	if sender.err != nil {
+		return sender.err
+	}
+	sender.messages = append(sender.messages, message)
+	return nil
+}
+
+func newTestService(store watchStore, sender mail.Sender) *Service {
+	return &Service{
+		store:  store,
+		tokens: NewTokens("test-secret-that-is-long-enough-for-hmac"),
+		sender: sender,
+		links:  NewLinks("https://www.deskovkylevne.test/"),
+		now:    func() time.Time { return time.Date(2026, 3, 8, 8, 0, 0, 0, time.UTC) },
+	}
+}
+
+// linkToken extracts the fragment token of the first line containing path.
+func linkToken(t *testing.T, text string, path string) string {
+	t.Helper()
+	for _, line := range strings.Split(text, "\n") {
+		if _, after, ok := strings.Cut(line, "https://www.deskovkylevne.test"+path+"#"); ok {
+			return after
+		}
+	}
+	t.Fatalf("no %s link in %q", path, text)
+	return ""
+}
+
+func TestServiceCreateMailsConfirmationAndConfirmActivates(t *testing.T) {
+	store := newFakeWatchStore()
+	sender := &recordingSender{}
+	service := newTestService(store, sender)
+	seller := "tlamagames"
+
+	if err := service.Create(context.Background(), WatchRequest{
+		Slug: "azul", Seller: &seller, TargetPrice: 699.5, Email: "hrac@example.test",
+	}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(sender.messages) != 1 {
+		t.Fatalf("expected one confirmation mail, got %d", len(sender.messages))
+	}
+	message := sender.messages[0]
+	if message.To != "hrac@example.test" || !strings.Contains(message.Subject, "Azul") {
+		t.Fatalf("unexpected confirmation mail: %#v", message)
+	}
+	if !strings.Contains(message.Text, "699.5 CZK nebo níž u prodejce tlamagames") ||
+		!strings.Contains(message.Text, "https://www.deskovkylevne.test/deskove-hry/azul") {
+		t.Fatalf("confirmation text lacks the watch terms: %q", message.Text)
+	}
+	if !strings.HasPrefix(message.UnsubscribeURL, "https://www.deskovkylevne.test/hlidani-ceny/zrusit#") {
+		t.Fatalf("unexpected unsubscribe URL %q", message.UnsubscribeURL)
+	}
+
+	confirmToken := linkToken(t, message.Text, confirmPath)
+	if _, err := service.Confirm(context.Background(), message.UnsubscribeURL[strings.Index(message.UnsubscribeURL, "#")+1:]); !errors.Is(err, ErrWatchNotFound) {
+		t.Fatalf("unsubscribe token must not confirm, got %v", err)
+	}
+	watch, err := service.Confirm(context.Background(), confirmToken)
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	if watch.ConfirmedAt == nil || watch.Slug != "azul" || *watch.Seller != "tlamagames" {
+		t.Fatalf("unexpected confirmed watch: %#v", watch)
+	}
+	again, err := service.Confirm(context.Background(), confirmToken)
+	if err != nil || !again.ConfirmedAt.Equal(*watch.ConfirmedAt) {
+		t.Fatalf("confirming twice must be idempotent: %#v, %v", again, err)
+	}
+}
+
+func TestServiceRejectsForgedAndReissuedConfirmTokens(t *testing.T) {
+	store := newFakeWatchStore()
+	sender := &recordingSender{}
+	service := newTestService(store, sender)
+	if err := service.Create(context.Background(), WatchRequest{Slug: "azul", TargetPrice: 500, Email: "hrac@example.test"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	token := linkToken(t, sender.messages[0].Text, confirmPath)
+
+	forged := NewTokens("another-secret-of-the-same-kind-1234").sign(purposeConfirm, 1, confirmVersion(store.watches[1]))
+	for _, candidate := range []string{"", "not-base64!", forged} {
+		if _, err := service.Confirm(context.Background(), candidate); !errors.Is(err, ErrWatchNotFound) {
+			t.Fatalf("token %q: expected ErrWatchNotFound, got %v", candidate, err)
+		}
+	}
+
+	// A repeated request resets created_at, so the earlier link stops working.
+	watch := store.watches[1]
+	watch.CreatedAt = watch.CreatedAt.Add(time.Minute)
+	store.watches[1] = watch
+	if _, err := service.Confirm(context.Background(), token); !errors.Is(err, ErrWatchNotFound) {
+		t.Fatalf("stale confirm link must fail, got %v", err)
+	}
+}
+
+func TestServiceConfirmExpiresPendingWatches(t *testing.T) {
+	store := newFakeWatchStore()
+	sender := &recordingSender{}
+	service := newTestService(store, sender)
+	if err := service.Create(context.Background(), WatchRequest{Slug: "azul", TargetPrice: 500, Email: "hrac@example.test"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	service.now = func() time.Time { return store.watches[1].CreatedAt.Add(ConfirmationWindow + time.Minute) }
+	if _, err := service.Confirm(context.Background(), linkToken(t, sender.messages[0].Text, confirmPath)); !errors.Is(err, ErrWatchNotFound) {
+		t.Fatalf("expected expired confirmation to fail, got %v", err)
+	}
+}
+
+func TestServiceDeleteAcceptsEitherToken(t *testing.T) {
+	store := newFakeWatchStore()
+	sender := &recordingSender{}
+	service := newTestService(store, sender)
+	for range 2 {
+		if err := service.Create(context.Background(), WatchRequest{Slug: "azul", TargetPrice: 500, Email: "hrac@example.test"}); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	unsubscribe := sender.messages[0].UnsubscribeURL
+	if err := service.Delete(context.Background(), unsubscribe[strings.Index(unsubscribe, "#")+1:]); err != nil {
+		t.Fatalf("Delete with unsubscribe token: %v", err)
+	}
+	if err := service.Delete(context.Background(), linkToken(t, sender.messages[1].Text, confirmPath)); err != nil {
+		t.Fatalf("Delete with confirm token: %v", err)
+	}
+	if len(store.watches) != 0 {
+		t.Fatalf("watches not deleted: %#v", store.watches)
+	}
+	if err := service.Delete(context.Background(), linkToken(t, sender.messages[1].Text, confirmPath)); !errors.Is(err, ErrWatchNotFound) {
+		t.Fatalf("expected ErrWatchNotFound after delete, got %v", err)
+	}
+}
+
+func TestServiceCreateSkipsMailForExistingWatches(t *testing.T) {
+	store := newFakeWatchStore()
+	store.existing = true
+	sender := &recordingSender{}
+	if err := newTestService(store, sender).Create(context.Background(), WatchRequest{Slug: "azul", TargetPrice: 500, Email: "hrac@example.test"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(sender.messages) != 0 {
+		t.Fatalf("existing watches must not be mailed again: %#v", sender.messages)
+	}
+}
+
+func TestServiceCreateDropsWatchWhenMailFails(t *testing.T) {
+	store := newFakeWatchStore()
+	sender := &recordingSender{err: errors.New("relay down")}
+	err := newTestService(store, sender).Create(context.Background(), WatchRequest{Slug: "azul", TargetPrice: 500, Email: "hrac@example.test"})
+	if !errors.Is(err, ErrDeliveryFailed) {
+		t.Fatalf("expected ErrDeliveryFailed, got %v", err)
+	}
+	if len(store.watches) != 0 {
+		t.Fatalf("undeliverable watch was kept: %#v", store.watches)
+	}
+}
@@ -0,0 +1,149 @@
+package bgg
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxEntries bounds one import; larger BGG collections are rare and can be
+// split by the user.
+const MaxEntries = 2000
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Entry is one game from a BGG collection export. ID is zero when the export
+// does not carry an object id.
+type Entry struct {
+	ID           int64
+	Name         string
+	OriginalName string
+}
+
+// ParseExport reads either export BGG offers: the CSV download from the
+// collection page or a saved XML API collection response. The format is
+// detected from the first character.
+func ParseExport(data []byte) ([]Entry, error) {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, utf8BOM))
+	if len(data) == 0 {
+		return nil, errors.New("export is empty")
+	}
+	var entries []Entry
+	var err error
+	if data[0] == '<' {
+		entries, err = parseXML(data)
+	} else {
+		entries, err = parseCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > MaxEntries {
+		return nil, fmt.Errorf("export must list at most %d games", MaxEntries)
+	}
+	return entries, nil
+}
+
+func parseCSV(data []byte) ([]Entry, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("export is not a valid CSV file: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for index, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = index
+	}
+	nameColumn, ok := columns["objectname"]
+	if !ok {
+		return nil, errors.New("CSV export must have an objectname column")
+	}
+	idColumn, hasID := columns["objectid"]
+	originalColumn, hasOriginal := columns["originalname"]
+
+	entries := make([]Entry, 0, 64)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("export is not a valid CSV file: %w", err)
+		}
+		entry := Entry{Name: field(record, nameColumn)}
+		if hasOriginal {
+			entry.OriginalName = field(record, originalColumn)
+		}
+		if hasID {
+			if entry.ID, err = parseObjectID(field(record, idColumn)); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		if entry.Name != "" || entry.ID != 0 {
+			entries = append(entries, entry)
+		}
+	}
+}
+
+type xmlCollection struct {
+	XMLName xml.Name  `xml:"items"`
+	Items   []xmlItem `xml:"item"`
+}
+
+type xmlItem struct {
+	ObjectID     string `xml:"objectid,attr"`
+	Name         string `xml:"name"`
+	OriginalName string `xml:"originalname"`
+}
+
+func parseXML(data []byte) ([]Entry, error) {
+	var collection xmlCollection
+	if err := xml.Unmarshal(data, &collection); err != nil {
+		// BGG answers a cold collection request with a <message> asking to
+		// retry; saving that page is the usual way to end up here.
+		return nil, fmt.Errorf("export is not a BGG collection XML file: %w", err)
+	}
+	entries := make([]Entry, 0, len(collection.Items))
+	for index, item := range collection.Items {
+		id, err := parseObjectID(item.ObjectID)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", index+1, err)
+		}
+		entry := Entry{
+			ID:           id,
+			Name:         strings.TrimSpace(item.Name),
+			OriginalName: strings.TrimSpace(item.OriginalName),
+		}
+		if entry.Name != "" || entry.ID != 0 {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func parseObjectID(raw string) (int64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || id < 1 {
+		return 0, errors.New("objectid must be a positive integer")
+	}
+	return id, nil
+}
+
+func field(record []string, index int) string {
+	if index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
@@ -0,0 +1,71 @@
+package bgg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseExportReadsCSVColumnsByName(t *testing.T) {
+	data := "\ufeffobjectname,objectid,rating,originalname,wishlist\n" +
+		"Azul,230802,8,,1\n" +
+		"\"Carcassonne: Lovci a sběrači\",4390,N/A,Hunters and Gatherers,0\n" +
+		",,,,\n" +
+		"Bez ID,,,,1\n"
+	entries, err := ParseExport([]byte(data))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []Entry{
+		{ID: 230802, Name: "Azul"},
+		{ID: 4390, Name: "Carcassonne: Lovci a sběrači", OriginalName: "Hunters and Gatherers"},
+		{Name: "Bez ID"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %#v", len(want), entries)
+	}
+	for index := range want {
+		if entries[index] != want[index] {
+			t.Fatalf("entry %d: expected %#v, got %#v", index, want[index], entries[index])
+		}
+	}
+}
+
+func TestParseExportReadsCollectionXML(t *testing.T) {
+	data := `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<items totalitems="2" termsofuse="https://boardgamegeek.com/xmlapi/termsofuse">
+  <item objecttype="thing" objectid="230802" subtype="boardgame" collid="1">
+    <name sortindex="1">Azul</name>
+    <yearpublished>2017</yearpublished>
+    <status own="0" wishlist="1" />
+  </item>
+  <item objecttype="thing" objectid="822" subtype="boardgame" collid="2">
+    <name sortindex="1"> Carcassonne </name>
+    <originalname>Carcassonne</originalname>
+  </item>
+</items>`
+	entries, err := ParseExport([]byte(data))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(entries) != 2 ||
+		entries[0] != (Entry{ID: 230802, Name: "Azul"}) ||
+		entries[1] != (Entry{ID: 822, Name: "Carcassonne", OriginalName: "Carcassonne"}) {
+		t.Fatalf("unexpected entries %#v", entries)
+	}
+}
+
+func TestParseExportRejectsUnusableFiles(t *testing.T) {
+	tooMany := "objectname\n" + strings.Repeat("Azul\n", MaxEntries+1)
+	for name, data := range map[string]string{
+		"empty":          " \n",
+		"missing column": "name,id\nAzul,1\n",
+		"bad id":         "objectname,objectid\nAzul,abc\n",
+		"bgg message":    `<message>Your request for this collection has been accepted and will be processed.</message>`,
+		"broken xml":     `<items><item objectid="1"><name>Azul</item>`,
+		"too many":       tooMany,
+	} {
+		if _, err := ParseExport([]byte(data)); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package bgg
+
+import (
+	"context"
+
+	"tlamasite/apps/api-go/internal/catalog"
+)
+
+const (
+	MatchedByID    = "boardgamegeek_id"
+	MatchedByName  = "name"
+	MatchedByAlias = "alias"
+)
+
+// Result keeps the order of the export in both lists.
+type Result struct {
+	Matched   []Match   `json:"matched"`
+	Unmatched []Missing `json:"unmatched"`
+}
+
+type Match struct {
+	BoardGameGeekID *int64      `json:"boardgamegeek_id"`
+	Name            string      `json:"name"`
+	MatchedBy       string      `json:"matched_by"`
+	Product         catalog.Row `json:"product"`
+}
+
+type Missing struct {
+	BoardGameGeekID *int64 `json:"boardgamegeek_id"`
+	Name            string `json:"name"`
+}
+
+// lookup is what the matcher receives for one entry: the BGG id and the
+// catalog slugs derived from its names. Empty values never match.
+type lookup struct {
+	ID           int64
+	NameSlug     string
+	OriginalSlug string
+}
+
+// resolution is the canonical slug found for a lookup and how it was found.
+// Slug is empty when nothing matched.
+type resolution struct {
+	Slug      string
+	MatchedBy string
+}
+
+type matchStore interface {
+	match(ctx context.Context, lookups []lookup) ([]resolution, error)
+}
+
+type catalogRows interface {
+	FetchBySlugs(ctx context.Context, slugs []string) ([]catalog.Row, error)
+}
+
+type Service struct {
+	store   matchStore
+	catalog catalogRows
+}
+
+func NewService(repository *Repository, rows catalogRows) *Service {
+	return &Service{store: repository, catalog: rows}
+}
+
+// Import matches entries to catalog products. A BGG id listed in
+// catalog_boardgamegeek_ids wins; otherwise the name, then the original name,
+// is turned into a slug and resolved through the alias table.
+func (s *Service) Import(ctx context.Context, entries []Entry) (Result, error) {
+	result := Result{Matched: []Match{}, Unmatched: []Missing{}}
+	if len(entries) == 0 {
+		return result, nil
+	}
+	lookups := make([]lookup, len(entries))
+	for index, entry := range entries {
+		lookups[index] = lookupFor(entry)
+	}
+	resolutions, err := s.store.match(ctx, lookups)
+	if err != nil {
+		return Result{}, err
+	}
+
+	slugs := make([]string, 0, len(resolutions))
+	seen := make(map[string]struct{}, len(resolutions))
+	for _, resolved := range resolutions {
+		if _, exists := seen[resolved.Slug]; resolved.Slug == "" || exists {
+			continue
+		}
+		seen[resolved.Slug] = struct{}{}
+		slugs = append(slugs, resolved.Slug)
+	}
+	rows, err := s.catalog.FetchBySlugs(ctx, slugs)
+	if err != nil {
+		return Result{}, err
+	}
+	bySlug := make(map[string]catalog.Row, len(rows))
+	for _, row := range rows {
+		if row.ProductNameNormalized != nil {
+			bySlug[*row.ProductNameNormalized] = row
+		}
+	}
+
+	for index, entry := range entries {
+		var id *int64
+		if entry.ID != 0 {
+			id = &entry.ID
+		}
+		// A product can leave the read model between the two queries; it is
+		// then reported like any other miss.
+		if index < len(resolutions) {
+			if row, ok := bySlug[resolutions[index].Slug]; ok {
+				result.Matched = append(result.Matched, Match{
+					BoardGameGeekID: id,
+					Name:            entry.Name,
+					MatchedBy:       resolutions[index].MatchedBy,
+					Product:         row,
+				})
+				continue
+			}
+		}
+		result.Unmatched = append(result.Unmatched, Missing{BoardGameGeekID: id, Name: entry.Name})
+	}
+	return result, nil
+}
+
+func lookupFor(entry Entry) lookup {
+	result := lookup{
+		ID:           entry.ID,
+		NameSlug:     catalog.SlugFromName(entry.Name),
+		OriginalSlug: catalog.SlugFromName(entry.OriginalName),
+	}
+	if result.OriginalSlug == result.NameSlug {
+		result.OriginalSlug = ""
+	}
+	return result
+}
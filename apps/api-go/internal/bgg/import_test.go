@@ -0,0 +1,98 @@
+package bgg
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"tlamasite/apps/api-go/internal/catalog"
+)
+
+type fakeMatchStore struct {
+	lookups []lookup
+	matches map[string]resolution
+}
+
+func (store *fakeMatchStore) match(_ context.Context, lookups []lookup) ([]resolution, error) {
+	store.lookups = lookups
+	resolutions := make([]resolution, len(lookups))
+	for index, entry := range lookups {
+		resolutions[index] = store.matches[entry.NameSlug]
+	}
+	return resolutions, nil
+}
+
+type fakeCatalog struct {
+	requested []string
+}
+
+func (fake *fakeCatalog) FetchBySlugs(_ context.Context, slugs []string) ([]catalog.Row, error) {
+	fake.requested = slugs
+	rows := make([]catalog.Row, 0, len(slugs))
+	for _, slug := range slugs {
+		if slug == "vyprodano" {
+			continue
+		}
+		price := 649.0
+		rows = append(rows, catalog.Row{ProductNameNormalized: &slug, LatestPrice: &price})
+	}
+	return rows, nil
+}
+
+func TestImportSplitsMatchedAndUnmatchedInExportOrder(t *testing.T) {
+	store := &fakeMatchStore{matches: map[string]resolution{
+		"azul":                        {Slug: "azul", MatchedBy: MatchedByID},
+		"carcassonne-lovci-a-sberaci": {Slug: "carcassonne-hunters", MatchedBy: MatchedByAlias},
+		"azul-duplicate":              {Slug: "azul", MatchedBy: MatchedByName},
+		"gone":                        {Slug: "vyprodano", MatchedBy: MatchedByName},
+	}}
+	rows := &fakeCatalog{}
+	service := &Service{store: store, catalog: rows}
+
+	result, err := service.Import(context.Background(), []Entry{
+		{ID: 230802, Name: "Azul"},
+		{Name: "Neznámá hra"},
+		{ID: 4390, Name: "Carcassonne: Lovci a sběrači", OriginalName: "Hunters and Gatherers"},
+		{Name: "Azul duplicate"},
+		{Name: "Gone"},
+	})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if store.lookups[2].OriginalSlug != "hunters-and-gatherers" || store.lookups[1].ID != 0 {
+		t.Fatalf("unexpected lookups %#v", store.lookups)
+	}
+	if strings.Join(rows.requested, ",") != "azul,carcassonne-hunters,vyprodano" {
+		t.Fatalf("expected each product to be fetched once, got %v", rows.requested)
+	}
+	if len(result.Matched) != 3 ||
+		result.Matched[0].MatchedBy != MatchedByID || *result.Matched[0].BoardGameGeekID != 230802 ||
+		result.Matched[1].Name != "Carcassonne: Lovci a sběrači" || *result.Matched[1].Product.ProductNameNormalized != "carcassonne-hunters" ||
+		result.Matched[2].BoardGameGeekID != nil || *result.Matched[2].Product.LatestPrice != 649 {
+		t.Fatalf("unexpected matches %#v", result.Matched)
+	}
+	if len(result.Unmatched) != 2 || result.Unmatched[0].Name != "Neznámá hra" || result.Unmatched[1].Name != "Gone" {
+		t.Fatalf("unexpected misses %#v", result.Unmatched)
+	}
+}
+
+func TestLookupDropsOriginalNameWithSameSlug(t *testing.T) {
+	if got := lookupFor(Entry{Name: "Carcassonne", OriginalName: "CARCASSONNE"}); got.OriginalSlug != "" {
+		t.Fatalf("expected duplicate original name to be dropped, got %#v", got)
+	}
+}
+
+func TestMatchQueryPrefersIDsAndResolvesAliases(t *testing.T) {
+	for _, fragment := range []string{
+		"with ordinality",
+		"coalesce(by_id.slug, by_metadata.slug, by_name.slug, by_original.slug, '')",
+		"from public.catalog_boardgamegeek_ids ids",
+		"where public.catalog_boardgamegeek_id(offer.metadata) = input.boardgamegeek_id",
+		"public.canonical_product_slug(null, null, input.name_slug)",
+		"order by input.position",
+	} {
+		if !strings.Contains(matchQuery, fragment) {
+			t.Fatalf("match query lacks %q", fragment)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package bgg
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository only reads: the BGG id overrides, offer metadata, aliases and
+// catalog state.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// matchQuery resolves every entry in one round trip and keeps input order.
+// A BGG id matches through a curated override first, then through the id in
+// seller offer metadata. canonical_product_slug maps alias names to their
+// product; a slug that comes back unchanged was matched by name alone.
+const matchQuery = `
+with input as (
+  select
+    entry.position,
+    nullif(entry.boardgamegeek_id, 0) as boardgamegeek_id,
+    nullif(entry.name_slug, '') as name_slug,
+    nullif(entry.original_slug, '') as original_slug
+  from unnest($1::bigint[], $2::text[], $3::text[]) with ordinality
+    as entry(boardgamegeek_id, name_slug, original_slug, position)
+)
+select
+  coalesce(by_id.slug, by_metadata.slug, by_name.slug, by_original.slug, ''),
+  case
+    when coalesce(by_id.slug, by_metadata.slug) is not null then 'boardgamegeek_id'
+    when by_name.slug is not null then by_name.matched_by
+    when by_original.slug is not null then by_original.matched_by
+    else ''
+  end
+from input
+left join lateral (
+  select state.product_name_normalized as slug
+  from public.catalog_boardgamegeek_ids ids
+  join public.catalog_slug_state state
+    on state.product_name_normalized
+      = public.canonical_product_slug(null, null, ids.product_name_normalized)
+  where ids.boardgamegeek_id = input.boardgamegeek_id
+) by_id on true
+left join lateral (
+  select offer.product_name_normalized as slug
+  from public.catalog_slug_seller_state offer
+  join public.catalog_slug_state state
+    on state.product_name_normalized = offer.product_name_normalized
+  where public.catalog_boardgamegeek_id(offer.metadata) = input.boardgamegeek_id
+  order by public.seller_priority(offer.seller), offer.seller
+  limit 1
+) by_metadata on true
+left join lateral (
+  select
+    state.product_name_normalized as slug,
+    case when state.product_name_normalized = input.name_slug then 'name' else 'alias' end as matched_by
+  from public.catalog_slug_state state
+  where state.product_name_normalized = public.canonical_product_slug(null, null, input.name_slug)
+) by_name on true
+left join lateral (
+  select
+    state.product_name_normalized as slug,
+    case when state.product_name_normalized = input.original_slug then 'name' else 'alias' end as matched_by
+  from public.catalog_slug_state state
+  where state.product_name_normalized = public.canonical_product_slug(null, null, input.original_slug)
+) by_original on true
+order by input.position;`
+
+func (repository *Repository) match(ctx context.Context, lookups []lookup) ([]resolution, error) {
+	ids := make([]int64, len(lookups))
+	nameSlugs := make([]string, len(lookups))
+	originalSlugs := make([]string, len(lookups))
+	for index, entry := range lookups {
+		ids[index] = entry.ID
+		nameSlugs[index] = entry.NameSlug
+		originalSlugs[index] = entry.OriginalSlug
+	}
+	rows, err := repository.db.Query(ctx, matchQuery, ids, nameSlugs, originalSlugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resolutions := make([]resolution, 0, len(lookups))
+	for rows.Next() {
+		var resolved resolution
+		if err := rows.Scan(&resolved.Slug, &resolved.MatchedBy); err != nil {
+			return nil, err
+		}
+		resolutions = append(resolutions, resolved)
+	}
+	return resolutions, rows.Err()
+}
@@ -0,0 +1,278 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+)
+
+const (
+	MinCompareSlugs = 2
+	MaxCompareSlugs = 6
+)
+
+// Comparison lines up 2–6 products. AttributeKeys is the sorted union of the
+// normalized parameter keys, so every product exposes the same columns.
+type Comparison struct {
+	Products      []ComparedProduct `json:"products"`
+	AttributeKeys []string          `json:"attribute_keys"`
+	MissingSlugs  []string          `json:"missing_slugs"`
+}
+
+type ComparedProduct struct {
+	RequestedSlug         string               `json:"requested_slug"`
+	ProductNameNormalized string               `json:"product_name_normalized"`
+	ProductName           *string              `json:"product_name"`
+	HeroImageURL          *string              `json:"hero_image_url"`
+	BestOffer             *ComparedOffer       `json:"best_offer"`
+	Sellers               []ComparedOffer      `json:"sellers"`
+	SellerCount           int                  `json:"seller_count"`
+	AvailableSellerCount  int                  `json:"available_seller_count"`
+	Attributes            ComparedAttributes   `json:"attributes"`
+	Parameters            map[string]*string   `json:"parameters"`
+	PriceStats            []ComparedPriceStats `json:"price_stats"`
+}
+
+type ComparedOffer struct {
+	Seller             string   `json:"seller"`
+	CurrencyCode       *string  `json:"currency_code"`
+	LatestPrice        *float64 `json:"latest_price"`
+	ListPriceWithVat   *float64 `json:"list_price_with_vat"`
+	AvailabilityStatus *string  `json:"availability_status"`
+	IsAvailable        bool     `json:"is_available"`
+	SourceURL          *string  `json:"source_url"`
+	LatestScrapedAt    *string  `json:"latest_scraped_at"`
+	Stale              bool     `json:"stale"`
+	priority           int
+}
+
+type ComparedAttributes struct {
+	MinPlayers         *int `json:"min_players"`
+	MaxPlayers         *int `json:"max_players"`
+	MinPlaytimeMinutes *int `json:"min_playtime_minutes"`
+	MaxPlaytimeMinutes *int `json:"max_playtime_minutes"`
+	MinAge             *int `json:"min_age"`
+}
+
+// ComparedPriceStats aggregates seller-day history per currency; sellers are
+// only combined for these lows, never in a merged price series.
+type ComparedPriceStats struct {
+	CurrencyCode   *string  `json:"currency_code"`
+	AllTimeLow     *float64 `json:"all_time_low"`
+	LowestPrice30d *float64 `json:"lowest_price_30d"`
+	TrackedSellers int      `json:"tracked_sellers"`
+}
+
+// Compare loads the requested products in three queries regardless of how
+// many slugs are compared: canonical rows, seller offers, and price stats.
+func (r *Repository) Compare(ctx context.Context, slugs []string) (Comparison, error) {
+	comparison := Comparison{
+		Products:      make([]ComparedProduct, 0, len(slugs)),
+		AttributeKeys: []string{},
+		MissingSlugs:  []string{},
+	}
+	products, err := r.fetchComparedProducts(ctx, slugs, &comparison)
+	if err != nil || len(comparison.Products) == 0 {
+		return comparison, err
+	}
+	canonicalIDs := make([]string, 0, len(products))
+	for canonicalID := range products {
+		canonicalIDs = append(canonicalIDs, canonicalID)
+	}
+	offers, err := r.fetchComparedOffers(ctx, canonicalIDs)
+	if err != nil {
+		return Comparison{}, err
+	}
+	stats, err := r.fetchComparedPriceStats(ctx, canonicalIDs)
+	if err != nil {
+		return Comparison{}, err
+	}
+	assembleComparison(&comparison, offers, stats)
+	return comparison, nil
+}
+
+func (r *Repository) fetchComparedProducts(
+	ctx context.Context,
+	slugs []string,
+	comparison *Comparison,
+) (map[string]struct{}, error) {
+	rows, err := r.db.Query(ctx, compareProductsQuery, slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return collectComparedProducts(rows, comparison)
+}
+
+func collectComparedProducts(rows pgxRows, comparison *Comparison) (map[string]struct{}, error) {
+	canonicalIDs := make(map[string]struct{}, cap(comparison.Products))
+	for rows.Next() {
+		var product ComparedProduct
+		var canonicalID *string
+		var parameters json.RawMessage
+		if err := rows.Scan(
+			&product.RequestedSlug,
+			&canonicalID,
+			&product.ProductName,
+			&product.HeroImageURL,
+			&product.Attributes.MinPlayers,
+			&product.Attributes.MaxPlayers,
+			&product.Attributes.MinPlaytimeMinutes,
+			&product.Attributes.MaxPlaytimeMinutes,
+			&product.Attributes.MinAge,
+			&parameters,
+		); err != nil {
+			return nil, err
+		}
+		if canonicalID == nil {
+			comparison.MissingSlugs = append(comparison.MissingSlugs, product.RequestedSlug)
+			continue
+		}
+		// Aliases of an already listed product would repeat its column.
+		if _, seen := canonicalIDs[*canonicalID]; seen {
+			continue
+		}
+		product.ProductNameNormalized = *canonicalID
+		product.Parameters = normalizeParameters(parameters)
+		canonicalIDs[*canonicalID] = struct{}{}
+		comparison.Products = append(comparison.Products, product)
+	}
+	return canonicalIDs, rows.Err()
+}
+
+func (r *Repository) fetchComparedOffers(
+	ctx context.Context,
+	canonicalIDs []string,
+) (map[string][]ComparedOffer, error) {
+	rows, err := r.db.Query(ctx, compareOffersQuery, canonicalIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	offers := make(map[string][]ComparedOffer, len(canonicalIDs))
+	for rows.Next() {
+		var canonicalID string
+		var offer ComparedOffer
+		if err := rows.Scan(
+			&canonicalID,
+			&offer.Seller,
+			&offer.CurrencyCode,
+			&offer.LatestPrice,
+			&offer.ListPriceWithVat,
+			&offer.AvailabilityStatus,
+			&offer.IsAvailable,
+			&offer.SourceURL,
+			&offer.LatestScrapedAt,
+			&offer.Stale,
+			&offer.priority,
+		); err != nil {
+			return nil, err
+		}
+		offers[canonicalID] = append(offers[canonicalID], offer)
+	}
+	return offers, rows.Err()
+}
+
+func (r *Repository) fetchComparedPriceStats(
+	ctx context.Context,
+	canonicalIDs []string,
+) (map[string][]ComparedPriceStats, error) {
+	rows, err := r.db.Query(ctx, comparePriceStatsQuery, canonicalIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[string][]ComparedPriceStats, len(canonicalIDs))
+	for rows.Next() {
+		var canonicalID string
+		var entry ComparedPriceStats
+		if err := rows.Scan(
+			&canonicalID,
+			&entry.CurrencyCode,
+			&entry.AllTimeLow,
+			&entry.LowestPrice30d,
+			&entry.TrackedSellers,
+		); err != nil {
+			return nil, err
+		}
+		stats[canonicalID] = append(stats[canonicalID], entry)
+	}
+	return stats, rows.Err()
+}
+
+func assembleComparison(
+	comparison *Comparison,
+	offers map[string][]ComparedOffer,
+	stats map[string][]ComparedPriceStats,
+) {
+	keys := make(map[string]struct{})
+	for _, product := range comparison.Products {
+		for key := range product.Parameters {
+			keys[key] = struct{}{}
+		}
+	}
+	for key := range keys {
+		comparison.AttributeKeys = append(comparison.AttributeKeys, key)
+	}
+	sort.Strings(comparison.AttributeKeys)
+
+	for index := range comparison.Products {
+		product := &comparison.Products[index]
+		product.Sellers = offers[product.ProductNameNormalized]
+		if product.Sellers == nil {
+			product.Sellers = []ComparedOffer{}
+		}
+		product.SellerCount = len(product.Sellers)
+		for _, offer := range product.Sellers {
+			if offer.IsAvailable && !offer.Stale {
+				product.AvailableSellerCount++
+			}
+		}
+		product.BestOffer = bestOffer(product.Sellers)
+		product.PriceStats = stats[product.ProductNameNormalized]
+		if product.PriceStats == nil {
+			product.PriceStats = []ComparedPriceStats{}
+		}
+		for _, key := range comparison.AttributeKeys {
+			if _, exists := product.Parameters[key]; !exists {
+				product.Parameters[key] = nil
+			}
+		}
+	}
+}
+
+// bestOffer prefers available offers, then the lowest price, then seller
+// priority, so an out-of-stock bargain never hides a buyable offer. Stale
+// offers are skipped because their price may no longer exist.
+func bestOffer(offers []ComparedOffer) *ComparedOffer {
+	var best *ComparedOffer
+	for index := range offers {
+		candidate := &offers[index]
+		if candidate.LatestPrice == nil || candidate.Stale {
+			continue
+		}
+		if best == nil || offerRanksBefore(candidate, best) {
+			best = candidate
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	selected := *best
+	return &selected
+}
+
+func offerRanksBefore(candidate *ComparedOffer, current *ComparedOffer) bool {
+	if candidate.IsAvailable != current.IsAvailable {
+		return candidate.IsAvailable
+	}
+	if *candidate.LatestPrice != *current.LatestPrice {
+		return *candidate.LatestPrice < *current.LatestPrice
+	}
+	if candidate.priority != current.priority {
+		return candidate.priority < current.priority
+	}
+	return candidate.Seller < current.Seller
+}
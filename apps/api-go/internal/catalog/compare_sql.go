@@ -0,0 +1,76 @@
+package catalog
+
+const compareProductsQuery = `
+with requested as (
+  select
+    requested.slug,
+    requested.position,
+    public.canonical_product_slug(null, null, requested.slug) as canonical_product_id
+  from unnest($1::text[]) with ordinality as requested(slug, position)
+)
+select
+  requested.slug,
+  state.product_name_normalized,
+  state.product_name,
+  state.hero_image_url,
+  state.min_players,
+  state.max_players,
+  state.min_playtime_minutes,
+  state.max_playtime_minutes,
+  state.min_age,
+  coalesce(state.supplementary_parameters, '[]'::jsonb)
+from requested
+left join public.catalog_slug_state state
+  on state.product_name_normalized = requested.canonical_product_id
+order by requested.position asc;`
+
+const compareOffersQuery = `
+select
+  seller_state.product_name_normalized,
+  seller_state.seller,
+  seller_state.currency_code,
+  seller_state.latest_price::double precision,
+  seller_state.list_price_with_vat::double precision,
+  seller_state.availability_status,
+  coalesce(seller_state.is_available, false),
+  seller_state.source_url,
+  seller_state.latest_scraped_at::text,
+  coalesce(
+    seller_state.latest_scraped_at < now() - public.catalog_seller_stale_after(seller_state.seller),
+    true
+  ),
+  public.seller_priority(seller_state.seller)
+from public.catalog_slug_seller_state seller_state
+where seller_state.product_name_normalized = any($1::text[])
+order by
+  seller_state.product_name_normalized asc,
+  public.seller_priority(seller_state.seller) asc,
+  seller_state.seller asc;`
+
+// comparePriceStatsQuery measures lowest_price_30d per seller like product
+// stats: the 30 days preceding that seller's latest checked day.
+const comparePriceStatsQuery = `
+with seller_history as (
+  select
+    history.canonical_product_id,
+    history.seller,
+    history.currency_code,
+    history.price_date,
+    history.min_price,
+    max(history.price_date) over (
+      partition by history.canonical_product_id, history.seller
+    ) as latest_price_date
+  from public.catalog_daily_price_history history
+  where history.canonical_product_id = any($1::text[])
+)
+select
+  canonical_product_id,
+  currency_code,
+  min(min_price)::double precision,
+  (min(min_price) filter (
+    where price_date >= latest_price_date - 30 and price_date < latest_price_date
+  ))::double precision,
+  count(distinct seller)::integer
+from seller_history
+group by canonical_product_id, currency_code
+order by canonical_product_id asc, currency_code asc nulls last;`
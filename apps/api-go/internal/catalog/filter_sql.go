@@ -39,6 +39,13 @@ var categoryTagRules = map[string]categoryTagRule{
 	},
 }
 
+// CategoryClause matches supported category slugs against the tag columns
+// shared by catalog_slug_state and catalog_slug_seller_state, so seller-level
+// feeds filter categories exactly like the catalog does.
+func CategoryClause(args *[]any, categories []string) string {
+	return buildCategoryClause(args, categories)
+}
+
 func buildCategoryClause(args *[]any, categories []string) string {
 	clauses := make([]string, 0, len(categories))
 	for _, category := range categories {
@@ -0,0 +1,71 @@
+package catalog
+
+import (
+	"context"
+	"time"
+)
+
+// SellerFreshness reports how current one seller's offers are against its
+// catalog_sellers.stale_after threshold. Stale is set when even the newest
+// offer is past the threshold, which usually means the scraper stopped.
+type SellerFreshness struct {
+	Seller                string     `json:"seller"`
+	StaleAfterSeconds     int64      `json:"stale_after_seconds"`
+	OfferCount            int64      `json:"offer_count"`
+	StaleOfferCount       int64      `json:"stale_offer_count"`
+	NewestLatestScrapedAt *time.Time `json:"newest_latest_scraped_at"`
+	Stale                 bool       `json:"stale"`
+}
+
+type FreshnessReport struct {
+	Stale bool              `json:"stale"`
+	Rows  []SellerFreshness `json:"rows"`
+}
+
+// sellerFreshnessQuery resolves each seller's threshold once and lists stale
+// sellers first.
+const sellerFreshnessQuery = `
+with threshold as (
+  select seller_ids.seller, public.catalog_seller_stale_after(seller_ids.seller) as stale_after
+  from (select distinct seller from public.catalog_slug_seller_state) seller_ids
+)
+select
+  offer.seller,
+  extract(epoch from threshold.stale_after)::bigint,
+  count(*),
+  count(*) filter (
+    where offer.latest_scraped_at is null
+      or offer.latest_scraped_at < now() - threshold.stale_after
+  ),
+  max(offer.latest_scraped_at),
+  coalesce(max(offer.latest_scraped_at) < now() - threshold.stale_after, true) as stale
+from public.catalog_slug_seller_state offer
+join threshold on threshold.seller = offer.seller
+group by offer.seller, threshold.stale_after
+order by stale desc, offer.seller asc;`
+
+func (repository *Repository) SellerFreshness(ctx context.Context) (FreshnessReport, error) {
+	rows, err := repository.db.Query(ctx, sellerFreshnessQuery)
+	if err != nil {
+		return FreshnessReport{}, err
+	}
+	defer rows.Close()
+
+	report := FreshnessReport{Rows: make([]SellerFreshness, 0, 16)}
+	for rows.Next() {
+		var seller SellerFreshness
+		if err := rows.Scan(
+			&seller.Seller,
+			&seller.StaleAfterSeconds,
+			&seller.OfferCount,
+			&seller.StaleOfferCount,
+			&seller.NewestLatestScrapedAt,
+			&seller.Stale,
+		); err != nil {
+			return FreshnessReport{}, err
+		}
+		report.Stale = report.Stale || seller.Stale
+		report.Rows = append(report.Rows, seller)
+	}
+	return report, rows.Err()
+}
@@ -0,0 +1,108 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	parameterOrdinalPattern = regexp.MustCompile(`^\d+\.\s*`)
+	parameterKeyPattern     = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// normalizeParameters flattens the seller-specific supplementary_parameters
+// shapes (object, array of name/value records, or "name: value" strings) into
+// ASCII snake_case keys, so "Počet hráčů" and "pocet-hracu" share a column.
+// The first non-empty value wins for a repeated key.
+func normalizeParameters(raw json.RawMessage) map[string]*string {
+	parameters := make(map[string]*string)
+	var decoded any
+	if len(raw) == 0 || json.Unmarshal(raw, &decoded) != nil {
+		return parameters
+	}
+	switch typed := decoded.(type) {
+	case map[string]any:
+		names := make([]string, 0, len(typed))
+		for name := range typed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			addParameter(parameters, name, typed[name])
+		}
+	case []any:
+		for _, item := range typed {
+			addParameterItem(parameters, item)
+		}
+	}
+	return parameters
+}
+
+func addParameterItem(parameters map[string]*string, item any) {
+	switch typed := item.(type) {
+	case map[string]any:
+		addParameter(
+			parameters,
+			firstPresent(typed, "name", "label", "key", "title"),
+			firstPresent(typed, "value", "val", "text", "data"),
+		)
+	case string:
+		if name, value, found := strings.Cut(typed, ":"); found {
+			addParameter(parameters, name, value)
+		}
+	}
+}
+
+func addParameter(parameters map[string]*string, name any, value any) {
+	label, ok := name.(string)
+	if !ok {
+		return
+	}
+	key := normalizeParameterKey(label)
+	text := stringifyParameterValue(value)
+	if key == "" || text == "" || parameters[key] != nil {
+		return
+	}
+	parameters[key] = &text
+}
+
+func normalizeParameterKey(label string) string {
+	cleaned := parameterOrdinalPattern.ReplaceAllString(strings.TrimSpace(label), "")
+	cleaned = strings.ToLower(stripDiacritics(cleaned))
+	return strings.Trim(parameterKeyPattern.ReplaceAllString(cleaned, "_"), "_")
+}
+
+func stringifyParameterValue(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(typed)
+	case []any:
+		parts := make([]string, 0, len(typed))
+		for _, entry := range typed {
+			if text := stringifyParameterValue(entry); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, ", ")
+	case map[string]any:
+		return stringifyParameterValue(firstPresent(typed, "value", "val", "text"))
+	case float64:
+		return strings.TrimSpace(fmt.Sprintf("%g", typed))
+	default:
+		return strings.TrimSpace(fmt.Sprint(typed))
+	}
+}
+
+func firstPresent(record map[string]any, keys ...string) any {
+	for _, key := range keys {
+		if value, exists := record[key]; exists && value != nil {
+			return value
+		}
+	}
+	return nil
+}
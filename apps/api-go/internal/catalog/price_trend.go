@@ -0,0 +1,80 @@
+package catalog
+
+import (
+	"context"
+
+	"tlamasite/apps/api-go/internal/pricetrend"
+)
+
+// priceTrendHistoryQuery loads a year of closing prices of each slug's
+// primary seller for a whole page in one round trip, with the same window as
+// the product-detail trend.
+const priceTrendHistoryQuery = `
+with page as (
+  select state.product_name_normalized as slug, state.primary_seller
+  from public.catalog_slug_state state
+  where state.product_name_normalized = any($1::text[])
+    and state.primary_seller is not null
+),
+seller_history as (
+  select
+    page.slug,
+    history.price_date,
+    history.closing_price,
+    max(history.price_date) over (partition by page.slug) as latest_price_date
+  from page
+  join public.catalog_daily_price_history history
+    on history.canonical_product_id = page.slug
+   and history.seller = page.primary_seller
+  where history.closing_price is not null
+)
+select
+  slug,
+  price_date,
+  closing_price::double precision
+from seller_history
+where price_date > latest_price_date - 365
+order by slug asc, price_date asc;`
+
+// attachPriceTrends analyzes the primary seller's daily history of every row.
+// Rows without history get an unknown trend, as sellers do in product detail.
+func (r *Repository) attachPriceTrends(ctx context.Context, rows []Row) error {
+	slugs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.ProductNameNormalized != nil {
+			slugs = append(slugs, *row.ProductNameNormalized)
+		}
+	}
+	if len(slugs) == 0 {
+		return nil
+	}
+	history, err := r.db.Query(ctx, priceTrendHistoryQuery, slugs)
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+	return applyPriceTrends(rows, history)
+}
+
+func applyPriceTrends(rows []Row, history pgxRows) error {
+	pointsBySlug := make(map[string][]pricetrend.Point, len(rows))
+	for history.Next() {
+		var slug string
+		var point pricetrend.Point
+		if err := history.Scan(&slug, &point.Date, &point.Price); err != nil {
+			return err
+		}
+		pointsBySlug[slug] = append(pointsBySlug[slug], point)
+	}
+	if err := history.Err(); err != nil {
+		return err
+	}
+	for index := range rows {
+		if rows[index].ProductNameNormalized == nil {
+			continue
+		}
+		trend := pricetrend.Analyze(pointsBySlug[*rows[index].ProductNameNormalized])
+		rows[index].PriceTrend = &trend
+	}
+	return nil
+}
@@ -34,6 +34,11 @@ limit ` + limitPlaceholder + ` offset ` + offsetPlaceholder + `;`
 	return query, append(rowArgs, filters.Limit, filters.Offset)
 }
 
+func buildSlugRowsQuery(relation string) string {
+	return catalogRowsSelect + relation + `
+where product_name_normalized = any($1::text[]);`
+}
+
 const catalogRowsSelect = `
 select
   product_code,
@@ -161,6 +166,12 @@ func normalizeSearchQuery(value string) string {
 	return strings.Join(strings.Fields(cleaned), " ")
 }
 
+// SlugFromName derives the slug a product name gets in the catalog:
+// diacritics dropped and every run of other characters turned into a hyphen.
+func SlugFromName(name string) string {
+	return strings.ReplaceAll(normalizeSearchQuery(name), " ", "-")
+}
+
 func stripDiacritics(value string) string {
 	var builder strings.Builder
 	for _, character := range norm.NFD.String(value) {
@@ -45,6 +45,11 @@ func (r *Repository) Fetch(ctx context.Context, filters Filters) ([]Row, int64,
 	if err != nil {
 		return nil, 0, err
 	}
+	if filters.IncludeTrend {
+		if err := r.attachPriceTrends(ctx, results); err != nil {
+			return nil, 0, err
+		}
+	}
 	if len(results) == 0 && filters.Offset > 0 {
 		countSQL := "select count(*) from " + r.summaryRelation + whereSQL
 		if err := r.db.QueryRow(ctx, countSQL, args...).Scan(&total); err != nil {
@@ -54,6 +59,22 @@ func (r *Repository) Fetch(ctx context.Context, filters Filters) ([]Row, int64,
 	return results, total, nil
 }
 
+// FetchBySlugs returns the catalog rows of the given canonical slugs with the
+// same projection as Fetch. Rows come back in no particular order and unknown
+// slugs are skipped.
+func (r *Repository) FetchBySlugs(ctx context.Context, slugs []string) ([]Row, error) {
+	if len(slugs) == 0 {
+		return []Row{}, nil
+	}
+	rows, err := r.db.Query(ctx, buildSlugRowsQuery(r.summaryRelation), slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results, _, err := collectRows(rows)
+	return results, err
+}
+
 func (r *Repository) Search(
 	ctx context.Context,
 	query string,
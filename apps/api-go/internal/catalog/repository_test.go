@@ -1,8 +1,11 @@
 package catalog
 
 import (
+	"encoding/json"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNormalizeRelationName(t *testing.T) {
@@ -127,6 +130,19 @@ func TestNormalizeSearchQueryRemovesDiacriticsAndSpecialCharacters(t *testing.T)
 	}
 }
 
+func TestSlugFromNameMatchesCatalogSlugs(t *testing.T) {
+	for name, want := range map[string]string{
+		"Výbušná koťátka":                     "vybusna-kotatka",
+		"  Carcassonne: Hunters & Gatherers ": "carcassonne-hunters-gatherers",
+		"7 Wonders (Second Edition)":          "7-wonders-second-edition",
+		"***":                                 "",
+	} {
+		if got := SlugFromName(name); got != want {
+			t.Fatalf("%q: expected %q, got %q", name, want, got)
+		}
+	}
+}
+
 func TestBuildWhereUsesAllSearchTokensAgainstNameAndCode(t *testing.T) {
 	whereSQL, args := buildWhere(Filters{Query: "vybusna party"})
 
@@ -209,3 +225,226 @@ func TestBuildSearchQueryIncludesSellerCount(t *testing.T) {
 		t.Fatalf("unexpected args: %#v", args)
 	}
 }
+
+// fakeRows scans each row's values into the destinations by assignment, so
+// scan loops can be tested without a database.
+type fakeRows struct {
+	values [][]any
+	index  int
+}
+
+func (rows *fakeRows) Next() bool {
+	rows.index++
+	return rows.index <= len(rows.values)
+}
+
+func (rows *fakeRows) Scan(dest ...any) error {
+	for position, value := range rows.values[rows.index-1] {
+		target := reflect.ValueOf(dest[position]).Elem()
+		if value == nil {
+			target.SetZero()
+			continue
+		}
+		target.Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func (rows *fakeRows) Err() error { return nil }
+
+func TestPriceTrendHistoryQueryFollowsPrimarySeller(t *testing.T) {
+	for _, fragment := range []string{
+		"state.product_name_normalized = any($1::text[])",
+		"history.canonical_product_id = page.slug",
+		"history.seller = page.primary_seller",
+		"max(history.price_date) over (partition by page.slug)",
+		"price_date > latest_price_date - 365",
+	} {
+		if !strings.Contains(priceTrendHistoryQuery, fragment) {
+			t.Fatalf("expected %q in %s", fragment, priceTrendHistoryQuery)
+		}
+	}
+}
+
+func TestApplyPriceTrendsGroupsHistoryBySlug(t *testing.T) {
+	day := func(value int) time.Time { return time.Date(2026, 3, value, 0, 0, 0, 0, time.UTC) }
+	rising, quiet := "rising-game", "quiet-game"
+	rows := []Row{{ProductNameNormalized: &rising}, {ProductNameNormalized: &quiet}, {}}
+	history := &fakeRows{values: [][]any{
+		{rising, day(1), 100.0},
+		{rising, day(2), 102.0},
+		{rising, day(3), 104.0},
+		{rising, day(5), 108.0},
+		{rising, day(6), 110.0},
+	}}
+	if err := applyPriceTrends(rows, history); err != nil {
+		t.Fatal(err)
+	}
+	if rows[0].PriceTrend == nil || rows[0].PriceTrend.SampleDays != 5 {
+		t.Fatalf("unexpected trend: %#v", rows[0].PriceTrend)
+	}
+	if rows[0].PriceTrend.Direction != "rising" {
+		t.Fatalf("expected rising trend, got %q", rows[0].PriceTrend.Direction)
+	}
+	if rows[1].PriceTrend == nil || rows[1].PriceTrend.Direction != "unknown" || rows[1].PriceTrend.SampleDays != 0 {
+		t.Fatalf("expected unknown trend without history: %#v", rows[1].PriceTrend)
+	}
+	if rows[2].PriceTrend != nil {
+		t.Fatalf("expected rows without a slug to be skipped: %#v", rows[2].PriceTrend)
+	}
+}
+
+func TestCompareQueriesUseFixedSetBasedLookups(t *testing.T) {
+	expectations := map[string][]string{
+		compareProductsQuery: {
+			"unnest($1::text[]) with ordinality",
+			"public.canonical_product_slug(null, null, requested.slug)",
+			"left join public.catalog_slug_state state",
+		},
+		compareOffersQuery: {
+			"seller_state.product_name_normalized = any($1::text[])",
+			"public.seller_priority(seller_state.seller) asc",
+			"public.catalog_seller_stale_after(seller_state.seller)",
+		},
+		comparePriceStatsQuery: {
+			"history.canonical_product_id = any($1::text[])",
+			"partition by history.canonical_product_id, history.seller",
+			"price_date >= latest_price_date - 30 and price_date < latest_price_date",
+			"group by canonical_product_id, currency_code",
+		},
+	}
+	for query, fragments := range expectations {
+		for _, fragment := range fragments {
+			if !strings.Contains(query, fragment) {
+				t.Fatalf("expected query to contain %q", fragment)
+			}
+		}
+	}
+}
+
+func TestCollectComparedProductsKeepsOneColumnPerProduct(t *testing.T) {
+	canonical := "obrozeni-rebirth"
+	comparedRow := func(requested string, canonicalID *string) []any {
+		return []any{requested, canonicalID, nil, nil, nil, nil, nil, nil, nil, json.RawMessage(`[]`)}
+	}
+	rows := &fakeRows{values: [][]any{
+		comparedRow("obrozeni-rebirth", &canonical),
+		comparedRow("unknown", nil),
+		comparedRow("obrozeni", &canonical),
+	}}
+	comparison := Comparison{Products: []ComparedProduct{}}
+	canonicalIDs, err := collectComparedProducts(rows, &comparison)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comparison.Products) != 1 || comparison.Products[0].RequestedSlug != "obrozeni-rebirth" ||
+		len(canonicalIDs) != 1 {
+		t.Fatalf("expected the alias to fold into the first column: %#v", comparison.Products)
+	}
+	if len(comparison.MissingSlugs) != 1 || comparison.MissingSlugs[0] != "unknown" {
+		t.Fatalf("unexpected missing slugs: %#v", comparison.MissingSlugs)
+	}
+}
+
+func TestNormalizeParametersAlignsSellerShapes(t *testing.T) {
+	fromArray := normalizeParameters([]byte(`[
+		{"name":"1. Počet hráčů","value":"2-4"},
+		{"label":"Herní doba","val":["30", "60 min"]},
+		"Věk: 10+",
+		{"name":"Prázdné","value":""}
+	]`))
+	fromObject := normalizeParameters([]byte(`{"pocet-hracu":"1-5","Jazyk":{"text":"CZ"},"Rok":2024}`))
+
+	expectedArray := map[string]string{
+		"pocet_hracu": "2-4",
+		"herni_doba":  "30, 60 min",
+		"vek":         "10+",
+	}
+	if len(fromArray) != len(expectedArray) {
+		t.Fatalf("unexpected array parameters: %#v", fromArray)
+	}
+	for key, value := range expectedArray {
+		if fromArray[key] == nil || *fromArray[key] != value {
+			t.Fatalf("unexpected %s: %#v", key, fromArray[key])
+		}
+	}
+	if *fromObject["pocet_hracu"] != "1-5" || *fromObject["jazyk"] != "CZ" || *fromObject["rok"] != "2024" {
+		t.Fatalf("unexpected object parameters: %#v", fromObject)
+	}
+	if len(normalizeParameters([]byte(`"free text"`))) != 0 {
+		t.Fatal("expected unsupported parameter shape to be ignored")
+	}
+}
+
+func TestAssembleComparisonPrefersAvailableBestOfferAndAlignsKeys(t *testing.T) {
+	cheap, buyable, other, abandoned := 599.0, 649.0, 899.0, 499.0
+	players := "2-4"
+	comparison := Comparison{Products: []ComparedProduct{
+		{ProductNameNormalized: "alpha", Parameters: map[string]*string{"pocet_hracu": &players}},
+		{ProductNameNormalized: "beta", Parameters: map[string]*string{}},
+	}}
+	offers := map[string][]ComparedOffer{
+		"alpha": {
+			{Seller: "tlamagames", LatestPrice: &cheap, priority: 1},
+			{Seller: "planetaher", LatestPrice: &buyable, IsAvailable: true, priority: 3},
+			{Seller: "tlamagase", LatestPrice: &buyable, IsAvailable: true, priority: 2},
+			{Seller: "other", LatestPrice: &other, IsAvailable: true, priority: 100},
+			{Seller: "closedshop", LatestPrice: &abandoned, IsAvailable: true, Stale: true, priority: 100},
+		},
+	}
+	assembleComparison(&comparison, offers, nil)
+
+	alpha, beta := comparison.Products[0], comparison.Products[1]
+	if alpha.BestOffer == nil || alpha.BestOffer.Seller != "tlamagase" {
+		t.Fatalf("unexpected best offer: %#v", alpha.BestOffer)
+	}
+	if alpha.SellerCount != 5 || alpha.AvailableSellerCount != 3 {
+		t.Fatalf("unexpected seller coverage: %d/%d", alpha.AvailableSellerCount, alpha.SellerCount)
+	}
+	if beta.BestOffer != nil || len(beta.Sellers) != 0 || beta.PriceStats == nil {
+		t.Fatalf("unexpected empty product: %#v", beta)
+	}
+	value, exists := beta.Parameters["pocet_hracu"]
+	if len(comparison.AttributeKeys) != 1 || !exists || value != nil {
+		t.Fatalf("expected aligned null parameter: %#v %#v", comparison.AttributeKeys, beta.Parameters)
+	}
+}
+
+func TestSellerDirectoryQueryUsesRegistryMetadataAndDiscountRule(t *testing.T) {
+	for _, fragment := range []string{
+		"from public.catalog_slug_seller_state offer",
+		"left join public.catalog_sellers registry on registry.seller = offer.seller",
+		"coalesce(nullif(trim(registry.display_name), ''), offer.seller)",
+		"count(*) filter (where offer.is_available)",
+		"offer.latest_price < offer.previous_price",
+		"offer.latest_price < offer.list_price_with_vat",
+		"order by public.seller_priority(offer.seller) asc, offer.seller asc",
+	} {
+		if !strings.Contains(sellerDirectoryQuery, fragment) {
+			t.Fatalf("seller directory query missing %q", fragment)
+		}
+	}
+}
+
+func TestSellerFreshnessQueryUsesPerSellerThreshold(t *testing.T) {
+	for _, fragment := range []string{
+		"public.catalog_seller_stale_after(seller_ids.seller)",
+		"offer.latest_scraped_at < now() - threshold.stale_after",
+		"coalesce(max(offer.latest_scraped_at) < now() - threshold.stale_after, true) as stale",
+		"order by stale desc, offer.seller asc",
+	} {
+		if !strings.Contains(sellerFreshnessQuery, fragment) {
+			t.Fatalf("seller freshness query missing %q", fragment)
+		}
+	}
+}
+
+func TestBuildSlugRowsQueryReusesCatalogProjection(t *testing.T) {
+	query := buildSlugRowsQuery("public.catalog_slug_state")
+	if !strings.HasPrefix(query, catalogRowsSelect+"public.catalog_slug_state") {
+		t.Fatalf("slug rows must use the catalog projection:\n%s", query)
+	}
+	if !strings.Contains(query, "where product_name_normalized = any($1::text[])") {
+		t.Fatalf("unexpected slug filter:\n%s", query)
+	}
+}
@@ -0,0 +1,73 @@
+package catalog
+
+import (
+	"context"
+	"time"
+)
+
+// SellerSummary describes one tracked shop. Counts cover its rows in
+// catalog_slug_seller_state; display metadata comes from catalog_sellers and
+// falls back to the seller id for unregistered shops.
+type SellerSummary struct {
+	Seller                string     `json:"seller"`
+	DisplayName           string     `json:"display_name"`
+	Priority              int        `json:"priority"`
+	Registered            bool       `json:"registered"`
+	OfferCount            int64      `json:"offer_count"`
+	InStockCount          int64      `json:"in_stock_count"`
+	DiscountedCount       int64      `json:"discounted_count"`
+	NewestLatestScrapedAt *time.Time `json:"newest_latest_scraped_at"`
+	OldestLatestScrapedAt *time.Time `json:"oldest_latest_scraped_at"`
+}
+
+// sellerDirectoryQuery counts a discount with the same eligibility rule as the
+// recent discounts feed.
+const sellerDirectoryQuery = `
+select
+  offer.seller,
+  coalesce(nullif(trim(registry.display_name), ''), offer.seller),
+  public.seller_priority(offer.seller),
+  registry.seller is not null,
+  count(*),
+  count(*) filter (where offer.is_available),
+  count(*) filter (
+    where offer.latest_price is not null
+      and (
+        (offer.previous_price is not null and offer.latest_price < offer.previous_price)
+        or (offer.list_price_with_vat is not null and offer.latest_price < offer.list_price_with_vat)
+      )
+  ),
+  max(offer.latest_scraped_at),
+  min(offer.latest_scraped_at)
+from public.catalog_slug_seller_state offer
+left join public.catalog_sellers registry on registry.seller = offer.seller
+group by offer.seller, registry.seller, registry.display_name
+order by public.seller_priority(offer.seller) asc, offer.seller asc;`
+
+func (repository *Repository) Sellers(ctx context.Context) ([]SellerSummary, error) {
+	rows, err := repository.db.Query(ctx, sellerDirectoryQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sellers := make([]SellerSummary, 0, 16)
+	for rows.Next() {
+		var seller SellerSummary
+		if err := rows.Scan(
+			&seller.Seller,
+			&seller.DisplayName,
+			&seller.Priority,
+			&seller.Registered,
+			&seller.OfferCount,
+			&seller.InStockCount,
+			&seller.DiscountedCount,
+			&seller.NewestLatestScrapedAt,
+			&seller.OldestLatestScrapedAt,
+		); err != nil {
+			return nil, err
+		}
+		sellers = append(sellers, seller)
+	}
+	return sellers, rows.Err()
+}
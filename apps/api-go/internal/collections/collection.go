@@ -0,0 +1,273 @@
+package collections
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"math"
+	"time"
+
+	"tlamasite/apps/api-go/internal/catalog"
+)
+
+const (
+	MaxItems       = 100
+	MaxTitleLength = 120
+
+	publicIDBytes   = 12
+	editTokenBytes  = 32
+	defaultCurrency = "CZK"
+)
+
+var (
+	ErrCollectionNotFound = errors.New("collection not found")
+	ErrInvalidEditToken   = errors.New("edit token does not match this collection")
+	ErrProductNotFound    = errors.New("product not found")
+	ErrCollectionFull     = errors.New("collection already holds the maximum number of games")
+)
+
+type CreateRequest struct {
+	Title *string
+	Slugs []string
+}
+
+// Collection is the public view: stored slugs joined with live catalog rows.
+// Product is null for a slug that has left the catalog.
+type Collection struct {
+	ID            string    `json:"id"`
+	Title         *string   `json:"title"`
+	Items         []Item    `json:"items"`
+	Totals        []Total   `json:"totals"`
+	UnpricedItems int       `json:"unpriced_items"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Item carries the catalog row and the cheapest fresh offer, which is what
+// the totals add up. BestOffer is null when no seller has a fresh price.
+type Item struct {
+	Slug      string       `json:"slug"`
+	AddedAt   time.Time    `json:"added_at"`
+	Product   *catalog.Row `json:"product"`
+	BestOffer *BestOffer   `json:"best_offer"`
+}
+
+type BestOffer struct {
+	Seller       string  `json:"seller"`
+	Price        float64 `json:"price"`
+	CurrencyCode *string `json:"currency_code"`
+}
+
+// Total sums best offer prices per currency; prices are never converted.
+type Total struct {
+	CurrencyCode string  `json:"currency_code"`
+	Amount       float64 `json:"amount"`
+	Items        int     `json:"items"`
+}
+
+// Created is returned once, when the collection is made. The edit token is not
+// stored and cannot be shown again.
+type Created struct {
+	Collection
+	EditToken string `json:"edit_token"`
+}
+
+type storedCollection struct {
+	ID        int64
+	PublicID  string
+	Title     *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Items     []storedItem
+}
+
+type storedItem struct {
+	Slug    string
+	AddedAt time.Time
+}
+
+type collectionStore interface {
+	resolveSlugs(ctx context.Context, slugs []string) ([]string, error)
+	insertCollection(ctx context.Context, publicID string, digest [sha256.Size]byte, title *string, slugs []string) error
+	collection(ctx context.Context, publicID string) (storedCollection, error)
+	addItem(ctx context.Context, publicID string, digest [sha256.Size]byte, slug string, limit int) error
+	removeItem(ctx context.Context, publicID string, digest [sha256.Size]byte, slug string) error
+	deleteCollection(ctx context.Context, publicID string, digest [sha256.Size]byte) error
+	bestOffers(ctx context.Context, slugs []string) (map[string]BestOffer, error)
+}
+
+type catalogRows interface {
+	FetchBySlugs(ctx context.Context, slugs []string) ([]catalog.Row, error)
+}
+
+type Service struct {
+	store   collectionStore
+	catalog catalogRows
+}
+
+func NewService(repository *Repository, rows catalogRows) *Service {
+	return &Service{store: repository, catalog: rows}
+}
+
+// Create stores a collection with optional initial slugs. Unknown slugs fail
+// the whole request so a shared list never silently loses a game.
+func (s *Service) Create(ctx context.Context, request CreateRequest) (Created, error) {
+	slugs, err := s.resolve(ctx, request.Slugs)
+	if err != nil {
+		return Created{}, err
+	}
+	if len(slugs) > MaxItems {
+		return Created{}, ErrCollectionFull
+	}
+	publicID, err := randomToken(publicIDBytes)
+	if err != nil {
+		return Created{}, err
+	}
+	editToken, err := randomToken(editTokenBytes)
+	if err != nil {
+		return Created{}, err
+	}
+	if err := s.store.insertCollection(ctx, publicID, digest(editToken), request.Title, slugs); err != nil {
+		return Created{}, err
+	}
+	collection, err := s.Get(ctx, publicID)
+	if err != nil {
+		return Created{}, err
+	}
+	return Created{Collection: collection, EditToken: editToken}, nil
+}
+
+func (s *Service) Get(ctx context.Context, publicID string) (Collection, error) {
+	stored, err := s.store.collection(ctx, publicID)
+	if err != nil {
+		return Collection{}, err
+	}
+	slugs := make([]string, 0, len(stored.Items))
+	for _, item := range stored.Items {
+		slugs = append(slugs, item.Slug)
+	}
+	rows, err := s.catalog.FetchBySlugs(ctx, slugs)
+	if err != nil {
+		return Collection{}, err
+	}
+	offers, err := s.store.bestOffers(ctx, slugs)
+	if err != nil {
+		return Collection{}, err
+	}
+	return buildCollection(stored, rows, offers), nil
+}
+
+func (s *Service) AddItem(ctx context.Context, publicID string, editToken string, slug string) (Collection, error) {
+	slugs, err := s.resolve(ctx, []string{slug})
+	if err != nil {
+		return Collection{}, err
+	}
+	if err := s.store.addItem(ctx, publicID, digest(editToken), slugs[0], MaxItems); err != nil {
+		return Collection{}, err
+	}
+	return s.Get(ctx, publicID)
+}
+
+// RemoveItem accepts the slug as shown in the collection or any alias of it.
+// Removing a game that is not in the collection is not an error.
+func (s *Service) RemoveItem(ctx context.Context, publicID string, editToken string, slug string) (Collection, error) {
+	if err := s.store.removeItem(ctx, publicID, digest(editToken), slug); err != nil {
+		return Collection{}, err
+	}
+	return s.Get(ctx, publicID)
+}
+
+func (s *Service) Delete(ctx context.Context, publicID string, editToken string) error {
+	return s.store.deleteCollection(ctx, publicID, digest(editToken))
+}
+
+// resolve maps requested slugs to canonical catalog slugs, dropping repeats.
+func (s *Service) resolve(ctx context.Context, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return []string{}, nil
+	}
+	resolved, err := s.store.resolveSlugs(ctx, requested)
+	if err != nil {
+		return nil, err
+	}
+	slugs := make([]string, 0, len(resolved))
+	seen := make(map[string]struct{}, len(resolved))
+	for _, slug := range resolved {
+		if slug == "" {
+			return nil, ErrProductNotFound
+		}
+		if _, exists := seen[slug]; exists {
+			continue
+		}
+		seen[slug] = struct{}{}
+		slugs = append(slugs, slug)
+	}
+	return slugs, nil
+}
+
+// buildCollection joins stored items with catalog rows and best offers. Two
+// items can resolve to one product after an alias is approved; the earlier one
+// is kept.
+func buildCollection(stored storedCollection, rows []catalog.Row, offers map[string]BestOffer) Collection {
+	bySlug := make(map[string]*catalog.Row, len(rows))
+	for index := range rows {
+		if rows[index].ProductNameNormalized != nil {
+			bySlug[*rows[index].ProductNameNormalized] = &rows[index]
+		}
+	}
+	collection := Collection{
+		ID:        stored.PublicID,
+		Title:     stored.Title,
+		Items:     make([]Item, 0, len(stored.Items)),
+		Totals:    []Total{},
+		CreatedAt: stored.CreatedAt,
+		UpdatedAt: stored.UpdatedAt,
+	}
+	seen := make(map[string]struct{}, len(stored.Items))
+	totals := make(map[string]int, 1)
+	for _, entry := range stored.Items {
+		if _, exists := seen[entry.Slug]; exists {
+			continue
+		}
+		seen[entry.Slug] = struct{}{}
+		item := Item{Slug: entry.Slug, AddedAt: entry.AddedAt, Product: bySlug[entry.Slug]}
+		if offer, exists := offers[entry.Slug]; exists {
+			item.BestOffer = &offer
+		}
+		collection.Items = append(collection.Items, item)
+		if item.BestOffer == nil {
+			collection.UnpricedItems++
+			continue
+		}
+		currency := defaultCurrency
+		if item.BestOffer.CurrencyCode != nil {
+			currency = *item.BestOffer.CurrencyCode
+		}
+		index, exists := totals[currency]
+		if !exists {
+			index = len(collection.Totals)
+			totals[currency] = index
+			collection.Totals = append(collection.Totals, Total{CurrencyCode: currency})
+		}
+		collection.Totals[index].Amount += item.BestOffer.Price
+		collection.Totals[index].Items++
+	}
+	for index := range collection.Totals {
+		collection.Totals[index].Amount = math.Round(collection.Totals[index].Amount*100) / 100
+	}
+	return collection
+}
+
+func randomToken(size int) (string, error) {
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func digest(editToken string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(editToken))
+}
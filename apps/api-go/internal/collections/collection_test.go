@@ -0,0 +1,286 @@
+package collections
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"tlamasite/apps/api-go/internal/catalog"
+)
+
+// fakeStore keeps one collection in memory and resolves "alias-*" slugs to
+// the slug after the prefix.
+type fakeStore struct {
+	catalog  map[string]struct{}
+	offers   map[string]BestOffer
+	publicID string
+	digest   [sha256.Size]byte
+	title    *string
+	items    []storedItem
+}
+
+func (store *fakeStore) resolveSlugs(_ context.Context, slugs []string) ([]string, error) {
+	resolved := make([]string, 0, len(slugs))
+	for _, slug := range slugs {
+		slug = strings.TrimPrefix(slug, "alias-")
+		if _, exists := store.catalog[slug]; !exists {
+			slug = ""
+		}
+		resolved = append(resolved, slug)
+	}
+	return resolved, nil
+}
+
+func (store *fakeStore) insertCollection(
+	_ context.Context,
+	publicID string,
+	digest [sha256.Size]byte,
+	title *string,
+	slugs []string,
+) error {
+	store.publicID, store.digest, store.title = publicID, digest, title
+	for _, slug := range slugs {
+		store.items = append(store.items, storedItem{Slug: slug})
+	}
+	return nil
+}
+
+func (store *fakeStore) collection(_ context.Context, publicID string) (storedCollection, error) {
+	if publicID != store.publicID {
+		return storedCollection{}, ErrCollectionNotFound
+	}
+	return storedCollection{PublicID: publicID, Title: store.title, Items: store.items}, nil
+}
+
+func (store *fakeStore) authorize(publicID string, digest [sha256.Size]byte) error {
+	if publicID != store.publicID {
+		return ErrCollectionNotFound
+	}
+	if digest != store.digest {
+		return ErrInvalidEditToken
+	}
+	return nil
+}
+
+func (store *fakeStore) addItem(_ context.Context, publicID string, digest [sha256.Size]byte, slug string, limit int) error {
+	if err := store.authorize(publicID, digest); err != nil {
+		return err
+	}
+	for _, item := range store.items {
+		if item.Slug == slug {
+			return nil
+		}
+	}
+	if len(store.items) >= limit {
+		return ErrCollectionFull
+	}
+	store.items = append(store.items, storedItem{Slug: slug})
+	return nil
+}
+
+func (store *fakeStore) removeItem(_ context.Context, publicID string, digest [sha256.Size]byte, slug string) error {
+	if err := store.authorize(publicID, digest); err != nil {
+		return err
+	}
+	slug = strings.TrimPrefix(slug, "alias-")
+	kept := store.items[:0]
+	for _, item := range store.items {
+		if item.Slug != slug {
+			kept = append(kept, item)
+		}
+	}
+	store.items = kept
+	return nil
+}
+
+func (store *fakeStore) deleteCollection(_ context.Context, publicID string, digest [sha256.Size]byte) error {
+	if err := store.authorize(publicID, digest); err != nil {
+		return err
+	}
+	store.publicID = ""
+	return nil
+}
+
+func (store *fakeStore) bestOffers(_ context.Context, slugs []string) (map[string]BestOffer, error) {
+	offers := make(map[string]BestOffer, len(slugs))
+	for _, slug := range slugs {
+		if offer, exists := store.offers[slug]; exists {
+			offers[slug] = offer
+		}
+	}
+	return offers, nil
+}
+
+type fakeCatalog struct {
+	rows []catalog.Row
+}
+
+func (fake fakeCatalog) FetchBySlugs(_ context.Context, slugs []string) ([]catalog.Row, error) {
+	rows := make([]catalog.Row, 0, len(slugs))
+	for _, row := range fake.rows {
+		for _, slug := range slugs {
+			if *row.ProductNameNormalized == slug {
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows, nil
+}
+
+func testRow(slug string, price *float64, currency string) catalog.Row {
+	return catalog.Row{ProductNameNormalized: &slug, LatestPrice: price, CurrencyCode: &currency}
+}
+
+func price(value float64) *float64 {
+	return &value
+}
+
+func offer(seller string, price float64, currency string) BestOffer {
+	return BestOffer{Seller: seller, Price: price, CurrencyCode: &currency}
+}
+
+func newTestService() (*Service, *fakeStore) {
+	store := &fakeStore{
+		catalog: map[string]struct{}{"azul": {}, "carcassonne": {}, "catan": {}},
+		offers: map[string]BestOffer{
+			"azul":        offer("tlamagames", 699.9, "CZK"),
+			"carcassonne": offer("planetaher", 549.2, "CZK"),
+		},
+	}
+	service := &Service{store: store, catalog: fakeCatalog{rows: []catalog.Row{
+		testRow("azul", price(699.9), "CZK"),
+		testRow("carcassonne", price(549.2), "CZK"),
+		testRow("catan", nil, "CZK"),
+	}}}
+	return service, store
+}
+
+func TestCreateReturnsTokenAndLiveTotals(t *testing.T) {
+	service, store := newTestService()
+	title := "Narozeniny"
+	created, err := service.Create(context.Background(), CreateRequest{
+		Title: &title,
+		Slugs: []string{"azul", "alias-azul", "carcassonne", "catan"},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(created.ID) != 16 || len(created.EditToken) != 43 {
+		t.Fatalf("unexpected identifiers %q / %q", created.ID, created.EditToken)
+	}
+	if store.digest != sha256.Sum256([]byte(created.EditToken)) {
+		t.Fatal("only the token digest may be stored")
+	}
+	if len(created.Items) != 3 || created.Items[0].Product == nil {
+		t.Fatalf("expected three deduplicated items, got %#v", created.Items)
+	}
+	if len(created.Totals) != 1 || created.Totals[0].Amount != 1249.1 || created.Totals[0].Items != 2 ||
+		created.UnpricedItems != 1 {
+		t.Fatalf("unexpected totals %#v (%d unpriced)", created.Totals, created.UnpricedItems)
+	}
+
+	if _, err := service.Create(context.Background(), CreateRequest{Slugs: []string{"azul", "unknown"}}); !errors.Is(err, ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestEditsRequireTheEditToken(t *testing.T) {
+	service, _ := newTestService()
+	created, err := service.Create(context.Background(), CreateRequest{})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := service.AddItem(ctx, created.ID, "forged", "azul"); !errors.Is(err, ErrInvalidEditToken) {
+		t.Fatalf("expected ErrInvalidEditToken, got %v", err)
+	}
+	if _, err := service.AddItem(ctx, "missing-collecti", created.EditToken, "azul"); !errors.Is(err, ErrCollectionNotFound) {
+		t.Fatalf("expected ErrCollectionNotFound, got %v", err)
+	}
+	collection, err := service.AddItem(ctx, created.ID, created.EditToken, "alias-azul")
+	if err != nil || len(collection.Items) != 1 || collection.Items[0].Slug != "azul" {
+		t.Fatalf("expected canonical item, got %#v (%v)", collection.Items, err)
+	}
+	collection, err = service.RemoveItem(ctx, created.ID, created.EditToken, "alias-azul")
+	if err != nil || len(collection.Items) != 0 || collection.UnpricedItems != 0 {
+		t.Fatalf("expected empty collection, got %#v (%v)", collection, err)
+	}
+	if err := service.Delete(ctx, created.ID, created.EditToken); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := service.Get(ctx, created.ID); !errors.Is(err, ErrCollectionNotFound) {
+		t.Fatalf("expected deleted collection to be gone, got %v", err)
+	}
+}
+
+func TestBuildCollectionKeepsMissingProductsAndSplitsCurrencies(t *testing.T) {
+	addedAt := time.Date(2026, 3, 9, 18, 0, 0, 0, time.UTC)
+	collection := buildCollection(
+		storedCollection{PublicID: "abcdefghijklmnop", Items: []storedItem{
+			{Slug: "azul", AddedAt: addedAt},
+			{Slug: "retired", AddedAt: addedAt},
+			{Slug: "azul", AddedAt: addedAt.Add(time.Hour)},
+			{Slug: "dixit", AddedAt: addedAt},
+		}},
+		[]catalog.Row{testRow("azul", price(699), "CZK"), testRow("dixit", price(29.99), "EUR")},
+		map[string]BestOffer{"azul": offer("tlamagames", 699, "CZK"), "dixit": offer("planetaher", 29.99, "EUR")},
+	)
+	if len(collection.Items) != 3 || collection.Items[1].Product != nil || collection.UnpricedItems != 1 {
+		t.Fatalf("unexpected items %#v", collection.Items)
+	}
+	if len(collection.Totals) != 2 || collection.Totals[0].CurrencyCode != "CZK" || collection.Totals[1].Amount != 29.99 {
+		t.Fatalf("unexpected totals %#v", collection.Totals)
+	}
+}
+
+func TestBuildCollectionSumsTheCheapestOfferNotThePrioritySeller(t *testing.T) {
+	// The catalog row shows the priority seller's 899; another shop sells it
+	// for 749.
+	collection := buildCollection(
+		storedCollection{Items: []storedItem{{Slug: "azul"}, {Slug: "catan"}}},
+		[]catalog.Row{testRow("azul", price(899), "CZK"), testRow("catan", price(999), "CZK")},
+		map[string]BestOffer{"azul": offer("planetaher", 749, "CZK")},
+	)
+	azul := collection.Items[0]
+	if azul.BestOffer == nil || azul.BestOffer.Seller != "planetaher" || azul.BestOffer.Price != 749 {
+		t.Fatalf("unexpected best offer %#v", azul.BestOffer)
+	}
+	if len(collection.Totals) != 1 || collection.Totals[0].Amount != 749 || collection.Totals[0].Items != 1 {
+		t.Fatalf("expected only the cheapest fresh offer to be summed, got %#v", collection.Totals)
+	}
+	if collection.Items[1].BestOffer != nil || collection.UnpricedItems != 1 {
+		t.Fatalf("a product without a fresh offer must count as unpriced: %#v", collection.Items[1])
+	}
+}
+
+func TestBestOffersQueryPicksCheapestFreshOffer(t *testing.T) {
+	for _, fragment := range []string{
+		"distinct on (offer.product_name_normalized)",
+		"offer.latest_scraped_at >= now() - public.catalog_seller_stale_after(offer.seller)",
+		"offer.latest_price asc",
+	} {
+		if !strings.Contains(bestOffersQuery, fragment) {
+			t.Fatalf("best offers query missing %q", fragment)
+		}
+	}
+}
+
+func TestEditQueriesLockAndMatchAliases(t *testing.T) {
+	if !strings.Contains(lockCollectionQuery, "for update") {
+		t.Fatal("edits must lock the collection row")
+	}
+	if !strings.Contains(removeItemQuery, "public.canonical_product_slug(null, null, $2)") {
+		t.Fatal("removal must match through canonical slugs")
+	}
+	if !strings.Contains(resolveSlugsQuery, "with ordinality") || !strings.Contains(resolveSlugsQuery, "order by input.position") {
+		t.Fatal("slug resolution must keep the request order")
+	}
+	if !strings.Contains(insertItemsQuery, "with ordinality") ||
+		!strings.Contains(insertItemsQuery, "(input.position - 1) * interval '1 microsecond'") {
+		t.Fatal("initial items must be stored in the request order")
+	}
+}
@@ -0,0 +1,305 @@
+package collections
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository runs as the API role, which may write the two collection tables.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// resolveSlugsQuery keeps the request order; unknown slugs come back null.
+const resolveSlugsQuery = `
+select coalesce(state.product_name_normalized, '')
+from unnest($1::text[]) with ordinality as input(slug, position)
+left join public.catalog_slug_state state
+  on state.product_name_normalized = public.canonical_product_slug(null, null, input.slug)
+order by input.position;`
+
+const insertCollectionQuery = `
+insert into public.catalog_collections (public_id, edit_token_sha256, title)
+values ($1, $2, $3)
+returning id;`
+
+// insertItemsQuery steps added_at by a microsecond per position so initial
+// games list in the submitted order rather than alphabetically.
+const insertItemsQuery = `
+insert into public.catalog_collection_items (
+  collection_id, product_name_normalized, added_at
+)
+select $1, input.slug, now() + (input.position - 1) * interval '1 microsecond'
+from unnest($2::text[]) with ordinality as input(slug, position)
+on conflict do nothing;`
+
+const collectionQuery = `
+select id, public_id, title, created_at, updated_at
+from public.catalog_collections
+where public_id = $1;`
+
+// collectionItemsQuery resolves stored slugs again so items follow aliases
+// approved after they were added.
+const collectionItemsQuery = `
+select
+  public.canonical_product_slug(null, null, item.product_name_normalized),
+  item.added_at
+from public.catalog_collection_items item
+where item.collection_id = $1
+order by item.added_at asc, item.product_name_normalized asc;`
+
+const lockCollectionQuery = `
+select id, edit_token_sha256
+from public.catalog_collections
+where public_id = $1
+for update;`
+
+const countItemsQuery = `
+select count(*)
+from public.catalog_collection_items
+where collection_id = $1;`
+
+const insertItemQuery = `
+insert into public.catalog_collection_items (collection_id, product_name_normalized)
+values ($1, $2)
+on conflict do nothing;`
+
+// removeItemQuery matches through canonical slugs, so a game can be removed
+// by any of its aliases.
+const removeItemQuery = `
+delete from public.catalog_collection_items
+where collection_id = $1
+  and public.canonical_product_slug(null, null, product_name_normalized)
+    = public.canonical_product_slug(null, null, $2);`
+
+const touchCollectionQuery = `
+update public.catalog_collections
+set updated_at = now()
+where id = $1;`
+
+// bestOffersQuery picks each product's cheapest offer that is not past its
+// seller's freshness threshold, the price a buyer could pay today.
+const bestOffersQuery = `
+select distinct on (offer.product_name_normalized)
+  offer.product_name_normalized,
+  offer.seller,
+  offer.latest_price::double precision,
+  offer.currency_code
+from public.catalog_slug_seller_state offer
+where offer.product_name_normalized = any($1::text[])
+  and offer.latest_price is not null
+  and offer.latest_scraped_at >= now() - public.catalog_seller_stale_after(offer.seller)
+order by
+  offer.product_name_normalized,
+  offer.latest_price asc,
+  public.seller_priority(offer.seller) asc,
+  offer.seller asc;`
+
+const deleteCollectionQuery = `delete from public.catalog_collections where id = $1;`
+
+func (repository *Repository) resolveSlugs(ctx context.Context, slugs []string) ([]string, error) {
+	rows, err := repository.db.Query(ctx, resolveSlugsQuery, slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resolved := make([]string, 0, len(slugs))
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, slug)
+	}
+	return resolved, rows.Err()
+}
+
+func (repository *Repository) insertCollection(
+	ctx context.Context,
+	publicID string,
+	digest [sha256.Size]byte,
+	title *string,
+	slugs []string,
+) error {
+	tx, err := repository.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var id int64
+	if err := tx.QueryRow(ctx, insertCollectionQuery, publicID, digest[:], title).Scan(&id); err != nil {
+		return err
+	}
+	if len(slugs) > 0 {
+		if _, err := tx.Exec(ctx, insertItemsQuery, id, slugs); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (repository *Repository) collection(ctx context.Context, publicID string) (storedCollection, error) {
+	var stored storedCollection
+	err := repository.db.QueryRow(ctx, collectionQuery, publicID).Scan(
+		&stored.ID,
+		&stored.PublicID,
+		&stored.Title,
+		&stored.CreatedAt,
+		&stored.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storedCollection{}, ErrCollectionNotFound
+	}
+	if err != nil {
+		return storedCollection{}, err
+	}
+	rows, err := repository.db.Query(ctx, collectionItemsQuery, stored.ID)
+	if err != nil {
+		return storedCollection{}, err
+	}
+	defer rows.Close()
+	stored.Items = make([]storedItem, 0, 16)
+	for rows.Next() {
+		var item storedItem
+		if err := rows.Scan(&item.Slug, &item.AddedAt); err != nil {
+			return storedCollection{}, err
+		}
+		stored.Items = append(stored.Items, item)
+	}
+	return stored, rows.Err()
+}
+
+func (repository *Repository) addItem(
+	ctx context.Context,
+	publicID string,
+	digest [sha256.Size]byte,
+	slug string,
+	limit int,
+) error {
+	return repository.edit(ctx, publicID, digest, func(tx pgx.Tx, id int64) error {
+		var count int
+		if err := tx.QueryRow(ctx, countItemsQuery, id).Scan(&count); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, insertItemQuery, id, slug)
+		if err != nil {
+			return err
+		}
+		// Checked after the insert so re-adding a game to a full collection
+		// stays a no-op rather than an error.
+		if tag.RowsAffected() > 0 && count >= limit {
+			return ErrCollectionFull
+		}
+		return nil
+	})
+}
+
+func (repository *Repository) removeItem(
+	ctx context.Context,
+	publicID string,
+	digest [sha256.Size]byte,
+	slug string,
+) error {
+	return repository.edit(ctx, publicID, digest, func(tx pgx.Tx, id int64) error {
+		_, err := tx.Exec(ctx, removeItemQuery, id, slug)
+		return err
+	})
+}
+
+func (repository *Repository) deleteCollection(
+	ctx context.Context,
+	publicID string,
+	digest [sha256.Size]byte,
+) error {
+	tx, err := repository.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := authorize(ctx, tx, publicID, digest)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, deleteCollectionQuery, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// edit runs one change under the collection row lock and bumps updated_at.
+// Returning an error rolls the change back.
+func (repository *Repository) edit(
+	ctx context.Context,
+	publicID string,
+	digest [sha256.Size]byte,
+	change func(pgx.Tx, int64) error,
+) error {
+	tx, err := repository.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := authorize(ctx, tx, publicID, digest)
+	if err != nil {
+		return err
+	}
+	if err := change(tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, touchCollectionQuery, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// authorize locks the collection and checks the edit token digest in constant
+// time.
+func authorize(ctx context.Context, tx pgx.Tx, publicID string, digest [sha256.Size]byte) (int64, error) {
+	var id int64
+	var stored []byte
+	err := tx.QueryRow(ctx, lockCollectionQuery, publicID).Scan(&id, &stored)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrCollectionNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if subtle.ConstantTimeCompare(stored, digest[:]) != 1 {
+		return 0, ErrInvalidEditToken
+	}
+	return id, nil
+}
+
+func (repository *Repository) bestOffers(ctx context.Context, slugs []string) (map[string]BestOffer, error) {
+	offers := make(map[string]BestOffer, len(slugs))
+	if len(slugs) == 0 {
+		return offers, nil
+	}
+	rows, err := repository.db.Query(ctx, bestOffersQuery, slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var slug string
+		var offer BestOffer
+		if err := rows.Scan(&slug, &offer.Seller, &offer.Price, &offer.CurrencyCode); err != nil {
+			return nil, err
+		}
+		offers[slug] = offer
+	}
+	return offers, rows.Err()
+}
@@ -1,19 +1,30 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/netip"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"tlamasite/apps/api-go/internal/admin"
 )
 
+const defaultSiteURL = "https://www.deskovkylevne.com"
+
+var adminTokenNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
 type Config struct {
 	ServerAddress          string
 	DatabaseURL            string
 	FrontendOrigin         string
+	SiteURL                string
 	CatalogSummaryRelation string
 	ReadTimeout            time.Duration
 	ReadHeaderTimeout      time.Duration
@@ -33,14 +44,41 @@ type Config struct {
 	DBMaxConnLifetime time.Duration
 	DBSimpleProtocol  bool
 
-	HealthTimeout     time.Duration
-	ReadyTimeout      time.Duration
-	CatalogTimeout    time.Duration
-	SearchTimeout     time.Duration
-	ProductTimeout    time.Duration
-	DiscountsTimeout  time.Duration
-	MetadataTimeout   time.Duration
-	PriceRangeTimeout time.Duration
+	HealthTimeout      time.Duration
+	ReadyTimeout       time.Duration
+	CatalogTimeout     time.Duration
+	SearchTimeout      time.Duration
+	ProductTimeout     time.Duration
+	DiscountsTimeout   time.Duration
+	MetadataTimeout    time.Duration
+	PriceRangeTimeout  time.Duration
+	AdminTimeout       time.Duration
+	WatchesTimeout     time.Duration
+	CollectionsTimeout time.Duration
+	ImportsTimeout     time.Duration
+	AliasReviewTimeout time.Duration
+
+	AdminTokenHashes  [][sha256.Size]byte
+	AdminTokens       []admin.Token
+	AdminAddress      string
+	AdminDatabaseRole string
+	AdminDBMaxConns   int32
+
+	SellerStatsInterval time.Duration
+	SellerStatsTimeout  time.Duration
+
+	WatchTokenSecret       string
+	WatchPollInterval      time.Duration
+	WatchEvaluationTimeout time.Duration
+	SMTPAddr               string
+	SMTPUsername           string
+	SMTPPassword           string
+	SMTPFrom               string
+	SMTPRequireTLS         bool
+
+	WebhookPollInterval time.Duration
+	WebhookTimeout      time.Duration
+	WebhookMaxAttempts  int
 
 	CacheNamespace     string
 	CacheTTLCatalog    time.Duration
@@ -48,6 +86,7 @@ type Config struct {
 	CacheTTLProduct    time.Duration
 	CacheTTLDiscounts  time.Duration
 	CacheTTLPriceRange time.Duration
+	CacheTTLSellers    time.Duration
 }
 
 func Load() (Config, error) {
@@ -56,17 +95,32 @@ func Load() (Config, error) {
 	applyDatabaseConfig(&cfg)
 	applyRouteTimeouts(&cfg)
 	applyCacheConfig(&cfg)
+	applyAdminConfig(&cfg)
+	applyJobConfig(&cfg)
+	applyWatchConfig(&cfg)
+	applyWebhookConfig(&cfg)
 	trustedProxyCIDRs, err := readCIDRs("API_TRUSTED_PROXY_CIDRS")
 	if err != nil {
 		return Config{}, err
 	}
 	cfg.TrustedProxyCIDRs = trustedProxyCIDRs
+	adminTokenHashes, err := readSHA256List("API_ADMIN_TOKEN_SHA256")
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.AdminTokenHashes = adminTokenHashes
+	adminTokens, err := readAdminTokens("API_ADMIN_TOKENS")
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.AdminTokens = adminTokens
 	return normalizeConfig(cfg)
 }
 
 func applyServerConfig(cfg *Config) {
 	cfg.ServerAddress = getenv("API_ADDRESS", ":8080")
 	cfg.FrontendOrigin = getenv("FRONTEND_ORIGIN", "http://localhost:5173")
+	cfg.SiteURL = strings.TrimRight(getenv("API_SITE_URL", defaultSiteURL), "/")
 	cfg.CatalogSummaryRelation = getenv(
 		"API_CATALOG_SUMMARY_RELATION", "public.catalog_slug_state",
 	)
@@ -97,6 +151,43 @@ func applyRouteTimeouts(cfg *Config) {
 	cfg.DiscountsTimeout = readDuration("API_TIMEOUT_DISCOUNTS", 4*time.Second)
 	cfg.MetadataTimeout = readDuration("API_TIMEOUT_METADATA", 4*time.Second)
 	cfg.PriceRangeTimeout = readDuration("API_TIMEOUT_PRICE_RANGE", 4*time.Second)
+	cfg.AdminTimeout = readDuration("API_TIMEOUT_ADMIN", 10*time.Second)
+	cfg.WatchesTimeout = readDuration("API_TIMEOUT_WATCHES", 15*time.Second)
+	cfg.CollectionsTimeout = readDuration("API_TIMEOUT_COLLECTIONS", 5*time.Second)
+	cfg.ImportsTimeout = readDuration("API_TIMEOUT_IMPORTS", 10*time.Second)
+	cfg.AliasReviewTimeout = readDuration("API_TIMEOUT_ALIAS_REVIEW", 2*time.Minute)
+}
+
+func applyAdminConfig(cfg *Config) {
+	cfg.AdminAddress = strings.TrimSpace(os.Getenv("API_ADMIN_ADDRESS"))
+	cfg.AdminDatabaseRole = getenv("API_ADMIN_DATABASE_ROLE", "tlamasite_maintenance")
+	cfg.AdminDBMaxConns = readInt32("API_ADMIN_DB_MAX_CONNS", 2)
+}
+
+func applyJobConfig(cfg *Config) {
+	cfg.SellerStatsInterval = readDuration("API_SELLER_STATS_INTERVAL", time.Hour)
+	cfg.SellerStatsTimeout = readDuration("API_SELLER_STATS_TIMEOUT", 2*time.Minute)
+}
+
+// applyWatchConfig reads price alert settings. Alerts stay disabled until a
+// token secret is set; the secret signs every confirm and unsubscribe link.
+func applyWatchConfig(cfg *Config) {
+	cfg.WatchTokenSecret = strings.TrimSpace(os.Getenv("API_WATCH_TOKEN_SECRET"))
+	cfg.WatchPollInterval = readDuration("API_WATCH_POLL_INTERVAL", time.Minute)
+	cfg.WatchEvaluationTimeout = readDuration("API_WATCH_EVALUATION_TIMEOUT", 5*time.Minute)
+	cfg.SMTPAddr = strings.TrimSpace(os.Getenv("API_SMTP_ADDR"))
+	cfg.SMTPUsername = strings.TrimSpace(os.Getenv("API_SMTP_USERNAME"))
+	cfg.SMTPPassword = os.Getenv("API_SMTP_PASSWORD")
+	cfg.SMTPFrom = strings.TrimSpace(os.Getenv("API_SMTP_FROM"))
+	cfg.SMTPRequireTLS = readBool("API_SMTP_REQUIRE_TLS", true)
+}
+
+// applyWebhookConfig reads dispatcher settings. The dispatcher shares the
+// admin pool, so it only runs when admin tokens are configured.
+func applyWebhookConfig(cfg *Config) {
+	cfg.WebhookPollInterval = readDuration("API_WEBHOOK_POLL_INTERVAL", 10*time.Second)
+	cfg.WebhookTimeout = readDuration("API_WEBHOOK_TIMEOUT", 10*time.Second)
+	cfg.WebhookMaxAttempts = readInt("API_WEBHOOK_MAX_ATTEMPTS", 8)
 }
 
 func applyCacheConfig(cfg *Config) {
@@ -109,6 +200,7 @@ func applyCacheConfig(cfg *Config) {
 	cfg.CacheTTLProduct = readDuration("API_CACHE_TTL_PRODUCT", 300*time.Second)
 	cfg.CacheTTLDiscounts = readDuration("API_CACHE_TTL_DISCOUNTS", 60*time.Second)
 	cfg.CacheTTLPriceRange = readDuration("API_CACHE_TTL_PRICE_RANGE", 180*time.Second)
+	cfg.CacheTTLSellers = readDuration("API_CACHE_TTL_SELLERS", 300*time.Second)
 }
 
 func normalizeConfig(cfg Config) (Config, error) {
@@ -130,9 +222,50 @@ func normalizeConfig(cfg Config) (Config, error) {
 	if cfg.DBMinConns > cfg.DBMaxConns {
 		cfg.DBMinConns = cfg.DBMaxConns
 	}
+	if cfg.AdminDBMaxConns < 1 {
+		cfg.AdminDBMaxConns = 1
+	}
+	// The stats aggregate scans a year of daily history; refreshing more often
+	// than once a minute only adds database load.
+	if cfg.SellerStatsInterval < time.Minute {
+		cfg.SellerStatsInterval = time.Minute
+	}
+	if cfg.SellerStatsTimeout <= 0 {
+		cfg.SellerStatsTimeout = 2 * time.Minute
+	}
+	if cfg.WatchTokenSecret != "" {
+		if len(cfg.WatchTokenSecret) < 32 {
+			return Config{}, errors.New("API_WATCH_TOKEN_SECRET must be at least 32 characters")
+		}
+		if cfg.SMTPAddr == "" || cfg.SMTPFrom == "" {
+			return Config{}, errors.New("API_SMTP_ADDR and API_SMTP_FROM are required for price alerts")
+		}
+	}
+	// Polling only reads max(updated_at) through an index.
+	if cfg.WatchPollInterval < 10*time.Second {
+		cfg.WatchPollInterval = 10 * time.Second
+	}
+	if cfg.WatchEvaluationTimeout <= 0 {
+		cfg.WatchEvaluationTimeout = 5 * time.Minute
+	}
+	if cfg.WebhookPollInterval < time.Second {
+		cfg.WebhookPollInterval = time.Second
+	}
+	if cfg.WebhookTimeout <= 0 || cfg.WebhookTimeout > time.Minute {
+		cfg.WebhookTimeout = 10 * time.Second
+	}
+	if cfg.WebhookMaxAttempts < 1 {
+		cfg.WebhookMaxAttempts = 1
+	}
+	if cfg.WebhookMaxAttempts > 20 {
+		cfg.WebhookMaxAttempts = 20
+	}
 	if cfg.CacheNamespace == "" {
 		cfg.CacheNamespace = "api-v2"
 	}
+	if cfg.SiteURL == "" {
+		cfg.SiteURL = defaultSiteURL
+	}
 	if cfg.CatalogSummaryRelation == "" {
 		cfg.CatalogSummaryRelation = "public.catalog_slug_state"
 	}
@@ -155,6 +288,65 @@ func readCIDRs(key string) ([]netip.Prefix, error) {
 	return prefixes, nil
 }
 
+// readSHA256List parses comma-separated hex SHA-256 digests. Invalid entries
+// fail startup so a typo cannot silently lock operators out.
+func readSHA256List(key string) ([][sha256.Size]byte, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return nil, nil
+	}
+	digests := make([][sha256.Size]byte, 0, 2)
+	for _, candidate := range strings.Split(raw, ",") {
+		decoded, err := hex.DecodeString(strings.TrimSpace(candidate))
+		if err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("%s contains invalid SHA-256 digest", key)
+		}
+		digests = append(digests, [sha256.Size]byte(decoded))
+	}
+	return digests, nil
+}
+
+// readAdminTokens parses semicolon-separated name:digest:scopes entries, where
+// scopes are comma-separated. Like readSHA256List it fails on any malformed
+// entry, unknown scope or repeated name, so a typo cannot silently widen or
+// drop access.
+func readAdminTokens(key string) ([]admin.Token, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return nil, nil
+	}
+	tokens := make([]admin.Token, 0, 2)
+	names := make(map[string]struct{}, 2)
+	for _, candidate := range strings.Split(raw, ";") {
+		fields := strings.SplitN(strings.TrimSpace(candidate), ":", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s entries must look like name:sha256:scope,scope", key)
+		}
+		name := strings.TrimSpace(fields[0])
+		if !adminTokenNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("%s contains invalid token name %q", key, name)
+		}
+		if _, exists := names[name]; exists {
+			return nil, fmt.Errorf("%s repeats token name %q", key, name)
+		}
+		names[name] = struct{}{}
+		decoded, err := hex.DecodeString(strings.TrimSpace(fields[1]))
+		if err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("%s token %q has an invalid SHA-256 digest", key, name)
+		}
+		token := admin.Token{Name: name, Digest: [sha256.Size]byte(decoded)}
+		for _, scope := range strings.Split(fields[2], ",") {
+			scope = strings.TrimSpace(scope)
+			if !slices.Contains(admin.Scopes, scope) {
+				return nil, fmt.Errorf("%s token %q has unknown scope %q", key, name, scope)
+			}
+			token.Scopes = append(token.Scopes, scope)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
 func getenv(key, fallback string) string {
 	value := strings.TrimSpace(os.Getenv(key))
 	if value == "" {
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"net/netip"
+	"strings"
 	"testing"
 	"time"
 )
@@ -39,6 +42,13 @@ func TestNormalizeConfigClampsOperationalBounds(t *testing.T) {
 	if config.DBMaxConns != 1 || config.DBMinConns != 1 {
 		t.Fatalf("unexpected database bounds: %#v", config)
 	}
+	if config.SellerStatsInterval != time.Minute || config.SellerStatsTimeout != 2*time.Minute {
+		t.Fatalf("unexpected seller stats schedule: %#v", config)
+	}
+	if config.WebhookPollInterval != time.Second || config.WebhookTimeout != 10*time.Second ||
+		config.WebhookMaxAttempts != 1 {
+		t.Fatalf("unexpected webhook bounds: %#v", config)
+	}
 	if config.CacheNamespace != "api-v2" ||
 		config.CatalogSummaryRelation != "public.catalog_slug_state" {
 		t.Fatalf("unexpected normalized defaults: %#v", config)
@@ -94,3 +104,101 @@ func TestLoadRejectsInvalidTrustedProxyCIDR(t *testing.T) {
 		t.Fatal("expected invalid trusted proxy CIDR to fail")
 	}
 }
+
+func TestReadSHA256ListDecodesDigests(t *testing.T) {
+	digest := sha256.Sum256([]byte("operator-token"))
+	t.Setenv("TEST_DIGESTS", " "+hex.EncodeToString(digest[:])+" ")
+	digests, err := readSHA256List("TEST_DIGESTS")
+	if err != nil {
+		t.Fatalf("read digests: %v", err)
+	}
+	if len(digests) != 1 || digests[0] != digest {
+		t.Fatalf("unexpected digests: %#v", digests)
+	}
+}
+
+func TestLoadRejectsInvalidAdminTokenDigest(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/example")
+	t.Setenv("API_ADMIN_TOKEN_SHA256", "plaintext-token")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected invalid admin token digest to fail")
+	}
+}
+
+func TestReadAdminTokensParsesScopedDigests(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	t.Setenv("API_ADMIN_TOKENS", " ops:"+digest+":* ; dashboard:"+strings.Repeat("CD", 32)+":sellers:read, audit:read")
+
+	tokens, err := readAdminTokens("API_ADMIN_TOKENS")
+	if err != nil {
+		t.Fatalf("read tokens: %v", err)
+	}
+	if len(tokens) != 2 || tokens[0].Name != "ops" || tokens[0].Digest[0] != 0xab ||
+		strings.Join(tokens[1].Scopes, ",") != "sellers:read,audit:read" || tokens[1].Digest[0] != 0xcd {
+		t.Fatalf("unexpected tokens %#v", tokens)
+	}
+}
+
+func TestLoadRejectsMalformedAdminTokens(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/example")
+	digest := strings.Repeat("ab", 32)
+	for name, value := range map[string]string{
+		"missing scopes": "ops:" + digest,
+		"plain secret":   "ops:secret:*",
+		"unknown scope":  "ops:" + digest + ":seller:write",
+		"repeated name":  "ops:" + digest + ":*;ops:" + digest + ":audit:read",
+		"invalid name":   "Ops Team:" + digest + ":*",
+	} {
+		t.Setenv("API_ADMIN_TOKENS", value)
+		if _, err := Load(); err == nil {
+			t.Fatalf("%s: expected startup to fail", name)
+		}
+	}
+}
+
+func TestLoadValidatesPriceAlertSettings(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/example")
+	t.Setenv("API_WATCH_TOKEN_SECRET", "too-short")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected a short watch token secret to fail")
+	}
+
+	t.Setenv("API_WATCH_TOKEN_SECRET", "0123456789abcdef0123456789abcdef")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected price alerts without SMTP settings to fail")
+	}
+
+	t.Setenv("API_SMTP_ADDR", "smtp.example.test:587")
+	t.Setenv("API_SMTP_FROM", "hlidani@example.test")
+	t.Setenv("API_WATCH_POLL_INTERVAL", "1s")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if !cfg.SMTPRequireTLS || cfg.WatchPollInterval != 10*time.Second {
+		t.Fatalf("unexpected price alert settings: %#v", cfg)
+	}
+}
+
+func TestLoadRefreshUsesMaintenanceDefaults(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/example")
+
+	cfg, err := LoadRefresh()
+	if err != nil {
+		t.Fatalf("load refresh config: %v", err)
+	}
+	if cfg.DatabaseRole != "tlamasite_maintenance" || cfg.Lookback != 48*time.Hour ||
+		cfg.LockTimeout != 10*time.Minute || cfg.Timeout != 30*time.Minute {
+		t.Fatalf("unexpected refresh defaults %#v", cfg)
+	}
+
+	t.Setenv("REFRESH_LOOKBACK", "0s")
+	if cfg, err := LoadRefresh(); err != nil || cfg.Lookback != 0 {
+		t.Fatalf("expected a zero lookback to be kept for a full refresh, got %#v %v", cfg, err)
+	}
+	t.Setenv("REFRESH_LOOKBACK", "-1h")
+	if _, err := LoadRefresh(); err == nil {
+		t.Fatal("expected a negative lookback to fail")
+	}
+}
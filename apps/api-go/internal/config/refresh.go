@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// RefreshConfig configures cmd/refresh. It shares DATABASE_URL and
+// API_DB_SIMPLE_PROTOCOL with the server and reads nothing else of its
+// settings.
+type RefreshConfig struct {
+	DatabaseURL    string
+	DatabaseRole   string
+	SimpleProtocol bool
+	// Lookback is how far back snapshots are reprocessed; zero is a full
+	// refresh.
+	Lookback    time.Duration
+	LockTimeout time.Duration
+	Timeout     time.Duration
+}
+
+func LoadRefresh() (RefreshConfig, error) {
+	cfg := RefreshConfig{
+		DatabaseURL:    strings.TrimSpace(os.Getenv("DATABASE_URL")),
+		DatabaseRole:   getenv("REFRESH_DATABASE_ROLE", "tlamasite_maintenance"),
+		SimpleProtocol: readBool("API_DB_SIMPLE_PROTOCOL", true),
+		Lookback:       readDuration("REFRESH_LOOKBACK", 48*time.Hour),
+		LockTimeout:    readDuration("REFRESH_LOCK_TIMEOUT", 10*time.Minute),
+		Timeout:        readDuration("REFRESH_TIMEOUT", 30*time.Minute),
+	}
+	if cfg.DatabaseURL == "" {
+		return RefreshConfig{}, errors.New("DATABASE_URL is required")
+	}
+	if cfg.Lookback < 0 {
+		return RefreshConfig{}, errors.New("REFRESH_LOOKBACK must not be negative")
+	}
+	if cfg.LockTimeout < 0 {
+		cfg.LockTimeout = 0
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Minute
+	}
+	return cfg, nil
+}
@@ -0,0 +1,193 @@
+// Package feed renders syndication documents. It knows nothing about the
+// catalog; callers map their rows to Items.
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"time"
+)
+
+const (
+	RSSContentType  = "application/rss+xml; charset=utf-8"
+	AtomContentType = "application/atom+xml; charset=utf-8"
+)
+
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the human-readable page the feed mirrors.
+	Link string
+	// SelfURL is the absolute URL the feed document is served from.
+	SelfURL  string
+	Language string
+	Updated  time.Time
+	Items    []Item
+}
+
+type Item struct {
+	// GUID must change whenever the item represents a new event, so readers
+	// announce it again.
+	GUID        string
+	Title       string
+	Link        string
+	Description string
+	Published   time.Time
+}
+
+// Updated returns the newest item timestamp, which is what Last-Modified and
+// the channel build date should report.
+func Updated(items []Item) time.Time {
+	var newest time.Time
+	for _, item := range items {
+		if item.Published.After(newest) {
+			newest = item.Published
+		}
+	}
+	return newest.UTC()
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	Language      string      `xml:"language,omitempty"`
+	LastBuildDate string      `xml:"lastBuildDate,omitempty"`
+	SelfLink      rssAtomLink `xml:"atom:link"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate,omitempty"`
+	Description string  `xml:"description,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS renders an RSS 2.0 document.
+func RSS(source Feed) ([]byte, error) {
+	channel := rssChannel{
+		Title:       source.Title,
+		Link:        source.Link,
+		Description: source.Description,
+		Language:    source.Language,
+		SelfLink:    rssAtomLink{Href: source.SelfURL, Rel: "self", Type: "application/rss+xml"},
+		Items:       make([]rssItem, 0, len(source.Items)),
+	}
+	if !source.Updated.IsZero() {
+		channel.LastBuildDate = source.Updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, item := range source.Items {
+		rendered := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: item.GUID},
+			Description: item.Description,
+		}
+		if !item.Published.IsZero() {
+			rendered.PubDate = item.Published.UTC().Format(time.RFC1123Z)
+		}
+		channel.Items = append(channel.Items, rendered)
+	}
+	return marshal(rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: channel,
+	})
+}
+
+type atomDocument struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Author   atomAuthor  `xml:"author"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary,omitempty"`
+}
+
+// Atom renders an Atom 1.0 document. The feed id is its self URL, and every
+// entry id is the item GUID.
+func Atom(source Feed) ([]byte, error) {
+	document := atomDocument{
+		ID:       source.SelfURL,
+		Title:    source.Title,
+		Subtitle: source.Description,
+		Updated:  atomTime(source.Updated),
+		Author:   atomAuthor{Name: source.Title},
+		Links: []atomLink{
+			{Href: source.SelfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: source.Link, Rel: "alternate", Type: "text/html"},
+		},
+		Entries: make([]atomEntry, 0, len(source.Items)),
+	}
+	for _, item := range source.Items {
+		document.Entries = append(document.Entries, atomEntry{
+			ID:      item.GUID,
+			Title:   item.Title,
+			Updated: atomTime(item.Published),
+			Link:    atomLink{Href: item.Link, Rel: "alternate"},
+			Summary: item.Description,
+		})
+	}
+	return marshal(document)
+}
+
+// atomTime formats a required Atom date; a zero time becomes the Unix epoch
+// rather than an invalid year-one timestamp.
+func atomTime(value time.Time) string {
+	if value.IsZero() {
+		value = time.Unix(0, 0)
+	}
+	return value.UTC().Format(time.RFC3339)
+}
+
+func marshal(document any) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buffer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	buffer.WriteByte('\n')
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,89 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	return Feed{
+		Title:       "Deskovky Levně – slevy",
+		Description: "Nejnovější slevy",
+		Link:        "https://example.test/deskove-hry",
+		SelfURL:     "https://example.test/feeds/discounts.xml?seller=tlama",
+		Language:    "cs",
+		Updated:     time.Date(2026, 7, 11, 13, 26, 17, 0, time.UTC),
+		Items: []Item{{
+			GUID:        "urn:tlamasite:discount:alpha:tlama:1783776377000000:799",
+			Title:       "Alpha & <Beta>",
+			Link:        "https://example.test/deskove-hry/alpha",
+			Description: "799 CZK",
+			Published:   time.Date(2026, 7, 11, 13, 26, 17, 0, time.UTC),
+		}},
+	}
+}
+
+func TestRSSRendersNonPermalinkGUIDsAndEscapesText(t *testing.T) {
+	body, err := RSS(testFeed())
+	if err != nil {
+		t.Fatalf("render rss: %v", err)
+	}
+	document := string(body)
+	for _, fragment := range []string{
+		`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">`,
+		`<atom:link href="https://example.test/feeds/discounts.xml?seller=tlama" rel="self" type="application/rss+xml">`,
+		`<guid isPermaLink="false">urn:tlamasite:discount:alpha:tlama:1783776377000000:799</guid>`,
+		`<title>Alpha &amp; &lt;Beta&gt;</title>`,
+		`<pubDate>Sat, 11 Jul 2026 13:26:17 +0000</pubDate>`,
+	} {
+		if !strings.Contains(document, fragment) {
+			t.Fatalf("rss missing %q:\n%s", fragment, document)
+		}
+	}
+	var parsed struct {
+		Items []struct {
+			Title string `xml:"title"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal(body, &parsed); err != nil || len(parsed.Items) != 1 || parsed.Items[0].Title != "Alpha & <Beta>" {
+		t.Fatalf("rss does not round-trip: %#v, %v", parsed, err)
+	}
+}
+
+func TestAtomUsesGUIDAsEntryID(t *testing.T) {
+	body, err := Atom(testFeed())
+	if err != nil {
+		t.Fatalf("render atom: %v", err)
+	}
+	var parsed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Updated string `xml:"updated"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &parsed); err != nil {
+		t.Fatalf("parse atom: %v", err)
+	}
+	if parsed.ID != testFeed().SelfURL || parsed.Updated != "2026-07-11T13:26:17Z" {
+		t.Fatalf("unexpected feed header: %#v", parsed)
+	}
+	if len(parsed.Entries) != 1 || parsed.Entries[0].ID != testFeed().Items[0].GUID {
+		t.Fatalf("unexpected entries: %#v", parsed.Entries)
+	}
+}
+
+func TestUpdatedReturnsNewestItem(t *testing.T) {
+	older := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(48 * time.Hour)
+	if got := Updated([]Item{{Published: older}, {Published: newer}}); !got.Equal(newer) {
+		t.Fatalf("unexpected updated time %s", got)
+	}
+	if !Updated(nil).IsZero() {
+		t.Fatal("empty feeds have no update time")
+	}
+}
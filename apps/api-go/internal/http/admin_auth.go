@@ -0,0 +1,149 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"tlamasite/apps/api-go/internal/admin"
+)
+
+// requireAdminToken accepts a bearer token whose SHA-256 digest is listed in
+// tokenHashes. Only digests are configured, so plaintext tokens never live in
+// the environment, and every digest is compared in constant time.
+func requireAdminToken(tokenHashes [][sha256.Size]byte) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "no-store")
+			if !adminTokenMatches(r.Header.Get("Authorization"), tokenHashes) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				writeErrorCode(w, r, http.StatusUnauthorized, "unauthorized", "admin token required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func adminTokenMatches(authorization string, tokenHashes [][sha256.Size]byte) bool {
+	digest, ok := bearerDigest(authorization)
+	if !ok {
+		return false
+	}
+	matched := 0
+	for _, expected := range tokenHashes {
+		matched |= subtle.ConstantTimeCompare(digest[:], expected[:])
+	}
+	return matched == 1
+}
+
+func bearerDigest(authorization string) ([sha256.Size]byte, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(authorization), " ")
+	token = strings.TrimSpace(token)
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return [sha256.Size]byte{}, false
+	}
+	return sha256.Sum256([]byte(token)), true
+}
+
+type adminTokenContextKey struct{}
+
+type auditRecorder interface {
+	RecordAudit(context.Context, admin.AuditEntry) error
+}
+
+// requireScopedAdminToken authenticates /admin/v1 calls against the named
+// tokens and keeps the match in the context for scope checks and the audit
+// log. Every configured digest is compared, as in requireAdminToken.
+func requireScopedAdminToken(tokens []admin.Token) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "no-store")
+			token, ok := scopedAdminToken(r.Header.Get("Authorization"), tokens)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				writeErrorCode(w, r, http.StatusUnauthorized, "unauthorized", "admin token required")
+				return
+			}
+			ctx := context.WithValue(r.Context(), adminTokenContextKey{}, token)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func scopedAdminToken(authorization string, tokens []admin.Token) (admin.Token, bool) {
+	digest, ok := bearerDigest(authorization)
+	if !ok {
+		return admin.Token{}, false
+	}
+	matched := -1
+	for index, token := range tokens {
+		if subtle.ConstantTimeCompare(digest[:], token.Digest[:]) == 1 {
+			matched = index
+		}
+	}
+	if matched < 0 {
+		return admin.Token{}, false
+	}
+	return tokens[matched], true
+}
+
+func requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, _ := r.Context().Value(adminTokenContextKey{}).(admin.Token)
+			if !token.Allows(scope) {
+				writeErrorCode(w, r, http.StatusForbidden, "forbidden", "admin token lacks scope "+scope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// auditMutations records every authenticated call that is not a read, after
+// it completes and whatever its outcome. The entry is written even when the
+// request context has timed out; a failed write is logged, since the change
+// itself has already happened.
+func auditMutations(recorder auditRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+			writer := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(writer, r)
+
+			token, _ := r.Context().Value(adminTokenContextKey{}).(admin.Token)
+			entry := admin.AuditEntry{
+				Actor:     token.Name,
+				Method:    r.Method,
+				Route:     chi.RouteContext(r.Context()).RoutePattern(),
+				Path:      r.URL.EscapedPath(),
+				Status:    writer.Status(),
+				RequestID: middleware.GetReqID(r.Context()),
+				ClientIP:  clientIP(r),
+			}
+			if entry.Status == 0 {
+				entry.Status = http.StatusOK
+			}
+			if err := recorder.RecordAudit(context.WithoutCancel(r.Context()), entry); err != nil {
+				slog.Error("admin_audit_failed",
+					"request_id", entry.RequestID,
+					"actor", entry.Actor,
+					"method", entry.Method,
+					"path", entry.Path,
+					"status", entry.Status,
+					"error", err.Error(),
+				)
+			}
+		})
+	}
+}
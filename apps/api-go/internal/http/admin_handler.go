@@ -0,0 +1,232 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"tlamasite/apps/api-go/internal/admin"
+)
+
+type adminRepository interface {
+	SellerSnapshots(context.Context, admin.SnapshotFilters) (admin.SnapshotPage, error)
+	Sellers(context.Context) ([]admin.SellerRegistration, error)
+	SaveSeller(context.Context, admin.SellerRegistration) (admin.SellerRegistration, error)
+	DeleteSeller(context.Context, string) error
+	Webhooks(context.Context) ([]admin.WebhookSubscription, error)
+	CreateWebhook(context.Context, admin.WebhookSubscription) (admin.WebhookSubscription, error)
+	DeleteWebhook(context.Context, int64) error
+	WebhookDeliveries(context.Context, admin.WebhookDeliveryFilters) ([]admin.WebhookDelivery, error)
+	RetryWebhookDelivery(context.Context, int64) error
+	RecordAudit(context.Context, admin.AuditEntry) error
+	AuditLog(context.Context, admin.AuditFilters) ([]admin.AuditEntry, error)
+	AliasCandidates(context.Context, admin.AliasCandidateFilters) ([]admin.AliasCandidate, error)
+	ApproveAliasCandidate(context.Context, int64, *string) (admin.AliasReview, error)
+	RejectAliasCandidate(context.Context, int64, *string) (admin.AliasReview, error)
+}
+
+// AdminHandler serves authenticated operational endpoints. Responses are
+// never cached because they expose raw scraper data. tokenHashes guard the
+// original, read-only /api/v1/admin group; tokens are the named, scoped tokens
+// of /admin/v1. Either group is mounted only when its list is non-empty.
+type AdminHandler struct {
+	repository  adminRepository
+	tokenHashes [][sha256.Size]byte
+	tokens      []admin.Token
+}
+
+func NewAdminHandler(
+	repository adminRepository,
+	tokenHashes [][sha256.Size]byte,
+	tokens []admin.Token,
+) *AdminHandler {
+	return &AdminHandler{repository: repository, tokenHashes: tokenHashes, tokens: tokens}
+}
+
+func (h *AdminHandler) SellerSnapshots(w http.ResponseWriter, r *http.Request) {
+	filters, validationErr := parseSnapshotFilters(
+		chi.URLParam(r, "slug"),
+		chi.URLParam(r, "seller"),
+		r.URL.Query(),
+	)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	page, err := h.repository.SellerSnapshots(r.Context(), filters)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h *AdminHandler) Sellers(w http.ResponseWriter, r *http.Request) {
+	sellers, err := h.repository.Sellers(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"rows": sellers})
+}
+
+// SaveSeller replaces one registration. Public responses pick the change up
+// once their cache entries expire.
+func (h *AdminHandler) SaveSeller(w http.ResponseWriter, r *http.Request) {
+	registration, validationErr := parseSellerRegistration(chi.URLParam(r, "seller"), r.Body)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	saved, err := h.repository.SaveSeller(r.Context(), registration)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, saved)
+}
+
+func (h *AdminHandler) DeleteSeller(w http.ResponseWriter, r *http.Request) {
+	seller, validationErr := validateSeller(chi.URLParam(r, "seller"))
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	if err := h.repository.DeleteSeller(r.Context(), seller); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) Webhooks(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.repository.Webhooks(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"rows": subscriptions})
+}
+
+// CreateWebhook answers with the signing secret. It is not shown again, so a
+// lost secret means deleting and recreating the subscription.
+func (h *AdminHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	subscription, validationErr := parseWebhookSubscription(r.Body)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	created, err := h.repository.CreateWebhook(r.Context(), subscription)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (h *AdminHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, validationErr := parseWebhookID(chi.URLParam(r, "id"), "webhook id")
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	if err := h.repository.DeleteWebhook(r.Context(), id); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) WebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	filters, validationErr := parseWebhookDeliveryFilters(chi.URLParam(r, "id"), r.URL.Query())
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	deliveries, err := h.repository.WebhookDeliveries(r.Context(), filters)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"rows": deliveries})
+}
+
+// RetryWebhookDelivery requeues a dead or delivered delivery with a fresh
+// attempt budget.
+func (h *AdminHandler) RetryWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, validationErr := parseWebhookID(chi.URLParam(r, "id"), "delivery id")
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	if err := h.repository.RetryWebhookDelivery(r.Context(), id); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *AdminHandler) AuditLog(w http.ResponseWriter, r *http.Request) {
+	filters, validationErr := parseAuditFilters(r.URL.Query())
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	entries, err := h.repository.AuditLog(r.Context(), filters)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"rows": entries})
+}
+
+func (h *AdminHandler) AliasCandidates(w http.ResponseWriter, r *http.Request) {
+	filters, validationErr := parseAliasCandidateFilters(r.URL.Query())
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	candidates, err := h.repository.AliasCandidates(r.Context(), filters)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"rows": candidates})
+}
+
+// ApproveAliasCandidate answers only after daily history and catalog state
+// have been rebuilt, so the merged product is live when it returns.
+func (h *AdminHandler) ApproveAliasCandidate(w http.ResponseWriter, r *http.Request) {
+	h.reviewAliasCandidate(w, r, false, h.repository.ApproveAliasCandidate)
+}
+
+func (h *AdminHandler) RejectAliasCandidate(w http.ResponseWriter, r *http.Request) {
+	h.reviewAliasCandidate(w, r, true, h.repository.RejectAliasCandidate)
+}
+
+func (h *AdminHandler) reviewAliasCandidate(
+	w http.ResponseWriter,
+	r *http.Request,
+	notesRequired bool,
+	review func(context.Context, int64, *string) (admin.AliasReview, error),
+) {
+	id, validationErr := parseWebhookID(chi.URLParam(r, "id"), "candidate id")
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	notes, validationErr := parseAliasReview(r.Body, notesRequired)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	result, err := review(r.Context(), id, notes)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
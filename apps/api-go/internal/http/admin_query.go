@@ -0,0 +1,241 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"tlamasite/apps/api-go/internal/admin"
+)
+
+func parseSnapshotFilters(rawSlug string, rawSeller string, values url.Values) (admin.SnapshotFilters, error) {
+	slug, err := validateProductSlug(rawSlug)
+	if err != nil {
+		return admin.SnapshotFilters{}, err
+	}
+	seller, err := validateSeller(rawSeller)
+	if err != nil {
+		return admin.SnapshotFilters{}, err
+	}
+	limit, err := parseBoundedInt(values, "limit", admin.DefaultSnapshotLimit, admin.MaxSnapshotLimit)
+	if err != nil {
+		return admin.SnapshotFilters{}, err
+	}
+	from, err := parseOptionalInstant(values, "from")
+	if err != nil {
+		return admin.SnapshotFilters{}, err
+	}
+	to, err := parseOptionalInstant(values, "to")
+	if err != nil {
+		return admin.SnapshotFilters{}, err
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return admin.SnapshotFilters{}, fmt.Errorf("from must be before to")
+	}
+	filters := admin.SnapshotFilters{Slug: slug, Seller: seller, From: from, To: to, Limit: limit}
+	if rawCursor := strings.TrimSpace(values.Get("cursor")); rawCursor != "" {
+		cursor, err := admin.DecodeSnapshotCursor(rawCursor)
+		if err != nil {
+			return admin.SnapshotFilters{}, err
+		}
+		filters.Cursor = &cursor
+	}
+	return filters, nil
+}
+
+// parseOptionalInstant accepts RFC 3339 timestamps or YYYY-MM-DD dates, which
+// mean midnight UTC.
+func parseOptionalInstant(values url.Values, key string) (*time.Time, error) {
+	raw := strings.TrimSpace(values.Get(key))
+	if raw == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+		if parsed, err := time.Parse(layout, raw); err == nil {
+			parsed = parsed.UTC()
+			return &parsed, nil
+		}
+	}
+	return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or YYYY-MM-DD date", key)
+}
+
+const (
+	maxSellerBodyBytes  = 16 << 10
+	maxSellerTextLength = 200
+	maxPickupOptions    = 20
+	maxShippingAmount   = 100000
+	minSellerStaleAfter = time.Hour
+	maxSellerStaleAfter = 90 * 24 * time.Hour
+	maxSellerPriority   = 10000
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// sellerRegistrationBody is the PUT payload. Pointers tell omitted fields from
+// zero values so omitted settings fall back to the registry defaults.
+type sellerRegistrationBody struct {
+	DisplayName           *string  `json:"display_name"`
+	HomepageURL           *string  `json:"homepage_url"`
+	LogoPath              *string  `json:"logo_path"`
+	Priority              *int     `json:"priority"`
+	StaleAfterSeconds     *int64   `json:"stale_after_seconds"`
+	ShippingCurrencyCode  *string  `json:"shipping_currency_code"`
+	FreeShippingThreshold *float64 `json:"free_shipping_threshold"`
+	FlatShippingCost      *float64 `json:"flat_shipping_cost"`
+	PickupOptions         []string `json:"pickup_options"`
+}
+
+func parseSellerRegistration(rawSeller string, body io.Reader) (admin.SellerRegistration, error) {
+	seller, err := validateSeller(rawSeller)
+	if err != nil {
+		return admin.SellerRegistration{}, err
+	}
+	var payload sellerRegistrationBody
+	if err := decodeJSONObject(body, maxSellerBodyBytes, "a seller registration", &payload); err != nil {
+		return admin.SellerRegistration{}, err
+	}
+
+	registration := admin.SellerRegistration{
+		Seller:                seller,
+		Priority:              admin.DefaultSellerPriority,
+		StaleAfterSeconds:     int64(admin.DefaultStaleAfter / time.Second),
+		ShippingCurrencyCode:  admin.DefaultShippingCurrency,
+		FreeShippingThreshold: payload.FreeShippingThreshold,
+		FlatShippingCost:      payload.FlatShippingCost,
+	}
+	if registration.DisplayName, err = optionalText(payload.DisplayName, "display_name"); err != nil {
+		return admin.SellerRegistration{}, err
+	}
+	if registration.HomepageURL, err = optionalHTTPSURL(payload.HomepageURL, "homepage_url"); err != nil {
+		return admin.SellerRegistration{}, err
+	}
+	if registration.LogoPath, err = optionalLogoPath(payload.LogoPath); err != nil {
+		return admin.SellerRegistration{}, err
+	}
+	if payload.Priority != nil {
+		if *payload.Priority < 0 || *payload.Priority > maxSellerPriority {
+			return admin.SellerRegistration{}, fmt.Errorf("priority must be between 0 and %d", maxSellerPriority)
+		}
+		registration.Priority = *payload.Priority
+	}
+	if payload.StaleAfterSeconds != nil {
+		staleAfter := time.Duration(*payload.StaleAfterSeconds) * time.Second
+		if staleAfter < minSellerStaleAfter || staleAfter > maxSellerStaleAfter {
+			return admin.SellerRegistration{}, errors.New("stale_after_seconds must be between 1 hour and 90 days")
+		}
+		registration.StaleAfterSeconds = *payload.StaleAfterSeconds
+	}
+	if payload.ShippingCurrencyCode != nil {
+		if !currencyCodePattern.MatchString(*payload.ShippingCurrencyCode) {
+			return admin.SellerRegistration{}, errors.New("shipping_currency_code must be an ISO 4217 code")
+		}
+		registration.ShippingCurrencyCode = *payload.ShippingCurrencyCode
+	}
+	for key, amount := range map[string]*float64{
+		"free_shipping_threshold": payload.FreeShippingThreshold,
+		"flat_shipping_cost":      payload.FlatShippingCost,
+	} {
+		if amount != nil && (*amount < 0 || *amount > maxShippingAmount) {
+			return admin.SellerRegistration{}, fmt.Errorf("%s must be between 0 and %d", key, maxShippingAmount)
+		}
+	}
+	if registration.PickupOptions, err = parsePickupOptions(payload.PickupOptions); err != nil {
+		return admin.SellerRegistration{}, err
+	}
+	return registration, nil
+}
+
+func optionalText(raw *string, key string) (*string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	value := strings.TrimSpace(*raw)
+	if value == "" {
+		return nil, nil
+	}
+	if len([]rune(value)) > maxSellerTextLength {
+		return nil, fmt.Errorf("%s must be at most %d characters", key, maxSellerTextLength)
+	}
+	return &value, nil
+}
+
+// optionalHTTPSURL applies the catalog URL trust rule: absolute HTTPS without
+// embedded credentials.
+func optionalHTTPSURL(raw *string, key string) (*string, error) {
+	value, err := optionalText(raw, key)
+	if value == nil || err != nil {
+		return value, err
+	}
+	parsed, err := url.Parse(*value)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" || parsed.User != nil {
+		return nil, fmt.Errorf("%s must be an absolute https URL without credentials", key)
+	}
+	return value, nil
+}
+
+// optionalLogoPath accepts a site-local absolute path or an HTTPS URL, the
+// same shapes the frontend accepts for images.
+func optionalLogoPath(raw *string) (*string, error) {
+	value, err := optionalText(raw, "logo_path")
+	if value == nil || err != nil {
+		return value, err
+	}
+	if strings.HasPrefix(*value, "/") && !strings.HasPrefix(*value, "//") {
+		return value, nil
+	}
+	return optionalHTTPSURL(value, "logo_path")
+}
+
+func parsePickupOptions(raw []string) ([]string, error) {
+	if len(raw) > maxPickupOptions {
+		return nil, fmt.Errorf("pickup_options must contain at most %d entries", maxPickupOptions)
+	}
+	seen := make(map[string]struct{}, len(raw))
+	options := make([]string, 0, len(raw))
+	for _, candidate := range raw {
+		option, err := optionalText(&candidate, "pickup_options")
+		if err != nil {
+			return nil, err
+		}
+		if option == nil {
+			return nil, errors.New("pickup_options must not contain empty entries")
+		}
+		if _, exists := seen[*option]; exists {
+			continue
+		}
+		seen[*option] = struct{}{}
+		options = append(options, *option)
+	}
+	return options, nil
+}
+
+// decodeJSONObject decodes a size-limited request body holding exactly one
+// object with no unknown fields.
+func decodeJSONObject(body io.Reader, limit int64, description string, target any) error {
+	decoder := json.NewDecoder(io.LimitReader(body, limit))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("body must be %s JSON object: %w", description, err)
+	}
+	if decoder.More() {
+		return errors.New("body must contain a single JSON object")
+	}
+	return nil
+}
+
+func parseAuditFilters(values url.Values) (admin.AuditFilters, error) {
+	limit, err := parseBoundedInt(values, "limit", admin.DefaultAuditLimit, admin.MaxAuditLimit)
+	if err != nil {
+		return admin.AuditFilters{}, err
+	}
+	filters := admin.AuditFilters{Limit: limit}
+	if actor := strings.TrimSpace(values.Get("actor")); actor != "" {
+		filters.Actor = &actor
+	}
+	return filters, nil
+}
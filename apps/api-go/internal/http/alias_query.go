@@ -0,0 +1,75 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+
+	"tlamasite/apps/api-go/internal/admin"
+)
+
+const (
+	maxAliasReviewBodyBytes = 8 << 10
+	maxAliasReviewNotes     = 2000
+)
+
+type aliasReviewBody struct {
+	Notes *string `json:"notes"`
+}
+
+func parseAliasCandidateFilters(values url.Values) (admin.AliasCandidateFilters, error) {
+	limit, err := parseBoundedInt(values, "limit", admin.DefaultCandidateLimit, admin.MaxCandidateLimit)
+	if err != nil {
+		return admin.AliasCandidateFilters{}, err
+	}
+	filters := admin.AliasCandidateFilters{Limit: limit}
+	if rule := strings.ToLower(strings.TrimSpace(values.Get("match_rule"))); rule != "" {
+		if !slices.Contains(admin.AliasMatchRules, rule) {
+			return admin.AliasCandidateFilters{}, fmt.Errorf(
+				"match_rule must be one of %s",
+				strings.Join(admin.AliasMatchRules, ", "),
+			)
+		}
+		filters.MatchRule = &rule
+	}
+	return filters, nil
+}
+
+// parseAliasReview reads the review notes. An approval may send no body at
+// all; a rejection must say why so the candidate is not proposed blindly
+// again by the next reviewer.
+func parseAliasReview(body io.Reader, notesRequired bool) (*string, error) {
+	raw, err := io.ReadAll(io.LimitReader(body, maxAliasReviewBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > maxAliasReviewBodyBytes {
+		return nil, fmt.Errorf("body must be at most %d bytes", maxAliasReviewBodyBytes)
+	}
+	var payload aliasReviewBody
+	if len(bytes.TrimSpace(raw)) > 0 {
+		if err := decodeJSONObject(bytes.NewReader(raw), maxAliasReviewBodyBytes, "a review", &payload); err != nil {
+			return nil, err
+		}
+	}
+	var notes *string
+	if payload.Notes != nil {
+		if value := strings.TrimSpace(*payload.Notes); value != "" {
+			notes = &value
+		}
+	}
+	if notes == nil {
+		if notesRequired {
+			return nil, errors.New("notes is required")
+		}
+		return nil, nil
+	}
+	if len([]rune(*notes)) > maxAliasReviewNotes {
+		return nil, fmt.Errorf("notes must be at most %d characters", maxAliasReviewNotes)
+	}
+	return notes, nil
+}
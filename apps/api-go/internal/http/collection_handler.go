@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"tlamasite/apps/api-go/internal/collections"
+)
+
+type collectionService interface {
+	Create(context.Context, collections.CreateRequest) (collections.Created, error)
+	Get(context.Context, string) (collections.Collection, error)
+	AddItem(context.Context, string, string, string) (collections.Collection, error)
+	RemoveItem(context.Context, string, string, string) (collections.Collection, error)
+	Delete(context.Context, string, string) error
+}
+
+// CollectionHandler serves shareable collections. The public id in the path
+// grants read access; edits carry the edit token in the request body, like
+// watch tokens, so request logs cannot replay them.
+type CollectionHandler struct {
+	service collectionService
+}
+
+func NewCollectionHandler(service collectionService) *CollectionHandler {
+	return &CollectionHandler{service: service}
+}
+
+func (h *CollectionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	request, validationErr := parseCollectionCreate(r.Body)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	created, err := h.service.Create(r.Context(), request)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// Get is not cached: prices are live and the owner expects to see edits
+// immediately.
+func (h *CollectionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, validationErr := validateCollectionID(chi.URLParam(r, "id"))
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	collection, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, collection)
+}
+
+func (h *CollectionHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	h.editItem(w, r, h.service.AddItem)
+}
+
+func (h *CollectionHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	h.editItem(w, r, h.service.RemoveItem)
+}
+
+func (h *CollectionHandler) editItem(
+	w http.ResponseWriter,
+	r *http.Request,
+	edit func(context.Context, string, string, string) (collections.Collection, error),
+) {
+	id, validationErr := validateCollectionID(chi.URLParam(r, "id"))
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	token, slug, validationErr := parseCollectionItem(r.Body)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	collection, err := edit(r.Context(), id, token, slug)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, collection)
+}
+
+func (h *CollectionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, validationErr := validateCollectionID(chi.URLParam(r, "id"))
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	token, validationErr := parseCollectionToken(r.Body)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	if err := h.service.Delete(r.Context(), id, token); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"tlamasite/apps/api-go/internal/collections"
+)
+
+const testCollectionID = "Ab3dEf6hIj9lMn0p"
+
+type fakeCollectionService struct {
+	created *collections.CreateRequest
+	edits   []string
+	deleted bool
+	err     error
+}
+
+func (f *fakeCollectionService) Create(_ context.Context, request collections.CreateRequest) (collections.Created, error) {
+	f.created = &request
+	if f.err != nil {
+		return collections.Created{}, f.err
+	}
+	return collections.Created{
+		Collection: collections.Collection{ID: testCollectionID, Items: []collections.Item{}},
+		EditToken:  "edit-token",
+	}, nil
+}
+
+func (f *fakeCollectionService) Get(_ context.Context, id string) (collections.Collection, error) {
+	if id != testCollectionID {
+		return collections.Collection{}, collections.ErrCollectionNotFound
+	}
+	return collections.Collection{ID: id, Items: []collections.Item{}}, nil
+}
+
+func (f *fakeCollectionService) edit(id string, token string, slug string, action string) (collections.Collection, error) {
+	if token != "edit-token" {
+		return collections.Collection{}, collections.ErrInvalidEditToken
+	}
+	if f.err != nil {
+		return collections.Collection{}, f.err
+	}
+	f.edits = append(f.edits, action+":"+slug)
+	return collections.Collection{ID: id}, nil
+}
+
+func (f *fakeCollectionService) AddItem(_ context.Context, id string, token string, slug string) (collections.Collection, error) {
+	return f.edit(id, token, slug, "add")
+}
+
+func (f *fakeCollectionService) RemoveItem(_ context.Context, id string, token string, slug string) (collections.Collection, error) {
+	return f.edit(id, token, slug, "remove")
+}
+
+func (f *fakeCollectionService) Delete(_ context.Context, _ string, token string) error {
+	if token != "edit-token" {
+		return collections.ErrInvalidEditToken
+	}
+	f.deleted = true
+	return nil
+}
+
+func newCollectionTestRouter(service collectionService) http.Handler {
+	return NewRouter(NewHandler(&fakeService{}, 200), RouterOptions{
+		AllowedOrigin: "*",
+		Collections:   NewCollectionHandler(service),
+	})
+}
+
+func TestCreateCollectionNormalizesBodyAndReturnsToken(t *testing.T) {
+	service := &fakeCollectionService{}
+	recorder := serveWatchRequest(
+		newCollectionTestRouter(service),
+		http.MethodPost,
+		"/api/v1/collections",
+		`{"title":"  Herní večer  ","slugs":[" Azul ","carcassonne"]}`,
+	)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	request := service.created
+	if request == nil || *request.Title != "Herní večer" || strings.Join(request.Slugs, ",") != "azul,carcassonne" {
+		t.Fatalf("unexpected create request %#v", request)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil ||
+		payload["edit_token"] != "edit-token" || payload["id"] != testCollectionID {
+		t.Fatalf("unexpected create payload %s (%v)", recorder.Body.String(), err)
+	}
+
+	router := newCollectionTestRouter(&fakeCollectionService{})
+	for _, body := range []string{
+		`[]`,
+		`{"slugs":[""]}`,
+		`{"title":"` + strings.Repeat("x", collections.MaxTitleLength+1) + `"}`,
+		`{"slugs":["azul"],"edit_token":"mine"}`,
+	} {
+		if recorder := serveWatchRequest(router, http.MethodPost, "/api/v1/collections", body); recorder.Code != http.StatusBadRequest {
+			t.Fatalf("%.40s: expected 400, got %d", body, recorder.Code)
+		}
+	}
+}
+
+func TestCollectionReadsAndEdits(t *testing.T) {
+	service := &fakeCollectionService{}
+	router := newCollectionTestRouter(service)
+	path := "/api/v1/collections/" + testCollectionID
+
+	recorder := serveWatchRequest(router, http.MethodGet, path, "")
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("expected uncached 200, got %d %q", recorder.Code, recorder.Header().Get("Cache-Control"))
+	}
+	if recorder := serveWatchRequest(router, http.MethodGet, "/api/v1/collections/short", ""); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for malformed id, got %d", recorder.Code)
+	}
+	if recorder := serveWatchRequest(router, http.MethodGet, "/api/v1/collections/Zb3dEf6hIj9lMn0p", ""); recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown id, got %d", recorder.Code)
+	}
+
+	if recorder := serveWatchRequest(router, http.MethodPost, path+"/items", `{"edit_token":"edit-token","slug":"Azul"}`); recorder.Code != http.StatusOK {
+		t.Fatalf("expected add to succeed, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if recorder := serveWatchRequest(router, http.MethodDelete, path+"/items", `{"edit_token":"edit-token","slug":"azul"}`); recorder.Code != http.StatusOK {
+		t.Fatalf("expected remove to succeed, got %d", recorder.Code)
+	}
+	if strings.Join(service.edits, ",") != "add:azul,remove:azul" {
+		t.Fatalf("unexpected edits %v", service.edits)
+	}
+	recorder = serveWatchRequest(router, http.MethodPost, path+"/items", `{"edit_token":"forged","slug":"azul"}`)
+	if recorder.Code != http.StatusForbidden || !strings.Contains(recorder.Body.String(), `"forbidden"`) {
+		t.Fatalf("expected 403 forbidden, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if recorder := serveWatchRequest(router, http.MethodPost, path+"/items", `{"slug":"azul"}`); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without token, got %d", recorder.Code)
+	}
+	if recorder := serveWatchRequest(router, http.MethodDelete, path, `{"edit_token":"edit-token"}`); recorder.Code != http.StatusNoContent || !service.deleted {
+		t.Fatalf("expected deletion, got %d", recorder.Code)
+	}
+}
+
+func TestCollectionServiceErrorsMapToCodes(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{collections.ErrCollectionFull, http.StatusConflict, "collection_full"},
+		{collections.ErrProductNotFound, http.StatusNotFound, "not_found"},
+	}
+	for _, testCase := range tests {
+		recorder := serveWatchRequest(
+			newCollectionTestRouter(&fakeCollectionService{err: testCase.err}),
+			http.MethodPost,
+			"/api/v1/collections/"+testCollectionID+"/items",
+			`{"edit_token":"edit-token","slug":"azul"}`,
+		)
+		var payload map[string]string
+		_ = json.Unmarshal(recorder.Body.Bytes(), &payload)
+		if recorder.Code != testCase.status || payload["code"] != testCase.code {
+			t.Fatalf("%v: expected %d %s, got %d %s", testCase.err, testCase.status, testCase.code, recorder.Code, recorder.Body.String())
+		}
+	}
+}
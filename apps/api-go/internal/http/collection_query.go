@@ -0,0 +1,103 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"tlamasite/apps/api-go/internal/collections"
+)
+
+const (
+	maxCollectionBodyBytes  = 32 << 10
+	maxCollectionTokenBytes = 128
+)
+
+var collectionIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{16}$`)
+
+type collectionCreateBody struct {
+	Title *string  `json:"title"`
+	Slugs []string `json:"slugs"`
+}
+
+type collectionItemBody struct {
+	EditToken string `json:"edit_token"`
+	Slug      string `json:"slug"`
+}
+
+type collectionTokenBody struct {
+	EditToken string `json:"edit_token"`
+}
+
+func parseCollectionCreate(body io.Reader) (collections.CreateRequest, error) {
+	var payload collectionCreateBody
+	if err := decodeJSONObject(body, maxCollectionBodyBytes, "a collection", &payload); err != nil {
+		return collections.CreateRequest{}, err
+	}
+	request := collections.CreateRequest{Slugs: make([]string, 0, len(payload.Slugs))}
+	if payload.Title != nil {
+		title := strings.TrimSpace(*payload.Title)
+		if utf8.RuneCountInString(title) > collections.MaxTitleLength {
+			return collections.CreateRequest{}, fmt.Errorf(
+				"title must be at most %d characters",
+				collections.MaxTitleLength,
+			)
+		}
+		if title != "" {
+			request.Title = &title
+		}
+	}
+	if len(payload.Slugs) > collections.MaxItems {
+		return collections.CreateRequest{}, fmt.Errorf("slugs must list at most %d products", collections.MaxItems)
+	}
+	for _, raw := range payload.Slugs {
+		slug, err := validateProductSlug(raw)
+		if err != nil {
+			return collections.CreateRequest{}, err
+		}
+		request.Slugs = append(request.Slugs, slug)
+	}
+	return request, nil
+}
+
+func validateCollectionID(raw string) (string, error) {
+	if !collectionIDPattern.MatchString(raw) {
+		return "", errors.New("collection id must be 16 URL-safe characters")
+	}
+	return raw, nil
+}
+
+func parseCollectionItem(body io.Reader) (string, string, error) {
+	var payload collectionItemBody
+	if err := decodeJSONObject(body, maxCollectionBodyBytes, "a collection item", &payload); err != nil {
+		return "", "", err
+	}
+	token, err := validateEditToken(payload.EditToken)
+	if err != nil {
+		return "", "", err
+	}
+	slug, err := validateProductSlug(payload.Slug)
+	if err != nil {
+		return "", "", err
+	}
+	return token, slug, nil
+}
+
+func parseCollectionToken(body io.Reader) (string, error) {
+	var payload collectionTokenBody
+	if err := decodeJSONObject(body, maxCollectionBodyBytes, "an edit token", &payload); err != nil {
+		return "", err
+	}
+	return validateEditToken(payload.EditToken)
+}
+
+func validateEditToken(raw string) (string, error) {
+	token := strings.TrimSpace(raw)
+	if token == "" || len(token) > maxCollectionTokenBytes {
+		return "", errors.New("edit_token is required")
+	}
+	return token, nil
+}
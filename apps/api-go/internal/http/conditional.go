@@ -0,0 +1,133 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"tlamasite/apps/api-go/internal/snapshots"
+)
+
+// encodedProductDetail keeps the serialized product detail next to its
+// validators, so conditional requests are answered without re-encoding.
+type encodedProductDetail struct {
+	Body          json.RawMessage `json:"body"`
+	CanonicalSlug string          `json:"canonical_slug"`
+	ETag          string          `json:"etag"`
+	LastModified  time.Time       `json:"last_modified"`
+}
+
+var postgresTimestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999-07:00",
+	time.RFC3339Nano,
+}
+
+func encodeProductDetail(detail snapshots.ProductDetail) (encodedProductDetail, error) {
+	body, err := json.Marshal(detail)
+	if err != nil {
+		return encodedProductDetail{}, err
+	}
+	return encodedProductDetail{
+		Body:          body,
+		CanonicalSlug: detail.ProductNameNormalized,
+		ETag:          bodyETag(body),
+		LastModified:  newestScrapedAt(detail.Sellers),
+	}, nil
+}
+
+func newestScrapedAt(sellers []snapshots.Seller) time.Time {
+	var newest time.Time
+	for _, seller := range sellers {
+		if seller.LatestScrapedAt == nil {
+			continue
+		}
+		scrapedAt, ok := parsePostgresTimestamp(*seller.LatestScrapedAt)
+		if ok && scrapedAt.After(newest) {
+			newest = scrapedAt
+		}
+	}
+	return newest.UTC().Truncate(time.Second)
+}
+
+func parsePostgresTimestamp(raw string) (time.Time, bool) {
+	for _, layout := range postgresTimestampLayouts {
+		if parsed, err := time.Parse(layout, raw); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func writeConditionalJSON(w http.ResponseWriter, r *http.Request, payload encodedProductDetail) {
+	writeConditional(
+		w,
+		r,
+		"application/json; charset=utf-8",
+		append(payload.Body, '\n'),
+		payload.ETag,
+		payload.LastModified,
+	)
+}
+
+func writeConditional(
+	w http.ResponseWriter,
+	r *http.Request,
+	contentType string,
+	body []byte,
+	etag string,
+	lastModified time.Time,
+) {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	if isNotModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// bodyETag is a strong validator for a fully rendered body.
+func bodyETag(body []byte) string {
+	digest := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(digest[:16]) + `"`
+}
+
+// isNotModified follows RFC 9110: If-None-Match takes precedence, and
+// If-Modified-Since is only evaluated when it is absent.
+func isNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etag != "" && etagListMatches(ifNoneMatch, etag)
+	}
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(since)
+}
+
+func etagListMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
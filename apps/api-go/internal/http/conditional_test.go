@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"tlamasite/apps/api-go/internal/snapshots"
+)
+
+func TestHandlerProductDetailAnswersConditionalRequests(t *testing.T) {
+	scrapedAt := "2026-03-01 10:15:30.123456+00"
+	olderScrapedAt := "2026-02-27 08:00:00+00"
+	handler := NewHandler(&fakeService{
+		productDetail: func(
+			context.Context, string, snapshots.HistoryOptions,
+		) (snapshots.ProductDetail, error) {
+			return snapshots.ProductDetail{
+				ProductNameNormalized: "alpha",
+				Sellers: []snapshots.Seller{
+					{Seller: "tlamagase", LatestScrapedAt: &olderScrapedAt},
+					{Seller: "tlamagames", LatestScrapedAt: &scrapedAt},
+				},
+			}, nil
+		},
+	}, 200)
+
+	first := serveProductDetail(handler, nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Body.Len() == 0 {
+		t.Fatalf("unexpected first response: status=%d etag=%q", first.Code, etag)
+	}
+	if got := first.Header().Get("Last-Modified"); got != "Sun, 01 Mar 2026 10:15:30 GMT" {
+		t.Fatalf("unexpected Last-Modified: %q", got)
+	}
+	if got := first.Header().Get("Link"); got != `</api/v1/products/alpha>; rel="canonical"` {
+		t.Fatalf("unexpected canonical link: %q", got)
+	}
+
+	cases := []struct {
+		name   string
+		header http.Header
+		status int
+	}{
+		{"matching etag", http.Header{"If-None-Match": {`"stale", ` + etag}}, http.StatusNotModified},
+		{"weak etag", http.Header{"If-None-Match": {"W/" + etag}}, http.StatusNotModified},
+		{"stale etag", http.Header{"If-None-Match": {`"stale"`}}, http.StatusOK},
+		{"not modified since", http.Header{"If-Modified-Since": {"Sun, 01 Mar 2026 10:15:30 GMT"}}, http.StatusNotModified},
+		{"modified since", http.Header{"If-Modified-Since": {"Sun, 01 Mar 2026 10:15:29 GMT"}}, http.StatusOK},
+		{
+			"etag takes precedence",
+			http.Header{
+				"If-None-Match":     {`"stale"`},
+				"If-Modified-Since": {"Sun, 01 Mar 2026 10:15:30 GMT"},
+			},
+			http.StatusOK,
+		},
+	}
+	for _, tc := range cases {
+		recorder := serveProductDetail(handler, tc.header)
+		if recorder.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d", tc.name, tc.status, recorder.Code)
+		}
+		if tc.status == http.StatusNotModified && recorder.Body.Len() != 0 {
+			t.Fatalf("%s: expected empty 304 body", tc.name)
+		}
+		if recorder.Header().Get("ETag") != etag || recorder.Header().Get("Cache-Control") == "" {
+			t.Fatalf("%s: missing validators: %#v", tc.name, recorder.Header())
+		}
+	}
+}
+
+func TestParsePostgresTimestampAcceptsTextOffsets(t *testing.T) {
+	expected := time.Date(2026, 3, 1, 9, 15, 30, 0, time.UTC)
+	for _, raw := range []string{
+		"2026-03-01 10:15:30+01",
+		"2026-03-01 14:45:30+05:30",
+		"2026-03-01T09:15:30Z",
+	} {
+		parsed, ok := parsePostgresTimestamp(raw)
+		if !ok || !parsed.Equal(expected) {
+			t.Fatalf("unexpected timestamp for %q: %v, %t", raw, parsed, ok)
+		}
+	}
+	if _, ok := parsePostgresTimestamp("yesterday"); ok {
+		t.Fatal("expected invalid timestamp to fail")
+	}
+}
+
+func serveProductDetail(handler *Handler, header http.Header) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/products/alpha", nil)
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("slug", "alpha")
+	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routeContext))
+	recorder := httptest.NewRecorder()
+	handler.ProductDetail(recorder, request)
+	return recorder
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"tlamasite/apps/api-go/internal/feed"
+	"tlamasite/apps/api-go/internal/snapshots"
+)
+
+const (
+	defaultFeedItems = 50
+	feedLanguage     = "cs"
+	productPathBase  = "/deskove-hry/"
+)
+
+type feedService interface {
+	RecentDiscounts(ctx context.Context, filters snapshots.DiscountFilters) (snapshots.DiscountPage, error)
+	NewArrivals(ctx context.Context, filters snapshots.ArrivalFilters) ([]snapshots.NewArrival, error)
+}
+
+type feedRenderer func(feed.Feed) ([]byte, error)
+
+// FeedHandler serves RSS and Atom documents for feed readers and bots. Links
+// point at the public site, so it needs the site origin rather than the API
+// origin.
+type FeedHandler struct {
+	service feedService
+	siteURL string
+}
+
+func NewFeedHandler(service feedService, siteURL string) *FeedHandler {
+	return &FeedHandler{service: service, siteURL: strings.TrimRight(siteURL, "/")}
+}
+
+func (h *FeedHandler) DiscountsRSS(w http.ResponseWriter, r *http.Request) {
+	h.discounts(w, r, feed.RSS, feed.RSSContentType)
+}
+
+func (h *FeedHandler) DiscountsAtom(w http.ResponseWriter, r *http.Request) {
+	h.discounts(w, r, feed.Atom, feed.AtomContentType)
+}
+
+func (h *FeedHandler) NewArrivalsRSS(w http.ResponseWriter, r *http.Request) {
+	h.newArrivals(w, r, feed.RSS, feed.RSSContentType)
+}
+
+func (h *FeedHandler) NewArrivalsAtom(w http.ResponseWriter, r *http.Request) {
+	h.newArrivals(w, r, feed.Atom, feed.AtomContentType)
+}
+
+func (h *FeedHandler) discounts(w http.ResponseWriter, r *http.Request, render feedRenderer, contentType string) {
+	sellers, categories, limit, validationErr := parseFeedFilters(r.URL.Query())
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	page, err := h.service.RecentDiscounts(r.Context(), snapshots.DiscountFilters{
+		Limit:        limit,
+		HideInflated: true,
+		Sellers:      sellers,
+		Categories:   categories,
+		Sort:         snapshots.DiscountSortRecent,
+	})
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	items := make([]feed.Item, 0, len(page.Rows))
+	for _, row := range page.Rows {
+		items = append(items, h.discountItem(row))
+	}
+	h.writeFeed(w, r, render, contentType, feed.Feed{
+		Title:       "Deskovky Levně – slevy",
+		Description: "Nejnovější ověřené slevy deskových her podle prodejců.",
+		Link:        h.siteURL + "/levne-deskovky",
+		Items:       items,
+	})
+}
+
+func (h *FeedHandler) newArrivals(w http.ResponseWriter, r *http.Request, render feedRenderer, contentType string) {
+	sellers, categories, limit, validationErr := parseFeedFilters(r.URL.Query())
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	rows, err := h.service.NewArrivals(r.Context(), snapshots.ArrivalFilters{
+		Limit:      limit,
+		Sellers:    sellers,
+		Categories: categories,
+		WindowDays: snapshots.DefaultArrivalWindowDays,
+	})
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	items := make([]feed.Item, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, h.arrivalItem(row))
+	}
+	h.writeFeed(w, r, render, contentType, feed.Feed{
+		Title:       "Deskovky Levně – novinky",
+		Description: "Deskové hry, které prodejci nově zařadili do nabídky.",
+		Link:        h.siteURL + "/deskove-hry",
+		Items:       items,
+	})
+}
+
+func (h *FeedHandler) writeFeed(
+	w http.ResponseWriter,
+	r *http.Request,
+	render feedRenderer,
+	contentType string,
+	document feed.Feed,
+) {
+	document.SelfURL = h.siteURL + r.URL.RequestURI()
+	document.Language = feedLanguage
+	document.Updated = feed.Updated(document.Items).Truncate(time.Second)
+	body, err := render(document)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setPublicCache(w, 300, 600)
+	writeConditional(w, r, contentType, body, bodyETag(body), document.Updated)
+}
+
+// discountItem keys the GUID on the offer and its price pair, so readers see a
+// new item when a seller changes the price but not on every unchanged scrape.
+func (h *FeedHandler) discountItem(row snapshots.RecentDiscount) feed.Item {
+	name := stringOr(row.ProductName, row.ProductNameNormalized)
+	currency := stringOr(row.CurrencyCode, "")
+	current := formatFeedPrice(row.CurrentPrice)
+	title := fmt.Sprintf("%s za %s %s u %s", name, current, currency, row.Seller)
+	if row.DiscountPercent != nil {
+		title += fmt.Sprintf(" (−%s %%)", strconv.FormatFloat(*row.DiscountPercent, 'f', -1, 64))
+	}
+	item := feed.Item{
+		GUID:  feedGUID("discount", row.ProductNameNormalized, row.Seller, current, formatFeedPrice(row.ReferencePrice)),
+		Title: title,
+		Link:  h.productURL(row.ProductNameNormalized),
+		Description: fmt.Sprintf(
+			"Cena %s %s, dříve %s %s.",
+			current, currency, formatFeedPrice(row.ReferencePrice), currency,
+		),
+	}
+	if row.ChangedAt != nil {
+		if changedAt, ok := parsePostgresTimestamp(*row.ChangedAt); ok {
+			item.Published = changedAt
+		}
+	}
+	return item
+}
+
+func (h *FeedHandler) arrivalItem(row snapshots.NewArrival) feed.Item {
+	name := stringOr(row.ProductName, row.ProductNameNormalized)
+	price := formatFeedPrice(row.CurrentPrice)
+	currency := stringOr(row.CurrencyCode, "")
+	return feed.Item{
+		GUID:        feedGUID("new", row.ProductNameNormalized, row.Seller),
+		Title:       fmt.Sprintf("%s nově u %s", name, row.Seller),
+		Link:        h.productURL(row.ProductNameNormalized),
+		Description: fmt.Sprintf("Cena %s %s.", price, currency),
+		Published:   row.FirstSeenAt,
+	}
+}
+
+func (h *FeedHandler) productURL(slug string) string {
+	return h.siteURL + productPathBase + url.PathEscape(slug)
+}
+
+func parseFeedFilters(values url.Values) ([]string, []string, int, error) {
+	sellers, err := parseSellers(values.Get("seller"))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	categories, err := parseEnumList(values.Get("categories"), "categories", supportedCategories)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	limit, err := parseBoundedInt(values, "limit", defaultFeedItems, maxDiscountResults)
+	return sellers, categories, limit, err
+}
+
+func feedGUID(kind string, parts ...string) string {
+	escaped := make([]string, 0, len(parts)+2)
+	escaped = append(escaped, "urn:tlamasite", kind)
+	for _, part := range parts {
+		escaped = append(escaped, url.QueryEscape(part))
+	}
+	return strings.Join(escaped, ":")
+}
+
+func formatFeedPrice(price *float64) string {
+	if price == nil {
+		return "?"
+	}
+	return strconv.FormatFloat(*price, 'f', -1, 64)
+}
+
+func stringOr(value *string, fallback string) string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return fallback
+	}
+	return *value
+}
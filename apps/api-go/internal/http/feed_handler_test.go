@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tlamasite/apps/api-go/internal/snapshots"
+)
+
+func newFeedTestRouter(service *fakeService) http.Handler {
+	return NewRouter(NewHandler(service, 200), RouterOptions{
+		AllowedOrigin: "*",
+		Feeds:         NewFeedHandler(service, "https://example.test/"),
+	})
+}
+
+func TestDiscountFeedRendersRSSFromRecentDiscounts(t *testing.T) {
+	var captured snapshots.DiscountFilters
+	name := "Alpha"
+	currency := "CZK"
+	current, reference, percent := 799.0, 899.0, 11.12
+	changedAt := "2026-07-11 15:26:17+02"
+	router := newFeedTestRouter(&fakeService{
+		recentDiscounts: func(_ context.Context, filters snapshots.DiscountFilters) (snapshots.DiscountPage, error) {
+			captured = filters
+			return snapshots.DiscountPage{Rows: []snapshots.RecentDiscount{{
+				ProductNameNormalized: "alpha",
+				Seller:                "tlamagames",
+				ProductName:           &name,
+				CurrencyCode:          &currency,
+				CurrentPrice:          &current,
+				ReferencePrice:        &reference,
+				DiscountPercent:       &percent,
+				ChangedAt:             &changedAt,
+			}}}, nil
+		},
+	})
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(
+		http.MethodGet,
+		"/feeds/discounts.xml?seller=tlamagames&categories=rodinna",
+		nil,
+	))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if !captured.HideInflated || captured.Sort != snapshots.DiscountSortRecent ||
+		captured.Limit != defaultFeedItems || len(captured.Sellers) != 1 || len(captured.Categories) != 1 {
+		t.Fatalf("unexpected discount filters: %#v", captured)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/rss+xml") {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+	if lastModified := recorder.Header().Get("Last-Modified"); lastModified != "Sat, 11 Jul 2026 13:26:17 GMT" {
+		t.Fatalf("unexpected Last-Modified %q", lastModified)
+	}
+	body := recorder.Body.String()
+	for _, fragment := range []string{
+		`<guid isPermaLink="false">urn:tlamasite:discount:alpha:tlamagames:799:899</guid>`,
+		`<link>https://example.test/deskove-hry/alpha</link>`,
+		`href="https://example.test/feeds/discounts.xml?seller=tlamagames&amp;categories=rodinna"`,
+		`<title>Alpha za 799 CZK u tlamagames (−11.12 %)</title>`,
+	} {
+		if !strings.Contains(body, fragment) {
+			t.Fatalf("feed missing %q:\n%s", fragment, body)
+		}
+	}
+}
+
+func TestFeedsAnswerConditionalRequests(t *testing.T) {
+	firstSeen := time.Date(2026, 7, 10, 8, 0, 0, 0, time.UTC)
+	router := newFeedTestRouter(&fakeService{
+		newArrivals: func(_ context.Context, filters snapshots.ArrivalFilters) ([]snapshots.NewArrival, error) {
+			if filters.WindowDays != snapshots.DefaultArrivalWindowDays {
+				t.Fatalf("unexpected arrival window %d", filters.WindowDays)
+			}
+			return []snapshots.NewArrival{{
+				ProductNameNormalized: "beta",
+				Seller:                "planetaher",
+				FirstSeenAt:           firstSeen,
+			}}, nil
+		},
+	})
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/feeds/new.atom", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "application/atom+xml") ||
+		!strings.Contains(recorder.Body.String(), "<id>urn:tlamasite:new:beta:planetaher</id>") {
+		t.Fatalf("unexpected atom response: %s", recorder.Body.String())
+	}
+	etag := recorder.Header().Get("ETag")
+
+	for _, header := range []struct{ name, value string }{
+		{"If-None-Match", etag},
+		{"If-Modified-Since", firstSeen.Format(http.TimeFormat)},
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/feeds/new.atom", nil)
+		request.Header.Set(header.name, header.value)
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusNotModified || recorder.Body.Len() != 0 {
+			t.Fatalf("%s: expected empty 304, got %d", header.name, recorder.Code)
+		}
+	}
+}
+
+func TestFeedsRejectInvalidFilters(t *testing.T) {
+	router := newFeedTestRouter(&fakeService{})
+	for _, path := range []string{
+		"/feeds/discounts.atom?categories=unknown",
+		"/feeds/new.xml?seller=bad%20seller",
+	} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", path, recorder.Code)
+		}
+	}
+}
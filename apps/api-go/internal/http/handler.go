@@ -7,18 +7,30 @@ import (
 	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
+	"tlamasite/apps/api-go/internal/admin"
+	"tlamasite/apps/api-go/internal/alerts"
 	"tlamasite/apps/api-go/internal/catalog"
+	"tlamasite/apps/api-go/internal/collections"
+	"tlamasite/apps/api-go/internal/sellerstats"
 	"tlamasite/apps/api-go/internal/snapshots"
 )
 
 type serviceContract interface {
 	Catalog(ctx context.Context, filters catalog.Filters) ([]catalog.Row, int64, error)
 	CatalogOverview(ctx context.Context) (catalog.Overview, error)
+	Compare(ctx context.Context, slugs []string) (catalog.Comparison, error)
 	Search(ctx context.Context, query string, availability string, productCodes []string, limit int) ([]catalog.SuggestionRow, error)
-	ProductDetail(ctx context.Context, slug string, historyPoints int) (snapshots.ProductDetail, error)
-	RecentDiscounts(ctx context.Context, limit int) ([]snapshots.RecentDiscount, error)
+	ProductDetail(ctx context.Context, slug string, history snapshots.HistoryOptions) (encodedProductDetail, error)
+	ProductStats(ctx context.Context, slug string) (snapshots.ProductPriceStats, error)
+	ProductAvailability(ctx context.Context, slug string) (snapshots.ProductAvailability, error)
+	ProductPriceAt(ctx context.Context, slug string, date string) (snapshots.ProductPriceAt, error)
+	ResolveSlug(ctx context.Context, slug string) (snapshots.SlugResolution, error)
+	RecentDiscounts(ctx context.Context, filters snapshots.DiscountFilters) (snapshots.DiscountPage, error)
 	PriceRange(ctx context.Context, filters catalog.PriceRangeFilters) (catalog.PriceRange, error)
 	FilterOptions(ctx context.Context) (catalog.FilterOptions, error)
+	Sellers(ctx context.Context) ([]catalog.SellerSummary, error)
+	SellerStats(ctx context.Context, seller string) (sellerstats.SellerStats, error)
+	SellerFreshness(ctx context.Context) (catalog.FreshnessReport, error)
 	Ready(ctx context.Context) error
 }
 
@@ -54,6 +66,18 @@ func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
 }
 
+// Freshness reports sellers whose offers passed their staleness threshold. It
+// answers 200 either way so monitors can alert on the stale flag.
+func (h *Handler) Freshness(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.SellerFreshness(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, report)
+}
+
 func (h *Handler) Version(w http.ResponseWriter, _ *http.Request) {
 	writeJSON(w, http.StatusOK, h.buildInfo)
 }
@@ -80,6 +104,21 @@ func (h *Handler) Catalog(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *Handler) Compare(w http.ResponseWriter, r *http.Request) {
+	slugs, validationErr := parseCompareSlugs(r.URL.Query())
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	comparison, err := h.service.Compare(r.Context(), slugs)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setPublicCache(w, 60, 120)
+	writeJSON(w, http.StatusOK, comparison)
+}
+
 func (h *Handler) CatalogOverview(w http.ResponseWriter, r *http.Request) {
 	overview, err := h.service.CatalogOverview(r.Context())
 	if err != nil {
@@ -132,7 +171,7 @@ func (h *Handler) ProductDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	values := r.URL.Query()
-	historyPoints, validationErr := parseProductHistoryPoints(values)
+	history, validationErr := parseProductHistoryOptions(values)
 	if validationErr != nil {
 		writeValidationError(w, r, validationErr)
 		return
@@ -140,30 +179,97 @@ func (h *Handler) ProductDetail(w http.ResponseWriter, r *http.Request) {
 	detail, err := h.service.ProductDetail(
 		r.Context(),
 		slug,
-		historyPoints,
+		history,
 	)
 	if err != nil {
 		writeServiceError(w, r, err)
 		return
 	}
 	setPublicCache(w, 60, 300)
-	writeJSON(w, http.StatusOK, detail)
+	setCanonicalLink(w, detail.CanonicalSlug)
+	writeConditionalJSON(w, r, detail)
+}
+
+func (h *Handler) ResolveSlug(w http.ResponseWriter, r *http.Request) {
+	slug, validationErr := validateProductSlug(chi.URLParam(r, "slug"))
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	resolution, err := h.service.ResolveSlug(r.Context(), slug)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setPublicCache(w, 300, 600)
+	setCanonicalLink(w, resolution.Canonical)
+	writeJSON(w, http.StatusOK, resolution)
+}
+
+func (h *Handler) ProductStats(w http.ResponseWriter, r *http.Request) {
+	slug, validationErr := validateProductSlug(chi.URLParam(r, "slug"))
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	stats, err := h.service.ProductStats(r.Context(), slug)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setPublicCache(w, 60, 300)
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (h *Handler) ProductAvailability(w http.ResponseWriter, r *http.Request) {
+	slug, validationErr := validateProductSlug(chi.URLParam(r, "slug"))
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	availability, err := h.service.ProductAvailability(r.Context(), slug)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setPublicCache(w, 60, 300)
+	writeJSON(w, http.StatusOK, availability)
+}
+
+func (h *Handler) ProductPriceAt(w http.ResponseWriter, r *http.Request) {
+	slug, validationErr := validateProductSlug(chi.URLParam(r, "slug"))
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	date, validationErr := validateHistoryDate(chi.URLParam(r, "date"))
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	snapshot, err := h.service.ProductPriceAt(r.Context(), slug, date)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setPublicCache(w, 60, 300)
+	writeJSON(w, http.StatusOK, snapshot)
 }
 
 func (h *Handler) RecentDiscounts(w http.ResponseWriter, r *http.Request) {
 	values := r.URL.Query()
-	limit, validationErr := parseBoundedInt(values, "limit", 10, maxDiscountResults)
+	filters, validationErr := parseDiscountFilters(values)
 	if validationErr != nil {
 		writeValidationError(w, r, validationErr)
 		return
 	}
-	rows, err := h.service.RecentDiscounts(r.Context(), limit)
+	page, err := h.service.RecentDiscounts(r.Context(), filters)
 	if err != nil {
 		writeServiceError(w, r, err)
 		return
 	}
 	setPublicCache(w, 30, 60)
-	writeJSON(w, http.StatusOK, map[string]any{"rows": rows})
+	writeJSON(w, http.StatusOK, page)
 }
 
 func (h *Handler) PriceRange(w http.ResponseWriter, r *http.Request) {
@@ -192,11 +298,102 @@ func (h *Handler) FilterOptions(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, options)
 }
 
+func (h *Handler) Sellers(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.service.Sellers(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setPublicCache(w, 300, 600)
+	writeJSON(w, http.StatusOK, map[string]any{"rows": rows})
+}
+
+func (h *Handler) SellerStats(w http.ResponseWriter, r *http.Request) {
+	seller, validationErr := validateSeller(chi.URLParam(r, "seller"))
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	stats, err := h.service.SellerStats(r.Context(), seller)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setPublicCache(w, 300, 600)
+	writeJSON(w, http.StatusOK, stats)
+}
+
 func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
-	if snapshots.IsProductNotFound(err) {
+	if snapshots.IsProductNotFound(err) ||
+		errors.Is(err, alerts.ErrProductNotFound) ||
+		errors.Is(err, collections.ErrProductNotFound) {
 		writeErrorCode(w, r, http.StatusNotFound, "not_found", "product not found")
 		return
 	}
+	if errors.Is(err, alerts.ErrOfferNotFound) || errors.Is(err, alerts.ErrWatchNotFound) {
+		writeErrorCode(w, r, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+	if errors.Is(err, collections.ErrCollectionNotFound) {
+		writeErrorCode(w, r, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+	if errors.Is(err, collections.ErrInvalidEditToken) {
+		writeErrorCode(w, r, http.StatusForbidden, "forbidden", err.Error())
+		return
+	}
+	if errors.Is(err, collections.ErrCollectionFull) {
+		writeErrorCode(w, r, http.StatusConflict, "collection_full", err.Error())
+		return
+	}
+	if errors.Is(err, alerts.ErrTooManyWatches) {
+		writeErrorCode(w, r, http.StatusTooManyRequests, "too_many_watches", err.Error())
+		return
+	}
+	if errors.Is(err, alerts.ErrDeliveryFailed) {
+		writeErrorCode(
+			w,
+			r,
+			http.StatusServiceUnavailable,
+			"delivery_failed",
+			alerts.ErrDeliveryFailed.Error(),
+		)
+		return
+	}
+	if errors.Is(err, sellerstats.ErrSellerNotFound) || errors.Is(err, admin.ErrSellerNotFound) {
+		writeErrorCode(w, r, http.StatusNotFound, "not_found", "seller not found")
+		return
+	}
+	if errors.Is(err, admin.ErrWebhookNotFound) || errors.Is(err, admin.ErrWebhookDeliveryNotFound) {
+		writeErrorCode(w, r, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+	if errors.Is(err, admin.ErrCandidateNotFound) {
+		writeErrorCode(w, r, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+	if errors.Is(err, admin.ErrCandidateReviewed) {
+		writeErrorCode(w, r, http.StatusConflict, "candidate_reviewed", err.Error())
+		return
+	}
+	if errors.Is(err, admin.ErrAliasConflict) {
+		writeErrorCode(w, r, http.StatusConflict, "alias_conflict", err.Error())
+		return
+	}
+	if errors.Is(err, admin.ErrRefreshRunning) {
+		writeErrorCode(w, r, http.StatusConflict, "refresh_running", err.Error())
+		return
+	}
+	if errors.Is(err, sellerstats.ErrNotReady) {
+		writeErrorCode(
+			w,
+			r,
+			http.StatusServiceUnavailable,
+			"not_ready",
+			"seller stats are still being computed",
+		)
+		return
+	}
 	if errors.Is(err, context.DeadlineExceeded) {
 		writeErrorCode(w, r, http.StatusGatewayTimeout, "timeout", "request timed out")
 		return
@@ -77,12 +77,12 @@ func TestRecentDiscountsCapsLimit(t *testing.T) {
 	handler := NewHandler(&fakeService{
 		recentDiscounts: func(
 			_ context.Context,
-			limit int,
-		) ([]snapshots.RecentDiscount, error) {
-			if limit != maxDiscountResults {
-				t.Fatalf("expected capped limit %d, got %d", maxDiscountResults, limit)
+			filters snapshots.DiscountFilters,
+		) (snapshots.DiscountPage, error) {
+			if filters.Limit != maxDiscountResults {
+				t.Fatalf("expected capped limit %d, got %d", maxDiscountResults, filters.Limit)
 			}
-			return []snapshots.RecentDiscount{}, nil
+			return snapshots.DiscountPage{}, nil
 		},
 	}, 200)
 	recorder := httptest.NewRecorder()
@@ -96,6 +96,93 @@ func TestRecentDiscountsCapsLimit(t *testing.T) {
 	}
 }
 
+func TestRecentDiscountsParsesHideInflated(t *testing.T) {
+	var captured snapshots.DiscountFilters
+	handler := NewHandler(&fakeService{
+		recentDiscounts: func(
+			_ context.Context,
+			filters snapshots.DiscountFilters,
+		) (snapshots.DiscountPage, error) {
+			captured = filters
+			return snapshots.DiscountPage{}, nil
+		},
+	}, 200)
+	recorder := httptest.NewRecorder()
+
+	handler.RecentDiscounts(
+		recorder,
+		httptest.NewRequest(http.MethodGet, "/api/v1/discounts/recent?hide_inflated=true", nil),
+	)
+	if recorder.Code != http.StatusOK || !captured.HideInflated || captured.Limit != 10 {
+		t.Fatalf("unexpected discount filters: status=%d %#v", recorder.Code, captured)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.RecentDiscounts(
+		recorder,
+		httptest.NewRequest(http.MethodGet, "/api/v1/discounts/recent?hide_inflated=yes", nil),
+	)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid hide_inflated, got %d", recorder.Code)
+	}
+}
+
+func TestRecentDiscountsParsesFiltersSortAndCursor(t *testing.T) {
+	var captured snapshots.DiscountFilters
+	handler := NewHandler(&fakeService{
+		recentDiscounts: func(
+			_ context.Context,
+			filters snapshots.DiscountFilters,
+		) (snapshots.DiscountPage, error) {
+			captured = filters
+			return snapshots.DiscountPage{}, nil
+		},
+	}, 200)
+	cursor := snapshots.DiscountCursor{
+		Sort:            snapshots.DiscountSortDiscount,
+		DiscountPercent: 30,
+		Slug:            "alpha",
+		Seller:          "tlamagames",
+	}.Encode()
+	recorder := httptest.NewRecorder()
+
+	handler.RecentDiscounts(recorder, httptest.NewRequest(
+		http.MethodGet,
+		"/api/v1/discounts/recent?seller=TlamaGames,planetaher&categories=rodinna&availability=available"+
+			"&min_discount_percent=15&max_price=900&sort=discount&cursor="+cursor,
+		nil,
+	))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if len(captured.Sellers) != 2 || captured.Sellers[0] != "tlamagames" ||
+		len(captured.Categories) != 1 || captured.Availability != "available" ||
+		captured.MinDiscountPercent == nil || *captured.MinDiscountPercent != 15 ||
+		captured.MaxPrice == nil || *captured.MaxPrice != 900 ||
+		captured.Sort != snapshots.DiscountSortDiscount ||
+		captured.Cursor == nil || captured.Cursor.Slug != "alpha" {
+		t.Fatalf("unexpected discount filters: %#v", captured)
+	}
+
+	for _, query := range []string{
+		"?sort=cheapest",
+		"?sort=deal_score&cursor=" + cursor,
+		"?min_discount_percent=150",
+		"?seller=bad%20seller",
+		"?categories=unknown",
+		"?cursor=" + cursor,
+	} {
+		recorder = httptest.NewRecorder()
+		handler.RecentDiscounts(
+			recorder,
+			httptest.NewRequest(http.MethodGet, "/api/v1/discounts/recent"+query, nil),
+		)
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, recorder.Code)
+		}
+	}
+}
+
 func TestHandlerServiceErrorIsNotPubliclyCached(t *testing.T) {
 	handler := NewHandler(&fakeService{
 		catalogOverview: func(context.Context) (catalog.Overview, error) {
@@ -115,3 +202,39 @@ func TestHandlerServiceErrorIsNotPubliclyCached(t *testing.T) {
 		t.Fatalf("error response was cacheable: %q", cacheControl)
 	}
 }
+
+func TestResolveSlugSetsCanonicalLinkForAlias(t *testing.T) {
+	aliasKind := snapshots.AliasKindSlugOnly
+	router := NewRouter(NewHandler(&fakeService{
+		resolveSlug: func(_ context.Context, slug string) (snapshots.SlugResolution, error) {
+			if slug != "old-alpha" {
+				t.Fatalf("unexpected slug: %q", slug)
+			}
+			return snapshots.SlugResolution{
+				RequestedSlug: slug,
+				Canonical:     "alpha",
+				IsAlias:       true,
+				AliasKind:     &aliasKind,
+			}, nil
+		},
+	}, 200), RouterOptions{AllowedOrigin: "*"})
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(
+		recorder,
+		httptest.NewRequest(http.MethodGet, "/api/v1/slugs/Old-Alpha/resolve", nil),
+	)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Link"); got != `</api/v1/products/alpha>; rel="canonical"` {
+		t.Fatalf("unexpected canonical link: %q", got)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if payload["canonical"] != "alpha" || payload["is_alias"] != true || payload["alias_kind"] != "slug_only" {
+		t.Fatalf("unexpected payload: %#v", payload)
+	}
+}
@@ -9,15 +9,25 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"tlamasite/apps/api-go/internal/catalog"
+	"tlamasite/apps/api-go/internal/sellerstats"
 	"tlamasite/apps/api-go/internal/snapshots"
 )
 
 type fakeService struct {
 	catalog         func(ctx context.Context, filters catalog.Filters) ([]catalog.Row, int64, error)
 	catalogOverview func(ctx context.Context) (catalog.Overview, error)
+	compare         func(ctx context.Context, slugs []string) (catalog.Comparison, error)
+	sellers         func(ctx context.Context) ([]catalog.SellerSummary, error)
+	sellerStats     func(ctx context.Context, seller string) (sellerstats.SellerStats, error)
+	freshness       func(ctx context.Context) (catalog.FreshnessReport, error)
 	search          func(ctx context.Context, query string, availability string, productCodes []string, limit int) ([]catalog.SuggestionRow, error)
-	productDetail   func(ctx context.Context, slug string, historyPoints int) (snapshots.ProductDetail, error)
-	recentDiscounts func(ctx context.Context, limit int) ([]snapshots.RecentDiscount, error)
+	productDetail   func(ctx context.Context, slug string, history snapshots.HistoryOptions) (snapshots.ProductDetail, error)
+	productStats    func(ctx context.Context, slug string) (snapshots.ProductPriceStats, error)
+	availability    func(ctx context.Context, slug string) (snapshots.ProductAvailability, error)
+	priceAt         func(ctx context.Context, slug string, date string) (snapshots.ProductPriceAt, error)
+	resolveSlug     func(ctx context.Context, slug string) (snapshots.SlugResolution, error)
+	recentDiscounts func(ctx context.Context, filters snapshots.DiscountFilters) (snapshots.DiscountPage, error)
+	newArrivals     func(ctx context.Context, filters snapshots.ArrivalFilters) ([]snapshots.NewArrival, error)
 	priceRange      func(ctx context.Context, filters catalog.PriceRangeFilters) (catalog.PriceRange, error)
 	ready           func(ctx context.Context) error
 }
@@ -39,6 +49,13 @@ func (f *fakeService) CatalogOverview(ctx context.Context) (catalog.Overview, er
 	return catalog.Overview{}, nil
 }
 
+func (f *fakeService) Compare(ctx context.Context, slugs []string) (catalog.Comparison, error) {
+	if f.compare != nil {
+		return f.compare(ctx, slugs)
+	}
+	return catalog.Comparison{}, nil
+}
+
 func (f *fakeService) Search(
 	ctx context.Context,
 	query string,
@@ -55,20 +72,72 @@ func (f *fakeService) Search(
 func (f *fakeService) ProductDetail(
 	ctx context.Context,
 	slug string,
-	historyPoints int,
-) (snapshots.ProductDetail, error) {
+	history snapshots.HistoryOptions,
+) (encodedProductDetail, error) {
+	detail := snapshots.ProductDetail{}
 	if f.productDetail != nil {
-		return f.productDetail(ctx, slug, historyPoints)
+		var err error
+		if detail, err = f.productDetail(ctx, slug, history); err != nil {
+			return encodedProductDetail{}, err
+		}
+	}
+	return encodeProductDetail(detail)
+}
+
+func (f *fakeService) ProductStats(
+	ctx context.Context,
+	slug string,
+) (snapshots.ProductPriceStats, error) {
+	if f.productStats != nil {
+		return f.productStats(ctx, slug)
+	}
+	return snapshots.ProductPriceStats{}, nil
+}
+
+func (f *fakeService) ProductAvailability(
+	ctx context.Context,
+	slug string,
+) (snapshots.ProductAvailability, error) {
+	if f.availability != nil {
+		return f.availability(ctx, slug)
+	}
+	return snapshots.ProductAvailability{}, nil
+}
+
+func (f *fakeService) ProductPriceAt(
+	ctx context.Context,
+	slug string,
+	date string,
+) (snapshots.ProductPriceAt, error) {
+	if f.priceAt != nil {
+		return f.priceAt(ctx, slug, date)
+	}
+	return snapshots.ProductPriceAt{}, nil
+}
+
+func (f *fakeService) ResolveSlug(ctx context.Context, slug string) (snapshots.SlugResolution, error) {
+	if f.resolveSlug != nil {
+		return f.resolveSlug(ctx, slug)
 	}
-	return snapshots.ProductDetail{}, nil
+	return snapshots.SlugResolution{RequestedSlug: slug, Canonical: slug}, nil
 }
 
 func (f *fakeService) RecentDiscounts(
 	ctx context.Context,
-	limit int,
-) ([]snapshots.RecentDiscount, error) {
+	filters snapshots.DiscountFilters,
+) (snapshots.DiscountPage, error) {
 	if f.recentDiscounts != nil {
-		return f.recentDiscounts(ctx, limit)
+		return f.recentDiscounts(ctx, filters)
+	}
+	return snapshots.DiscountPage{}, nil
+}
+
+func (f *fakeService) NewArrivals(
+	ctx context.Context,
+	filters snapshots.ArrivalFilters,
+) ([]snapshots.NewArrival, error) {
+	if f.newArrivals != nil {
+		return f.newArrivals(ctx, filters)
 	}
 	return nil, nil
 }
@@ -87,6 +156,27 @@ func (f *fakeService) FilterOptions(_ context.Context) (catalog.FilterOptions, e
 	return catalog.StaticFilterOptions(), nil
 }
 
+func (f *fakeService) Sellers(ctx context.Context) ([]catalog.SellerSummary, error) {
+	if f.sellers != nil {
+		return f.sellers(ctx)
+	}
+	return []catalog.SellerSummary{}, nil
+}
+
+func (f *fakeService) SellerStats(ctx context.Context, seller string) (sellerstats.SellerStats, error) {
+	if f.sellerStats != nil {
+		return f.sellerStats(ctx, seller)
+	}
+	return sellerstats.SellerStats{}, sellerstats.ErrNotReady
+}
+
+func (f *fakeService) SellerFreshness(ctx context.Context) (catalog.FreshnessReport, error) {
+	if f.freshness != nil {
+		return f.freshness(ctx)
+	}
+	return catalog.FreshnessReport{Rows: []catalog.SellerFreshness{}}, nil
+}
+
 func (f *fakeService) Ready(ctx context.Context) error {
 	if f.ready != nil {
 		return f.ready(ctx)
@@ -215,21 +305,21 @@ func TestHandlerProductDetailValidationError(t *testing.T) {
 }
 
 func TestHandlerProductDetailParsesHistoryPoints(t *testing.T) {
-	var capturedHistoryPoints int
+	var capturedHistory snapshots.HistoryOptions
 	handler := NewHandler(&fakeService{
 		productDetail: func(
 			_ context.Context,
 			_ string,
-			historyPoints int,
+			history snapshots.HistoryOptions,
 		) (snapshots.ProductDetail, error) {
-			capturedHistoryPoints = historyPoints
+			capturedHistory = history
 			return snapshots.ProductDetail{}, nil
 		},
 	}, 200)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
-		"/api/v1/products/alpha-game?history_points=250",
+		"/api/v1/products/alpha-game?history_points=250&include_intraday=true",
 		nil,
 	)
 	routeCtx := chi.NewRouteContext()
@@ -242,8 +332,74 @@ func TestHandlerProductDetailParsesHistoryPoints(t *testing.T) {
 	if rec.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", rec.Code)
 	}
-	if capturedHistoryPoints != 250 {
-		t.Fatalf("expected historyPoints=250, got %d", capturedHistoryPoints)
+	if capturedHistory.Points != 250 || !capturedHistory.Intraday {
+		t.Fatalf("unexpected history options: %#v", capturedHistory)
+	}
+}
+
+func TestHandlerSellerStatsMapsReportState(t *testing.T) {
+	tests := []struct {
+		err            error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{nil, http.StatusOK, ""},
+		{sellerstats.ErrNotReady, http.StatusServiceUnavailable, "not_ready"},
+		{sellerstats.ErrSellerNotFound, http.StatusNotFound, "not_found"},
+	}
+	for _, testCase := range tests {
+		var capturedSeller string
+		router := NewRouter(NewHandler(&fakeService{
+			sellerStats: func(_ context.Context, seller string) (sellerstats.SellerStats, error) {
+				capturedSeller = seller
+				return sellerstats.SellerStats{Seller: seller}, testCase.err
+			},
+		}, 200), RouterOptions{AllowedOrigin: "*"})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/sellers/TlamaGames/stats", nil))
+
+		if rec.Code != testCase.expectedStatus {
+			t.Fatalf("%v: expected %d, got %d", testCase.err, testCase.expectedStatus, rec.Code)
+		}
+		if capturedSeller != "tlamagames" {
+			t.Fatalf("seller was not normalized: %q", capturedSeller)
+		}
+		if testCase.expectedCode == "" {
+			continue
+		}
+		var payload map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("decode error payload: %v", err)
+		}
+		if payload["code"] != testCase.expectedCode {
+			t.Fatalf("expected code %s, got %v", testCase.expectedCode, payload["code"])
+		}
+	}
+}
+
+func TestHandlerFreshnessIsNeverCached(t *testing.T) {
+	handler := NewHandler(&fakeService{
+		freshness: func(context.Context) (catalog.FreshnessReport, error) {
+			return catalog.FreshnessReport{
+				Stale: true,
+				Rows:  []catalog.SellerFreshness{{Seller: "planetaher", Stale: true, StaleOfferCount: 12}},
+			}, nil
+		},
+	}, 200)
+
+	rec := httptest.NewRecorder()
+	handler.Freshness(rec, httptest.NewRequest(http.MethodGet, "/freshness", nil))
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("unexpected response: %d %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+	var payload catalog.FreshnessReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if !payload.Stale || len(payload.Rows) != 1 || payload.Rows[0].StaleOfferCount != 12 {
+		t.Fatalf("unexpected payload: %#v", payload)
 	}
 }
 
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"tlamasite/apps/api-go/internal/bgg"
+)
+
+type importService interface {
+	Import(context.Context, []bgg.Entry) (bgg.Result, error)
+}
+
+// ImportHandler matches uploaded collection exports against the catalog. The
+// upload is parsed in memory and never stored.
+type ImportHandler struct {
+	service importService
+}
+
+func NewImportHandler(service importService) *ImportHandler {
+	return &ImportHandler{service: service}
+}
+
+func (h *ImportHandler) BoardGameGeek(w http.ResponseWriter, r *http.Request) {
+	data, validationErr := readImportUpload(w, r)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	entries, validationErr := bgg.ParseExport(data)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	result, err := h.service.Import(r.Context(), entries)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, result)
+}
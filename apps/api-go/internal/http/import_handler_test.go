@@ -0,0 +1,85 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tlamasite/apps/api-go/internal/bgg"
+)
+
+type fakeImportService struct {
+	entries []bgg.Entry
+}
+
+func (f *fakeImportService) Import(_ context.Context, entries []bgg.Entry) (bgg.Result, error) {
+	f.entries = entries
+	return bgg.Result{Matched: []bgg.Match{}, Unmatched: []bgg.Missing{}}, nil
+}
+
+func serveImport(service importService, contentType string, body []byte) *httptest.ResponseRecorder {
+	router := NewRouter(NewHandler(&fakeService{}, 200), RouterOptions{
+		AllowedOrigin: "*",
+		Imports:       NewImportHandler(service),
+	})
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/imports/bgg", bytes.NewReader(body))
+	request.Header.Set("Content-Type", contentType)
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestImportAcceptsRawAndMultipartExports(t *testing.T) {
+	service := &fakeImportService{}
+	recorder := serveImport(service, "text/csv", []byte("objectname,objectid\nAzul,230802\n"))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("expected uncached 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if len(service.entries) != 1 || service.entries[0].ID != 230802 {
+		t.Fatalf("unexpected entries %#v", service.entries)
+	}
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	_ = writer.WriteField("note", "ignored")
+	file, _ := writer.CreateFormFile("file", "collection.xml")
+	_, _ = file.Write([]byte(`<items><item objectid="822"><name>Carcassonne</name></item></items>`))
+	_ = writer.Close()
+	service = &fakeImportService{}
+	recorder = serveImport(service, writer.FormDataContentType(), form.Bytes())
+	if recorder.Code != http.StatusOK || len(service.entries) != 1 || service.entries[0].Name != "Carcassonne" {
+		t.Fatalf("expected multipart upload to parse, got %d %#v", recorder.Code, service.entries)
+	}
+}
+
+func TestImportRejectsUnusableUploads(t *testing.T) {
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	_ = writer.WriteField("upload", "objectname\nAzul\n")
+	_ = writer.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		message     string
+	}{
+		{"missing file field", writer.FormDataContentType(), form.Bytes(), `\"file\"`},
+		{"too large", "text/csv", bytes.Repeat([]byte("a"), maxImportBytes+1), "must not exceed 4 MiB"},
+		{"not an export", "application/json", []byte(`{"slugs":["azul"]}`), "not a valid CSV"},
+	}
+	for _, testCase := range tests {
+		service := &fakeImportService{}
+		recorder := serveImport(service, testCase.contentType, testCase.body)
+		if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), testCase.message) {
+			t.Fatalf("%s: expected 400 mentioning %q, got %d: %s", testCase.name, testCase.message, recorder.Code, recorder.Body.String())
+		}
+		if service.entries != nil {
+			t.Fatalf("%s: service must not be called", testCase.name)
+		}
+	}
+}
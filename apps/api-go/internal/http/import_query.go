@@ -0,0 +1,60 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+)
+
+// maxImportBytes allows a few thousand CSV rows with every BGG column.
+const maxImportBytes = 4 << 20
+
+const importFileField = "file"
+
+// readImportUpload accepts the export either as the raw request body or as the
+// "file" field of a multipart form, which is what a plain HTML upload sends.
+func readImportUpload(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+	var source io.Reader = body
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		part, err := importFilePart(multipart.NewReader(body, params["boundary"]))
+		if err != nil {
+			return nil, uploadError(err)
+		}
+		defer part.Close()
+		source = part
+	}
+	data, err := io.ReadAll(source)
+	if err != nil {
+		return nil, uploadError(err)
+	}
+	return data, nil
+}
+
+func importFilePart(reader *multipart.Reader) (*multipart.Part, error) {
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("form must include a %q file", importFileField)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == importFileField {
+			return part, nil
+		}
+		_ = part.Close()
+	}
+}
+
+func uploadError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return fmt.Errorf("export must not exceed %d MiB", maxImportBytes>>20)
+	}
+	return fmt.Errorf("export upload could not be read: %w", err)
+}
@@ -4,16 +4,20 @@ import (
 	"fmt"
 	"math"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"tlamasite/apps/api-go/internal/catalog"
+	"tlamasite/apps/api-go/internal/snapshots"
 )
 
 const (
 	maxHistoryPoints   = 5000
 	maxDiscountResults = 100
+	maxDiscountSellers = 20
 	maxSearchLength    = 120
 	maxCatalogOffset   = 1_000_000
 	maxProductCodes    = 200
@@ -26,6 +30,12 @@ var supportedPlayerRanges = stringSet("1-2", "2-4", "4-plus")
 var supportedPlaytimeRanges = stringSet("under-30", "30-60", "60-plus")
 var supportedAgeRatings = stringSet("6", "8", "10", "12")
 var supportedPriceMovements = stringSet("decreased")
+var supportedDiscountSorts = stringSet(
+	snapshots.DiscountSortRecent,
+	snapshots.DiscountSortDiscount,
+	snapshots.DiscountSortDeal,
+)
+var sellerPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,99}$`)
 
 type commonFilters struct {
 	availability   string
@@ -75,9 +85,15 @@ func parseCatalogFilters(
 	if err != nil {
 		return catalog.Filters{}, err
 	}
-	return buildCatalogFilters(
+	includeTrend, err := parseOptionalBool(values, "include_trend")
+	if err != nil {
+		return catalog.Filters{}, err
+	}
+	filters := buildCatalogFilters(
 		common, minPrice, maxPrice, limit, offset, randomSeed, query, productCodes,
-	), nil
+	)
+	filters.IncludeTrend = includeTrend
+	return filters, nil
 }
 
 func buildCatalogFilters(
@@ -275,6 +291,118 @@ func parseProductHistoryPoints(values url.Values) (int, error) {
 	return parseBoundedInt(values, "history_points", 0, maxHistoryPoints)
 }
 
+func parseDiscountFilters(values url.Values) (snapshots.DiscountFilters, error) {
+	limit, err := parseBoundedInt(values, "limit", 10, maxDiscountResults)
+	if err != nil {
+		return snapshots.DiscountFilters{}, err
+	}
+	hideInflated, err := parseOptionalBool(values, "hide_inflated")
+	if err != nil {
+		return snapshots.DiscountFilters{}, err
+	}
+	sellers, err := parseSellers(values.Get("seller"))
+	if err != nil {
+		return snapshots.DiscountFilters{}, err
+	}
+	categories, err := parseEnumList(values.Get("categories"), "categories", supportedCategories)
+	if err != nil {
+		return snapshots.DiscountFilters{}, err
+	}
+	availability, err := parseOptionalEnum(values.Get("availability"), "availability", supportedAvailabilities)
+	if err != nil {
+		return snapshots.DiscountFilters{}, err
+	}
+	minDiscount, err := parseDiscountPercent(values, "min_discount_percent")
+	if err != nil {
+		return snapshots.DiscountFilters{}, err
+	}
+	maxPrice, err := parsePrice(values, "max_price")
+	if err != nil {
+		return snapshots.DiscountFilters{}, err
+	}
+	sort, err := parseOptionalEnum(values.Get("sort"), "sort", supportedDiscountSorts)
+	if err != nil {
+		return snapshots.DiscountFilters{}, err
+	}
+	if sort == "" {
+		sort = snapshots.DiscountSortRecent
+	}
+	filters := snapshots.DiscountFilters{
+		Limit:              limit,
+		HideInflated:       hideInflated,
+		Sellers:            sellers,
+		Categories:         categories,
+		Availability:       availability,
+		MinDiscountPercent: minDiscount,
+		MaxPrice:           maxPrice,
+		Sort:               sort,
+	}
+	if rawCursor := strings.TrimSpace(values.Get("cursor")); rawCursor != "" {
+		cursor, err := snapshots.DecodeDiscountCursor(rawCursor, sort)
+		if err != nil {
+			return snapshots.DiscountFilters{}, err
+		}
+		filters.Cursor = &cursor
+	}
+	return filters, nil
+}
+
+func parseSellers(raw string) ([]string, error) {
+	sellers := parseList(raw)
+	if len(sellers) > maxDiscountSellers {
+		return nil, fmt.Errorf("seller must not contain more than %d values", maxDiscountSellers)
+	}
+	for _, seller := range sellers {
+		if !sellerPattern.MatchString(seller) {
+			return nil, fmt.Errorf("unsupported seller value %q", seller)
+		}
+	}
+	return sellers, nil
+}
+
+func parseDiscountPercent(values url.Values, key string) (*float64, error) {
+	percent, err := parsePrice(values, key)
+	if err != nil || percent == nil {
+		return nil, err
+	}
+	if *percent > 100 {
+		return nil, fmt.Errorf("%s must be between 0 and 100", key)
+	}
+	return percent, nil
+}
+
+func validateSeller(raw string) (string, error) {
+	seller := strings.ToLower(strings.TrimSpace(raw))
+	if !sellerPattern.MatchString(seller) {
+		return "", fmt.Errorf("seller must be a lowercase seller identifier")
+	}
+	return seller, nil
+}
+
+func parseProductHistoryOptions(values url.Values) (snapshots.HistoryOptions, error) {
+	points, err := parseProductHistoryPoints(values)
+	if err != nil {
+		return snapshots.HistoryOptions{}, err
+	}
+	intraday, err := parseOptionalBool(values, "include_intraday")
+	if err != nil {
+		return snapshots.HistoryOptions{}, err
+	}
+	return snapshots.HistoryOptions{Points: points, Intraday: intraday}, nil
+}
+
+func parseOptionalBool(values url.Values, key string) (bool, error) {
+	raw := strings.TrimSpace(values.Get(key))
+	if raw == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean", key)
+	}
+	return parsed, nil
+}
+
 func validateSearchQuery(raw string) (string, error) {
 	query := strings.TrimSpace(raw)
 	if utf8.RuneCountInString(query) > maxSearchLength {
@@ -294,6 +422,33 @@ func validateProductSlug(raw string) (string, error) {
 	return slug, nil
 }
 
+func parseCompareSlugs(values url.Values) ([]string, error) {
+	slugs := parseList(values.Get("slugs"))
+	if len(slugs) < catalog.MinCompareSlugs || len(slugs) > catalog.MaxCompareSlugs {
+		return nil, fmt.Errorf(
+			"slugs must list between %d and %d distinct products",
+			catalog.MinCompareSlugs,
+			catalog.MaxCompareSlugs,
+		)
+	}
+	for index, raw := range slugs {
+		slug, err := validateProductSlug(raw)
+		if err != nil {
+			return nil, err
+		}
+		slugs[index] = slug
+	}
+	return slugs, nil
+}
+
+func validateHistoryDate(raw string) (string, error) {
+	parsed, err := time.Parse(time.DateOnly, strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("date must use YYYY-MM-DD format")
+	}
+	return parsed.Format(time.DateOnly), nil
+}
+
 func stringSet(values ...string) map[string]struct{} {
 	result := make(map[string]struct{}, len(values))
 	for _, value := range values {
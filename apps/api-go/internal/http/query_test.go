@@ -23,6 +23,20 @@ func TestParseProductHistoryPointsCap(t *testing.T) {
 	}
 }
 
+func TestParseProductHistoryOptionsIntradayIsOptIn(t *testing.T) {
+	compact, err := parseProductHistoryOptions(url.Values{})
+	if err != nil || compact.Intraday {
+		t.Fatalf("intraday must default to false: %#v, err=%v", compact, err)
+	}
+	extended, err := parseProductHistoryOptions(url.Values{"include_intraday": []string{"true"}})
+	if err != nil || !extended.Intraday {
+		t.Fatalf("expected intraday history, got %#v, err=%v", extended, err)
+	}
+	if _, err := parseProductHistoryOptions(url.Values{"include_intraday": []string{"maybe"}}); err == nil {
+		t.Fatal("expected validation error for non-boolean include_intraday")
+	}
+}
+
 func TestParseListNormalizesAndDeduplicates(t *testing.T) {
 	got := parseList("2-4, 4-PLUS,2-4,,")
 	if len(got) != 2 || got[0] != "2-4" || got[1] != "4-plus" {
@@ -44,6 +58,7 @@ func TestCatalogValidationRejectsInvalidValues(t *testing.T) {
 		{"random_seed": []string{"invalid"}},
 		{"q": []string{strings.Repeat("a", maxSearchLength+1)}},
 		{"product_codes": []string{strings.Repeat("x", maxProductCodeSize+1)}},
+		{"include_trend": []string{"maybe"}},
 	}
 	for _, values := range cases {
 		if _, err := parseCatalogFilters(values, 200); err == nil {
@@ -76,12 +91,13 @@ func TestCatalogValidationParsesSupportedValues(t *testing.T) {
 		"limit":          []string{"24"},
 		"offset":         []string{"48"},
 		"random_seed":    []string{"987"},
+		"include_trend":  []string{"true"},
 	}
 	filters, err := parseCatalogFilters(values, 200)
 	if err != nil {
 		t.Fatalf("unexpected validation error: %v", err)
 	}
-	if filters.Limit != 24 || filters.Offset != 48 || filters.RandomSeed == nil {
+	if filters.Limit != 24 || filters.Offset != 48 || filters.RandomSeed == nil || !filters.IncludeTrend {
 		t.Fatalf("unexpected filters: %#v", filters)
 	}
 }
@@ -167,3 +183,26 @@ func TestProductHistoryPointsRejectsNegativeValue(t *testing.T) {
 		t.Fatal("expected negative history_points to fail")
 	}
 }
+
+func TestValidateHistoryDateRequiresCalendarDate(t *testing.T) {
+	if got, err := validateHistoryDate(" 2026-03-01 "); err != nil || got != "2026-03-01" {
+		t.Fatalf("expected valid date, got %q, err=%v", got, err)
+	}
+	for _, raw := range []string{"", "2026-3-1", "2026-02-30", "01.03.2026"} {
+		if _, err := validateHistoryDate(raw); err == nil {
+			t.Fatalf("expected %q to fail", raw)
+		}
+	}
+}
+
+func TestParseCompareSlugsRequiresTwoToSixDistinctSlugs(t *testing.T) {
+	slugs, err := parseCompareSlugs(url.Values{"slugs": []string{" Alpha,beta ,alpha"}})
+	if err != nil || len(slugs) != 2 || slugs[0] != "alpha" || slugs[1] != "beta" {
+		t.Fatalf("unexpected slugs: %#v, err=%v", slugs, err)
+	}
+	for _, raw := range []string{"", "alpha", "a,b,c,d,e,f,g", "alpha," + strings.Repeat("x", 201)} {
+		if _, err := parseCompareSlugs(url.Values{"slugs": []string{raw}}); err == nil {
+			t.Fatalf("expected %q to fail", raw)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/go-chi/chi/v5/middleware"
 )
@@ -19,6 +20,18 @@ func setPublicCache(w http.ResponseWriter, maxAgeSeconds int, staleSeconds int)
 	)
 }
 
+// setCanonicalLink points clients and crawlers at the canonical product
+// resource, so alias slugs can be redirected without comparing payload fields.
+func setCanonicalLink(w http.ResponseWriter, canonicalSlug string) {
+	if canonicalSlug == "" {
+		return
+	}
+	w.Header().Set(
+		"Link",
+		fmt.Sprintf(`</api/v1/products/%s>; rel="canonical"`, url.PathEscape(canonicalSlug)),
+	)
+}
+
 func writeJSON(w http.ResponseWriter, status int, payload any) {
 	encodedPayload, err := json.Marshal(payload)
 	if err != nil {
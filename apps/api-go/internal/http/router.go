@@ -9,23 +9,41 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+
+	"tlamasite/apps/api-go/internal/admin"
 )
 
 type RouteTimeouts struct {
-	Health     time.Duration
-	Ready      time.Duration
-	Catalog    time.Duration
-	Search     time.Duration
-	Product    time.Duration
-	Discounts  time.Duration
-	Metadata   time.Duration
-	PriceRange time.Duration
+	Health      time.Duration
+	Ready       time.Duration
+	Catalog     time.Duration
+	Search      time.Duration
+	Product     time.Duration
+	Discounts   time.Duration
+	Metadata    time.Duration
+	PriceRange  time.Duration
+	Admin       time.Duration
+	Watches     time.Duration
+	Collections time.Duration
+	Imports     time.Duration
+	AliasReview time.Duration
 }
 
 type RouterOptions struct {
 	AllowedOrigin     string
 	Timeouts          RouteTimeouts
 	TrustedProxyCIDRs []netip.Prefix
+	// Admin is nil when no admin tokens are configured; admin routes then 404.
+	Admin *AdminHandler
+	// SeparateAdminV1 leaves /admin/v1 off this router because NewAdminRouter
+	// serves it on its own listener.
+	SeparateAdminV1 bool
+
+	Feeds *FeedHandler
+	// Watches is nil when price alerts are not configured.
+	Watches     *WatchHandler
+	Collections *CollectionHandler
+	Imports     *ImportHandler
 }
 
 func NewRouter(handler *Handler, options RouterOptions) http.Handler {
@@ -34,39 +52,199 @@ func NewRouter(handler *Handler, options RouterOptions) http.Handler {
 	router.Use(middleware.RequestID)
 	router.Use(middleware.Recoverer)
 	router.Use(requestLogger)
-	router.Use(middleware.Compress(5, "application/json"))
+	router.Use(middleware.Compress(5, "application/json", "application/rss+xml", "application/atom+xml"))
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins: []string{options.AllowedOrigin},
 		AllowedMethods: []string{
 			http.MethodGet,
 			http.MethodHead,
+			http.MethodPost,
+			http.MethodDelete,
 			http.MethodOptions,
 		},
 		AllowedHeaders: []string{"Accept", "Content-Type", "Authorization"},
+		ExposedHeaders: []string{"Link"},
 		MaxAge:         300,
 	}))
 	timeouts := options.Timeouts
 	withRouteTimeout(router, timeouts.Health, "/health", handler.Health)
 	withRouteTimeout(router, timeouts.Ready, "/ready", handler.Ready)
 	withRouteTimeout(router, timeouts.Health, "/version", handler.Version)
+	withRouteTimeout(router, timeouts.Ready, "/freshness", handler.Freshness)
+	if options.Feeds != nil {
+		mountFeedRoutes(router, options.Feeds, timeouts)
+	}
+	if options.Admin != nil && len(options.Admin.tokens) > 0 && !options.SeparateAdminV1 {
+		router.Route("/admin/v1", func(adminRouter chi.Router) {
+			mountAdminV1Routes(adminRouter, options.Admin, timeouts)
+		})
+	}
 	router.Route("/api/v1", func(r chi.Router) {
 		withRouteTimeout(r, timeouts.Catalog, "/catalog", handler.Catalog)
 		withRouteTimeout(r, timeouts.Catalog, "/catalog/overview", handler.CatalogOverview)
 		withRouteTimeout(r, timeouts.Search, "/search/suggest", handler.SearchSuggest)
 		withRouteTimeout(r, timeouts.Product, "/products/{slug}", handler.ProductDetail)
+		withRouteTimeout(r, timeouts.Product, "/products/{slug}/stats", handler.ProductStats)
+		withRouteTimeout(
+			r, timeouts.Product, "/products/{slug}/availability", handler.ProductAvailability,
+		)
+		withRouteTimeout(r, timeouts.Product, "/products/{slug}/at/{date}", handler.ProductPriceAt)
+		withRouteTimeout(r, timeouts.Product, "/slugs/{slug}/resolve", handler.ResolveSlug)
+		withRouteTimeout(r, timeouts.Product, "/compare", handler.Compare)
 		withRouteTimeout(r, timeouts.Discounts, "/discounts/recent", handler.RecentDiscounts)
 		withRouteTimeout(r, timeouts.PriceRange, "/meta/price-range", handler.PriceRange)
 		withRouteTimeout(r, timeouts.Metadata, "/meta/filter-options", handler.FilterOptions)
+		withRouteTimeout(r, timeouts.Metadata, "/sellers", handler.Sellers)
+		withRouteTimeout(r, timeouts.Metadata, "/sellers/{seller}/stats", handler.SellerStats)
+		if options.Watches != nil {
+			mountWatchRoutes(r, options.Watches, timeouts)
+		}
+		if options.Collections != nil {
+			mountCollectionRoutes(r, options.Collections, timeouts)
+		}
+		if options.Imports != nil {
+			withMethodTimeout(r, http.MethodPost, timeouts.Imports, "/imports/bgg", options.Imports.BoardGameGeek)
+		}
+		if options.Admin != nil && len(options.Admin.tokenHashes) > 0 {
+			r.Route("/admin", func(adminRouter chi.Router) {
+				mountAdminRoutes(adminRouter, options.Admin, timeouts)
+			})
+		}
+	})
+	return router
+}
+
+// NewAdminRouter serves only /admin/v1, for an admin listener that is not
+// exposed with the public API. It skips CORS and compression; admin tooling
+// does not run in browsers.
+func NewAdminRouter(handler *AdminHandler, options RouterOptions) http.Handler {
+	router := chi.NewRouter()
+	router.Use(trustedClientIP(options.TrustedProxyCIDRs))
+	router.Use(middleware.RequestID)
+	router.Use(middleware.Recoverer)
+	router.Use(requestLogger)
+	router.Route("/admin/v1", func(adminRouter chi.Router) {
+		mountAdminV1Routes(adminRouter, handler, options.Timeouts)
 	})
 	return router
 }
 
+func mountFeedRoutes(router chi.Router, handler *FeedHandler, timeouts RouteTimeouts) {
+	withRouteTimeout(router, timeouts.Discounts, "/feeds/discounts.xml", handler.DiscountsRSS)
+	withRouteTimeout(router, timeouts.Discounts, "/feeds/discounts.atom", handler.DiscountsAtom)
+	withRouteTimeout(router, timeouts.Discounts, "/feeds/new.xml", handler.NewArrivalsRSS)
+	withRouteTimeout(router, timeouts.Discounts, "/feeds/new.atom", handler.NewArrivalsAtom)
+}
+
+func mountWatchRoutes(router chi.Router, handler *WatchHandler, timeouts RouteTimeouts) {
+	withMethodTimeout(router, http.MethodPost, timeouts.Watches, "/watches", handler.Create)
+	withMethodTimeout(router, http.MethodPost, timeouts.Watches, "/watches/confirm", handler.Confirm)
+	withMethodTimeout(router, http.MethodDelete, timeouts.Watches, "/watches", handler.Delete)
+}
+
+func mountCollectionRoutes(router chi.Router, handler *CollectionHandler, timeouts RouteTimeouts) {
+	timeout := timeouts.Collections
+	withMethodTimeout(router, http.MethodPost, timeout, "/collections", handler.Create)
+	withRouteTimeout(router, timeout, "/collections/{id}", handler.Get)
+	withMethodTimeout(router, http.MethodDelete, timeout, "/collections/{id}", handler.Delete)
+	withMethodTimeout(router, http.MethodPost, timeout, "/collections/{id}/items", handler.AddItem)
+	withMethodTimeout(router, http.MethodDelete, timeout, "/collections/{id}/items", handler.RemoveItem)
+}
+
+// mountAdminRoutes keeps the original read-only admin group for unscoped
+// tokens. Mutations are served only by /admin/v1, where they are scoped and
+// audited.
+func mountAdminRoutes(router chi.Router, handler *AdminHandler, timeouts RouteTimeouts) {
+	router.Use(requireAdminToken(handler.tokenHashes))
+	withRouteTimeout(
+		router,
+		timeouts.Admin,
+		"/products/{slug}/sellers/{seller}/snapshots",
+		handler.SellerSnapshots,
+	)
+	withRouteTimeout(router, timeouts.Admin, "/sellers", handler.Sellers)
+	withRouteTimeout(router, timeouts.Admin, "/webhooks", handler.Webhooks)
+	withRouteTimeout(router, timeouts.Admin, "/webhooks/{id}/deliveries", handler.WebhookDeliveries)
+}
+
+// mountAdminV1Routes exposes the admin endpoints behind named tokens. Each
+// route names the scope it needs; mutations are audited even when refused.
+func mountAdminV1Routes(router chi.Router, handler *AdminHandler, timeouts RouteTimeouts) {
+	router.Use(requireScopedAdminToken(handler.tokens))
+	router.Use(auditMutations(handler.repository))
+	route := func(method string, scope string, pattern string, handlerFunc http.HandlerFunc) {
+		withMethodTimeout(router.With(requireScope(scope)), method, timeouts.Admin, pattern, handlerFunc)
+	}
+	route(
+		http.MethodGet,
+		admin.ScopeSnapshotsRead,
+		"/products/{slug}/sellers/{seller}/snapshots",
+		handler.SellerSnapshots,
+	)
+	route(http.MethodGet, admin.ScopeSellersRead, "/sellers", handler.Sellers)
+	route(http.MethodPut, admin.ScopeSellersWrite, "/sellers/{seller}", handler.SaveSeller)
+	route(http.MethodDelete, admin.ScopeSellersWrite, "/sellers/{seller}", handler.DeleteSeller)
+	route(http.MethodGet, admin.ScopeWebhooksRead, "/webhooks", handler.Webhooks)
+	route(http.MethodPost, admin.ScopeWebhooksWrite, "/webhooks", handler.CreateWebhook)
+	route(http.MethodDelete, admin.ScopeWebhooksWrite, "/webhooks/{id}", handler.DeleteWebhook)
+	route(http.MethodGet, admin.ScopeWebhooksRead, "/webhooks/{id}/deliveries", handler.WebhookDeliveries)
+	route(
+		http.MethodPost,
+		admin.ScopeWebhooksWrite,
+		"/webhooks/deliveries/{id}/retry",
+		handler.RetryWebhookDelivery,
+	)
+	route(http.MethodGet, admin.ScopeAuditRead, "/audit", handler.AuditLog)
+	route(http.MethodGet, admin.ScopeAliasesRead, "/aliases/candidates", handler.AliasCandidates)
+
+	// Approval rebuilds history and refreshes catalog state before answering,
+	// which can outlast both the admin timeout and the server write timeout.
+	review := router.With(requireScope(admin.ScopeAliasesWrite), extendWriteDeadline(timeouts.AliasReview))
+	withMethodTimeout(
+		review,
+		http.MethodPost,
+		timeouts.AliasReview,
+		"/aliases/candidates/{id}/approve",
+		handler.ApproveAliasCandidate,
+	)
+	withMethodTimeout(
+		review,
+		http.MethodPost,
+		timeouts.AliasReview,
+		"/aliases/candidates/{id}/reject",
+		handler.RejectAliasCandidate,
+	)
+}
+
+// extendWriteDeadline lets a slow route write its response after the server
+// WriteTimeout. Writers that cannot move the deadline keep the server one.
+func extendWriteDeadline(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if timeout > 0 {
+				_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout + time.Second))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func withRouteTimeout(router chi.Router, timeout time.Duration, pattern string, handler http.HandlerFunc) {
+	withMethodTimeout(router, http.MethodGet, timeout, pattern, handler)
+}
+
+func withMethodTimeout(
+	router chi.Router,
+	method string,
+	timeout time.Duration,
+	pattern string,
+	handler http.HandlerFunc,
+) {
 	if timeout <= 0 {
-		router.Get(pattern, handler)
+		router.Method(method, pattern, handler)
 		return
 	}
-	router.With(middleware.Timeout(timeout)).Get(pattern, handler)
+	router.With(middleware.Timeout(timeout)).Method(method, pattern, handler)
 }
 
 func requestLogger(next http.Handler) http.Handler {
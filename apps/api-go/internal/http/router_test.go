@@ -21,9 +21,21 @@ func TestRouterExposesCurrentEndpointsOnly(t *testing.T) {
 		{"/health", http.StatusOK},
 		{"/ready", http.StatusOK},
 		{"/version", http.StatusOK},
+		{"/freshness", http.StatusOK},
 		{"/api/v1/catalog/overview", http.StatusOK},
 		{"/api/v1/discounts/recent", http.StatusOK},
+		{"/api/v1/discounts/recent?sort=deal_score", http.StatusOK},
+		{"/api/v1/products/alpha/stats", http.StatusOK},
+		{"/api/v1/products/alpha/availability", http.StatusOK},
+		{"/api/v1/products/alpha/at/2026-03-01", http.StatusOK},
+		{"/api/v1/products/alpha/at/2026-3-1", http.StatusBadRequest},
+		{"/api/v1/slugs/alpha/resolve", http.StatusOK},
+		{"/api/v1/compare?slugs=alpha,beta", http.StatusOK},
+		{"/api/v1/compare?slugs=alpha,alpha", http.StatusBadRequest},
 		{"/api/v1/meta/filter-options", http.StatusOK},
+		{"/api/v1/sellers", http.StatusOK},
+		{"/api/v1/sellers/tlamagames/stats", http.StatusServiceUnavailable},
+		{"/api/v1/sellers/tlama.games/stats", http.StatusBadRequest},
 		{"/api/v1/snapshots/recent", http.StatusNotFound},
 		{"/api/v1/meta/categories", http.StatusNotFound},
 	}
@@ -8,6 +8,7 @@ import (
 	"golang.org/x/sync/singleflight"
 	"tlamasite/apps/api-go/internal/cache"
 	"tlamasite/apps/api-go/internal/catalog"
+	"tlamasite/apps/api-go/internal/sellerstats"
 	"tlamasite/apps/api-go/internal/snapshots"
 )
 
@@ -17,11 +18,15 @@ type CacheTTLConfig struct {
 	Product    time.Duration
 	Discounts  time.Duration
 	PriceRange time.Duration
+	Sellers    time.Duration
 }
 
 type ServiceOptions struct {
 	CacheNamespace string
 	CacheTTL       CacheTTLConfig
+	// SellerStats holds the precomputed competitiveness report; nil keeps the
+	// endpoint answering not_ready.
+	SellerStats *sellerstats.Store
 }
 
 type catalogRepository interface {
@@ -29,11 +34,19 @@ type catalogRepository interface {
 	FetchOverview(context.Context) (catalog.Overview, error)
 	Search(context.Context, string, string, []string, int) ([]catalog.SuggestionRow, error)
 	FetchPriceRange(context.Context, catalog.PriceRangeFilters) (catalog.PriceRange, error)
+	Compare(context.Context, []string) (catalog.Comparison, error)
+	Sellers(context.Context) ([]catalog.SellerSummary, error)
+	SellerFreshness(context.Context) (catalog.FreshnessReport, error)
 }
 
 type snapshotRepository interface {
-	BySlug(context.Context, string, int) (snapshots.ProductDetail, error)
-	RecentDiscounts(context.Context, int) ([]snapshots.RecentDiscount, error)
+	BySlug(context.Context, string, snapshots.HistoryOptions) (snapshots.ProductDetail, error)
+	RecentDiscounts(context.Context, snapshots.DiscountFilters) (snapshots.DiscountPage, error)
+	NewArrivals(context.Context, snapshots.ArrivalFilters) ([]snapshots.NewArrival, error)
+	PriceStats(context.Context, string) (snapshots.ProductPriceStats, error)
+	Availability(context.Context, string) (snapshots.ProductAvailability, error)
+	PriceAt(context.Context, string, string) (snapshots.ProductPriceAt, error)
+	ResolveSlug(context.Context, string) (snapshots.SlugResolution, error)
 	Ping(context.Context) error
 }
 
@@ -43,6 +56,7 @@ type Service struct {
 	cacheClient    cache.Client
 	cacheNamespace string
 	cacheTTL       CacheTTLConfig
+	sellerStats    *sellerstats.Store
 	requests       singleflight.Group
 }
 
@@ -59,6 +73,7 @@ func NewService(
 		cacheClient:    cacheClient,
 		cacheNamespace: normalized.CacheNamespace,
 		cacheTTL:       normalized.CacheTTL,
+		sellerStats:    options.SellerStats,
 	}
 }
 
@@ -71,6 +86,7 @@ func normalizeServiceOptions(options ServiceOptions) ServiceOptions {
 			Product:    300 * time.Second,
 			Discounts:  60 * time.Second,
 			PriceRange: 180 * time.Second,
+			Sellers:    300 * time.Second,
 		},
 	}
 
@@ -93,6 +109,9 @@ func normalizeServiceOptions(options ServiceOptions) ServiceOptions {
 	if normalized.CacheTTL.PriceRange <= 0 {
 		normalized.CacheTTL.PriceRange = defaults.CacheTTL.PriceRange
 	}
+	if normalized.CacheTTL.Sellers <= 0 {
+		normalized.CacheTTL.Sellers = defaults.CacheTTL.Sellers
+	}
 	return normalized
 }
 
@@ -105,14 +124,14 @@ type suggestionRowsResponse struct {
 	Rows []catalog.SuggestionRow `json:"rows"`
 }
 
-type productDetailCacheResponse struct {
-	Detail snapshots.ProductDetail `json:"detail"`
+type arrivalRowsResponse struct {
+	Rows []snapshots.NewArrival `json:"rows"`
 }
 
-type priceRangeResponse struct {
-	Row catalog.PriceRange `json:"row"`
+type sellerRowsResponse struct {
+	Rows []catalog.SellerSummary `json:"rows"`
 }
 
-type discountRowsResponse struct {
-	Rows []snapshots.RecentDiscount `json:"rows"`
+type priceRangeResponse struct {
+	Row catalog.PriceRange `json:"row"`
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"tlamasite/apps/api-go/internal/catalog"
+	"tlamasite/apps/api-go/internal/snapshots"
 )
 
 func catalogCacheKey(filters catalog.Filters) string {
@@ -23,10 +24,16 @@ func catalogCacheKey(filters catalog.Filters) string {
 		fmt.Sprintf("codes:%s", encodedJoin(filters.ProductCodes)),
 		fmt.Sprintf("l:%d", filters.Limit),
 		fmt.Sprintf("o:%d", filters.Offset),
+		fmt.Sprintf("trend:%t", filters.IncludeTrend),
 	}
 	return "catalog:" + strings.Join(parts, ";")
 }
 
+// compareCacheKey keeps the requested order, which is the column order.
+func compareCacheKey(slugs []string) string {
+	return "compare:" + orderedJoin(slugs)
+}
+
 func searchCacheKey(query string, availability string, productCodes []string, limit int) string {
 	return fmt.Sprintf(
 		"suggest:%s:%s:codes=%s:%d",
@@ -50,6 +57,17 @@ func priceRangeCacheKey(filters catalog.PriceRangeFilters) string {
 	)
 }
 
+func arrivalsCacheKey(filters snapshots.ArrivalFilters) string {
+	parts := []string{
+		fmt.Sprintf("l:%d", filters.Limit),
+		fmt.Sprintf("sellers:%s", sortedJoin(filters.Sellers)),
+		fmt.Sprintf("cats:%s", sortedJoin(filters.Categories)),
+		normalizeAvailability(filters.Availability),
+		fmt.Sprintf("days:%d", filters.WindowDays),
+	}
+	return "arrivals:" + strings.Join(parts, ";")
+}
+
 func sortedJoin(values []string) string {
 	normalized := append([]string(nil), values...)
 	sort.Strings(normalized)
@@ -59,8 +77,14 @@ func sortedJoin(values []string) string {
 func encodedJoin(values []string) string {
 	normalized := append([]string(nil), values...)
 	sort.Strings(normalized)
-	encoded := make([]string, 0, len(normalized))
-	for _, value := range normalized {
+	return orderedJoin(normalized)
+}
+
+// orderedJoin length-prefixes each value like encodedJoin but keeps the given
+// order, for keys where order changes the response.
+func orderedJoin(values []string) string {
+	encoded := make([]string, 0, len(values))
+	for _, value := range values {
 		encoded = append(encoded, fmt.Sprintf("%d:%s", len(value), value))
 	}
 	return strings.Join(encoded, "|")
@@ -89,10 +113,46 @@ func floatPtrKey(value *float64) string {
 	return strconv.FormatFloat(*value, 'g', -1, 64)
 }
 
-func productCacheKey(slug string, historyPoints int) string {
+func productCacheKey(slug string, history snapshots.HistoryOptions) string {
 	return fmt.Sprintf(
-		"product:%s:points-per-seller=%d",
+		"product-detail:%s:points-per-seller=%d:intraday=%t",
 		strings.ToLower(strings.TrimSpace(slug)),
-		historyPoints,
+		history.Points,
+		history.Intraday,
 	)
 }
+
+func productStatsCacheKey(slug string) string {
+	return "product-stats:" + strings.ToLower(strings.TrimSpace(slug))
+}
+
+func productAvailabilityCacheKey(slug string) string {
+	return "product-availability:" + strings.ToLower(strings.TrimSpace(slug))
+}
+
+func productPriceAtCacheKey(slug string, date string) string {
+	return "product-at:" + strings.ToLower(strings.TrimSpace(slug)) + ":" + date
+}
+
+func slugResolutionCacheKey(slug string) string {
+	return "slug-resolve:" + strings.ToLower(strings.TrimSpace(slug))
+}
+
+func discountsCacheKey(filters snapshots.DiscountFilters) string {
+	cursor := ""
+	if filters.Cursor != nil {
+		cursor = filters.Cursor.Encode()
+	}
+	parts := []string{
+		fmt.Sprintf("l:%d", filters.Limit),
+		fmt.Sprintf("hide-inflated:%t", filters.HideInflated),
+		fmt.Sprintf("sellers:%s", sortedJoin(filters.Sellers)),
+		fmt.Sprintf("cats:%s", sortedJoin(filters.Categories)),
+		normalizeAvailability(filters.Availability),
+		fmt.Sprintf("min-discount:%s", floatPtrKey(filters.MinDiscountPercent)),
+		fmt.Sprintf("max:%s", floatPtrKey(filters.MaxPrice)),
+		fmt.Sprintf("sort:%s", filters.Sort),
+		fmt.Sprintf("cursor:%s", cursor),
+	}
+	return "discounts:" + strings.Join(parts, ";")
+}
@@ -66,6 +66,15 @@ func TestEncodedJoinKeepsOpaqueProductCodeSetsDistinct(t *testing.T) {
 	}
 }
 
+func TestCompareCacheKeyKeepsColumnOrder(t *testing.T) {
+	if compareCacheKey([]string{"a", "b"}) == compareCacheKey([]string{"b", "a"}) {
+		t.Fatal("compare requests with different column orders must not share a cache key")
+	}
+	if compareCacheKey([]string{"a|b"}) == compareCacheKey([]string{"a", "b"}) {
+		t.Fatal("different compare slugs must not share a cache key")
+	}
+}
+
 func TestFetchCachedUsesValidHitWithoutCallingLoader(t *testing.T) {
 	cacheClient := newRecordingCache()
 	cacheClient.values["test:answer"] = "42"
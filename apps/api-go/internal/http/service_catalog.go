@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"tlamasite/apps/api-go/internal/catalog"
+	"tlamasite/apps/api-go/internal/sellerstats"
 )
 
 func (s *Service) Catalog(
@@ -103,6 +104,54 @@ func (s *Service) PriceRange(
 	return payload.Row, nil
 }
 
+func (s *Service) Compare(ctx context.Context, slugs []string) (catalog.Comparison, error) {
+	return fetchCached[catalog.Comparison](
+		ctx,
+		s,
+		"compare",
+		compareCacheKey(slugs),
+		s.cacheTTL.Catalog,
+		func(innerCtx context.Context) (catalog.Comparison, error) {
+			return s.catalogRepo.Compare(innerCtx, slugs)
+		},
+	)
+}
+
+func (s *Service) Sellers(ctx context.Context) ([]catalog.SellerSummary, error) {
+	payload, err := fetchCached[sellerRowsResponse](
+		ctx,
+		s,
+		"sellers",
+		"sellers",
+		s.cacheTTL.Sellers,
+		func(innerCtx context.Context) (sellerRowsResponse, error) {
+			rows, fetchErr := s.catalogRepo.Sellers(innerCtx)
+			if fetchErr != nil {
+				return sellerRowsResponse{}, fetchErr
+			}
+			return sellerRowsResponse{Rows: rows}, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return payload.Rows, nil
+}
+
+// SellerFreshness is an operational read and always goes to the database.
+func (s *Service) SellerFreshness(ctx context.Context) (catalog.FreshnessReport, error) {
+	return s.catalogRepo.SellerFreshness(ctx)
+}
+
+// SellerStats reads the in-memory report kept fresh by the seller stats job,
+// so it bypasses the shared cache.
+func (s *Service) SellerStats(_ context.Context, seller string) (sellerstats.SellerStats, error) {
+	if s.sellerStats == nil {
+		return sellerstats.SellerStats{}, sellerstats.ErrNotReady
+	}
+	return s.sellerStats.Seller(seller)
+}
+
 func (s *Service) FilterOptions(_ context.Context) (catalog.FilterOptions, error) {
 	return catalog.StaticFilterOptions(), nil
 }
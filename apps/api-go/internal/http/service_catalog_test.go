@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"tlamasite/apps/api-go/internal/catalog"
+	"tlamasite/apps/api-go/internal/sellerstats"
 )
 
 func TestNormalizeServiceOptionsAppliesDefaults(t *testing.T) {
@@ -159,3 +160,32 @@ func TestSearchAndPriceRangeForwardRepositoryInputs(t *testing.T) {
 		t.Fatalf("price range: %v", err)
 	}
 }
+
+func TestSellerStatsWithoutStoreAreNotReady(t *testing.T) {
+	service := newTestService(&fakeCatalogRepository{}, nil, newRecordingCache())
+	if _, err := service.SellerStats(context.Background(), "tlamagames"); !errors.Is(err, sellerstats.ErrNotReady) {
+		t.Fatalf("expected ErrNotReady, got %v", err)
+	}
+}
+
+func TestSellersAreCachedAsMetadata(t *testing.T) {
+	cacheClient := newRecordingCache()
+	fetchCalls := 0
+	repository := &fakeCatalogRepository{
+		sellers: func(context.Context) ([]catalog.SellerSummary, error) {
+			fetchCalls++
+			return []catalog.SellerSummary{{Seller: "tlamagames", DisplayName: "Tlama Games", OfferCount: 3}}, nil
+		},
+	}
+	service := newTestService(repository, nil, cacheClient)
+
+	for range 2 {
+		rows, err := service.Sellers(context.Background())
+		if err != nil || len(rows) != 1 || rows[0].DisplayName != "Tlama Games" {
+			t.Fatalf("unexpected sellers: %#v, %v", rows, err)
+		}
+	}
+	if fetchCalls != 1 || cacheClient.setCalls != 1 {
+		t.Fatalf("sellers were not cached: fetches=%d sets=%d", fetchCalls, cacheClient.setCalls)
+	}
+}
@@ -2,7 +2,6 @@ package http
 
 import (
 	"context"
-	"fmt"
 
 	"tlamasite/apps/api-go/internal/snapshots"
 )
@@ -10,46 +9,118 @@ import (
 func (s *Service) ProductDetail(
 	ctx context.Context,
 	slug string,
-	historyPoints int,
-) (snapshots.ProductDetail, error) {
-	cacheKey := productCacheKey(slug, historyPoints)
-	payload, err := fetchCached[productDetailCacheResponse](
+	history snapshots.HistoryOptions,
+) (encodedProductDetail, error) {
+	return fetchCached[encodedProductDetail](
 		ctx,
 		s,
 		"product",
-		cacheKey,
+		productCacheKey(slug, history),
 		s.cacheTTL.Product,
-		func(innerCtx context.Context) (productDetailCacheResponse, error) {
-			detail, fetchErr := s.snapshotRepo.BySlug(innerCtx, slug, historyPoints)
+		func(innerCtx context.Context) (encodedProductDetail, error) {
+			detail, fetchErr := s.snapshotRepo.BySlug(innerCtx, slug, history)
 			if fetchErr != nil {
-				return productDetailCacheResponse{}, fetchErr
+				return encodedProductDetail{}, fetchErr
 			}
-			return productDetailCacheResponse{Detail: detail}, nil
+			return encodeProductDetail(detail)
+		},
+	)
+}
+
+func (s *Service) ResolveSlug(ctx context.Context, slug string) (snapshots.SlugResolution, error) {
+	return fetchCached[snapshots.SlugResolution](
+		ctx,
+		s,
+		"slug-resolve",
+		slugResolutionCacheKey(slug),
+		s.cacheTTL.Product,
+		func(innerCtx context.Context) (snapshots.SlugResolution, error) {
+			return s.snapshotRepo.ResolveSlug(innerCtx, slug)
+		},
+	)
+}
+
+func (s *Service) ProductStats(
+	ctx context.Context,
+	slug string,
+) (snapshots.ProductPriceStats, error) {
+	return fetchCached[snapshots.ProductPriceStats](
+		ctx,
+		s,
+		"product-stats",
+		productStatsCacheKey(slug),
+		s.cacheTTL.Product,
+		func(innerCtx context.Context) (snapshots.ProductPriceStats, error) {
+			return s.snapshotRepo.PriceStats(innerCtx, slug)
+		},
+	)
+}
+
+func (s *Service) ProductAvailability(
+	ctx context.Context,
+	slug string,
+) (snapshots.ProductAvailability, error) {
+	return fetchCached[snapshots.ProductAvailability](
+		ctx,
+		s,
+		"product-availability",
+		productAvailabilityCacheKey(slug),
+		s.cacheTTL.Product,
+		func(innerCtx context.Context) (snapshots.ProductAvailability, error) {
+			return s.snapshotRepo.Availability(innerCtx, slug)
+		},
+	)
+}
+
+func (s *Service) ProductPriceAt(
+	ctx context.Context,
+	slug string,
+	date string,
+) (snapshots.ProductPriceAt, error) {
+	return fetchCached[snapshots.ProductPriceAt](
+		ctx,
+		s,
+		"product-at",
+		productPriceAtCacheKey(slug, date),
+		s.cacheTTL.Product,
+		func(innerCtx context.Context) (snapshots.ProductPriceAt, error) {
+			return s.snapshotRepo.PriceAt(innerCtx, slug, date)
 		},
 	)
-	if err != nil {
-		return snapshots.ProductDetail{}, err
-	}
-	return payload.Detail, nil
 }
 
 func (s *Service) RecentDiscounts(
 	ctx context.Context,
-	limit int,
-) ([]snapshots.RecentDiscount, error) {
-	cacheKey := fmt.Sprintf("discounts:%d", limit)
-	payload, err := fetchCached[discountRowsResponse](
+	filters snapshots.DiscountFilters,
+) (snapshots.DiscountPage, error) {
+	return fetchCached[snapshots.DiscountPage](
 		ctx,
 		s,
 		"discounts",
-		cacheKey,
+		discountsCacheKey(filters),
+		s.cacheTTL.Discounts,
+		func(innerCtx context.Context) (snapshots.DiscountPage, error) {
+			return s.snapshotRepo.RecentDiscounts(innerCtx, filters)
+		},
+	)
+}
+
+func (s *Service) NewArrivals(
+	ctx context.Context,
+	filters snapshots.ArrivalFilters,
+) ([]snapshots.NewArrival, error) {
+	payload, err := fetchCached[arrivalRowsResponse](
+		ctx,
+		s,
+		"arrivals",
+		arrivalsCacheKey(filters),
 		s.cacheTTL.Discounts,
-		func(innerCtx context.Context) (discountRowsResponse, error) {
-			rows, fetchErr := s.snapshotRepo.RecentDiscounts(innerCtx, limit)
+		func(innerCtx context.Context) (arrivalRowsResponse, error) {
+			rows, fetchErr := s.snapshotRepo.NewArrivals(innerCtx, filters)
 			if fetchErr != nil {
-				return discountRowsResponse{}, fetchErr
+				return arrivalRowsResponse{}, fetchErr
 			}
-			return discountRowsResponse{Rows: rows}, nil
+			return arrivalRowsResponse{Rows: rows}, nil
 		},
 	)
 	if err != nil {
@@ -3,7 +3,9 @@ package http
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"tlamasite/apps/api-go/internal/snapshots"
 )
@@ -12,7 +14,7 @@ func TestProductDetailCachesBySlugAndHistoryLimit(t *testing.T) {
 	cacheClient := newRecordingCache()
 	fetchCalls := 0
 	repository := &fakeSnapshotRepository{
-		bySlug: func(_ context.Context, slug string, historyPoints int) (snapshots.ProductDetail, error) {
+		bySlug: func(_ context.Context, slug string, _ snapshots.HistoryOptions) (snapshots.ProductDetail, error) {
 			fetchCalls++
 			return snapshots.ProductDetail{ProductNameNormalized: slug}, nil
 		},
@@ -20,14 +22,19 @@ func TestProductDetailCachesBySlugAndHistoryLimit(t *testing.T) {
 	service := newTestService(nil, repository, cacheClient)
 
 	for range 2 {
-		detail, err := service.ProductDetail(context.Background(), "alpha", 100)
-		if err != nil || detail.ProductNameNormalized != "alpha" {
-			t.Fatalf("unexpected product detail: %#v, %v", detail, err)
+		detail, err := service.ProductDetail(context.Background(), "alpha", historyOptions(100))
+		if err != nil || !strings.Contains(string(detail.Body), `"product_name_normalized":"alpha"`) {
+			t.Fatalf("unexpected product detail: %s, %v", detail.Body, err)
 		}
 	}
-	_, _ = service.ProductDetail(context.Background(), "alpha", 200)
-	_, _ = service.ProductDetail(context.Background(), "beta", 100)
-	if fetchCalls != 3 {
+	_, _ = service.ProductDetail(context.Background(), "alpha", historyOptions(200))
+	_, _ = service.ProductDetail(context.Background(), "beta", historyOptions(100))
+	_, _ = service.ProductDetail(
+		context.Background(),
+		"alpha",
+		snapshots.HistoryOptions{Points: 100, Intraday: true},
+	)
+	if fetchCalls != 4 {
 		t.Fatalf("product cache keys collided; repository calls=%d", fetchCalls)
 	}
 }
@@ -36,7 +43,7 @@ func TestProductDetailPropagatesNotFoundWithoutCachingIt(t *testing.T) {
 	cacheClient := newRecordingCache()
 	fetchCalls := 0
 	repository := &fakeSnapshotRepository{
-		bySlug: func(_ context.Context, _ string, _ int) (snapshots.ProductDetail, error) {
+		bySlug: func(_ context.Context, _ string, _ snapshots.HistoryOptions) (snapshots.ProductDetail, error) {
 			fetchCalls++
 			return snapshots.ProductDetail{}, snapshots.ErrProductNotFound
 		},
@@ -44,7 +51,7 @@ func TestProductDetailPropagatesNotFoundWithoutCachingIt(t *testing.T) {
 	service := newTestService(nil, repository, cacheClient)
 
 	for range 2 {
-		_, err := service.ProductDetail(context.Background(), "missing", 0)
+		_, err := service.ProductDetail(context.Background(), "missing", historyOptions(0))
 		if !snapshots.IsProductNotFound(err) {
 			t.Fatalf("expected not-found error, got %v", err)
 		}
@@ -54,24 +61,88 @@ func TestProductDetailPropagatesNotFoundWithoutCachingIt(t *testing.T) {
 	}
 }
 
+func TestProductStatsCachesBySlug(t *testing.T) {
+	cacheClient := newRecordingCache()
+	fetchCalls := 0
+	repository := &fakeSnapshotRepository{
+		priceStats: func(_ context.Context, slug string) (snapshots.ProductPriceStats, error) {
+			fetchCalls++
+			return snapshots.ProductPriceStats{ProductNameNormalized: slug}, nil
+		},
+	}
+	service := newTestService(nil, repository, cacheClient)
+
+	for range 2 {
+		stats, err := service.ProductStats(context.Background(), "alpha")
+		if err != nil || stats.ProductNameNormalized != "alpha" {
+			t.Fatalf("unexpected stats: %#v, %v", stats, err)
+		}
+	}
+	_, _ = service.ProductStats(context.Background(), "beta")
+	if fetchCalls != 2 {
+		t.Fatalf("stats cache keys collided or missed; repository calls=%d", fetchCalls)
+	}
+}
+
 func TestRecentDiscountsAndReadyPropagateRepositoryResults(t *testing.T) {
 	readinessError := errors.New("database unavailable")
 	repository := &fakeSnapshotRepository{
-		recentDiscounts: func(_ context.Context, limit int) ([]snapshots.RecentDiscount, error) {
-			if limit != 7 {
-				t.Fatalf("unexpected discount limit %d", limit)
+		recentDiscounts: func(
+			_ context.Context,
+			filters snapshots.DiscountFilters,
+		) (snapshots.DiscountPage, error) {
+			if filters.Limit != 7 {
+				t.Fatalf("unexpected discount limit %d", filters.Limit)
 			}
-			return []snapshots.RecentDiscount{{ProductNameNormalized: "alpha", Seller: "tlama"}}, nil
+			return snapshots.DiscountPage{
+				Rows: []snapshots.RecentDiscount{{ProductNameNormalized: "alpha", Seller: "tlama"}},
+			}, nil
 		},
 		ping: func(context.Context) error { return readinessError },
 	}
 	service := newTestService(nil, repository, nil)
 
-	rows, err := service.RecentDiscounts(context.Background(), 7)
-	if err != nil || len(rows) != 1 || rows[0].Seller != "tlama" {
-		t.Fatalf("unexpected discounts: %#v, %v", rows, err)
+	page, err := service.RecentDiscounts(
+		context.Background(),
+		snapshots.DiscountFilters{Limit: 7},
+	)
+	if err != nil || len(page.Rows) != 1 || page.Rows[0].Seller != "tlama" {
+		t.Fatalf("unexpected discounts: %#v, %v", page, err)
 	}
 	if err := service.Ready(context.Background()); !errors.Is(err, readinessError) {
 		t.Fatalf("unexpected readiness error: %v", err)
 	}
 }
+
+func historyOptions(points int) snapshots.HistoryOptions {
+	return snapshots.HistoryOptions{Points: points}
+}
+
+func TestRecentDiscountsKeepsDistinctPageCacheKeys(t *testing.T) {
+	cacheClient := newRecordingCache()
+	fetchCalls := 0
+	repository := &fakeSnapshotRepository{
+		recentDiscounts: func(context.Context, snapshots.DiscountFilters) (snapshots.DiscountPage, error) {
+			fetchCalls++
+			return snapshots.DiscountPage{Rows: []snapshots.RecentDiscount{}}, nil
+		},
+	}
+	service := newTestService(nil, repository, cacheClient)
+	firstPage := snapshots.DiscountFilters{Limit: 10, Sort: snapshots.DiscountSortRecent}
+	secondPage := firstPage
+	secondPage.Cursor = &snapshots.DiscountCursor{
+		Sort:      snapshots.DiscountSortRecent,
+		ChangedAt: time.Date(2026, 7, 11, 13, 0, 0, 0, time.UTC),
+		Slug:      "alpha",
+		Seller:    "tlama",
+	}
+	byDiscount := firstPage
+	byDiscount.Sort = snapshots.DiscountSortDiscount
+
+	for _, filters := range []snapshots.DiscountFilters{firstPage, secondPage, byDiscount, firstPage} {
+		_, _ = service.RecentDiscounts(context.Background(), filters)
+	}
+	if fetchCalls != 3 {
+		t.Fatalf("discount pages shared or missed cache entries; fetches=%d", fetchCalls)
+	}
+}
@@ -15,6 +15,9 @@ type fakeCatalogRepository struct {
 	fetchOverview   func(context.Context) (catalog.Overview, error)
 	search          func(context.Context, string, string, []string, int) ([]catalog.SuggestionRow, error)
 	fetchPriceRange func(context.Context, catalog.PriceRangeFilters) (catalog.PriceRange, error)
+	compare         func(context.Context, []string) (catalog.Comparison, error)
+	sellers         func(context.Context) ([]catalog.SellerSummary, error)
+	freshness       func(context.Context) (catalog.FreshnessReport, error)
 }
 
 func (repository *fakeCatalogRepository) Fetch(
@@ -59,31 +62,111 @@ func (repository *fakeCatalogRepository) FetchPriceRange(
 	return repository.fetchPriceRange(ctx, filters)
 }
 
+func (repository *fakeCatalogRepository) Compare(
+	ctx context.Context,
+	slugs []string,
+) (catalog.Comparison, error) {
+	if repository.compare == nil {
+		return catalog.Comparison{}, nil
+	}
+	return repository.compare(ctx, slugs)
+}
+
+func (repository *fakeCatalogRepository) Sellers(ctx context.Context) ([]catalog.SellerSummary, error) {
+	if repository.sellers == nil {
+		return nil, nil
+	}
+	return repository.sellers(ctx)
+}
+
+func (repository *fakeCatalogRepository) SellerFreshness(ctx context.Context) (catalog.FreshnessReport, error) {
+	if repository.freshness == nil {
+		return catalog.FreshnessReport{Rows: []catalog.SellerFreshness{}}, nil
+	}
+	return repository.freshness(ctx)
+}
+
 type fakeSnapshotRepository struct {
-	bySlug          func(context.Context, string, int) (snapshots.ProductDetail, error)
-	recentDiscounts func(context.Context, int) ([]snapshots.RecentDiscount, error)
+	bySlug          func(context.Context, string, snapshots.HistoryOptions) (snapshots.ProductDetail, error)
+	recentDiscounts func(context.Context, snapshots.DiscountFilters) (snapshots.DiscountPage, error)
+	newArrivals     func(context.Context, snapshots.ArrivalFilters) ([]snapshots.NewArrival, error)
+	priceStats      func(context.Context, string) (snapshots.ProductPriceStats, error)
+	availability    func(context.Context, string) (snapshots.ProductAvailability, error)
+	priceAt         func(context.Context, string, string) (snapshots.ProductPriceAt, error)
+	resolveSlug     func(context.Context, string) (snapshots.SlugResolution, error)
 	ping            func(context.Context) error
 }
 
 func (repository *fakeSnapshotRepository) BySlug(
 	ctx context.Context,
 	slug string,
-	historyPoints int,
+	history snapshots.HistoryOptions,
 ) (snapshots.ProductDetail, error) {
 	if repository.bySlug == nil {
 		return snapshots.ProductDetail{}, nil
 	}
-	return repository.bySlug(ctx, slug, historyPoints)
+	return repository.bySlug(ctx, slug, history)
 }
 
 func (repository *fakeSnapshotRepository) RecentDiscounts(
 	ctx context.Context,
-	limit int,
-) ([]snapshots.RecentDiscount, error) {
+	filters snapshots.DiscountFilters,
+) (snapshots.DiscountPage, error) {
 	if repository.recentDiscounts == nil {
+		return snapshots.DiscountPage{}, nil
+	}
+	return repository.recentDiscounts(ctx, filters)
+}
+
+func (repository *fakeSnapshotRepository) NewArrivals(
+	ctx context.Context,
+	filters snapshots.ArrivalFilters,
+) ([]snapshots.NewArrival, error) {
+	if repository.newArrivals == nil {
 		return nil, nil
 	}
-	return repository.recentDiscounts(ctx, limit)
+	return repository.newArrivals(ctx, filters)
+}
+
+func (repository *fakeSnapshotRepository) PriceStats(
+	ctx context.Context,
+	slug string,
+) (snapshots.ProductPriceStats, error) {
+	if repository.priceStats == nil {
+		return snapshots.ProductPriceStats{}, nil
+	}
+	return repository.priceStats(ctx, slug)
+}
+
+func (repository *fakeSnapshotRepository) Availability(
+	ctx context.Context,
+	slug string,
+) (snapshots.ProductAvailability, error) {
+	if repository.availability == nil {
+		return snapshots.ProductAvailability{}, nil
+	}
+	return repository.availability(ctx, slug)
+}
+
+func (repository *fakeSnapshotRepository) PriceAt(
+	ctx context.Context,
+	slug string,
+	date string,
+) (snapshots.ProductPriceAt, error) {
+	if repository.priceAt == nil {
+		return snapshots.ProductPriceAt{}, nil
+	}
+	return repository.priceAt(ctx, slug, date)
+}
+
+func (repository *fakeSnapshotRepository) ResolveSlug(
+	ctx context.Context,
+	slug string,
+) (snapshots.SlugResolution, error) {
+	if repository.resolveSlug == nil {
+		return snapshots.SlugResolution{RequestedSlug: slug, Canonical: slug}, nil
+	}
+	return repository.resolveSlug(ctx, slug)
 }
 
 func (repository *fakeSnapshotRepository) Ping(ctx context.Context) error {
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"tlamasite/apps/api-go/internal/alerts"
+)
+
+type watchService interface {
+	Create(context.Context, alerts.WatchRequest) error
+	Confirm(context.Context, string) (alerts.Watch, error)
+	Delete(context.Context, string) error
+}
+
+// WatchHandler serves the price watch API. Tokens travel in request bodies,
+// never in paths, so request logs cannot replay them.
+type WatchHandler struct {
+	service watchService
+}
+
+func NewWatchHandler(service watchService) *WatchHandler {
+	return &WatchHandler{service: service}
+}
+
+// Create always answers 202 for a valid request, whether or not a mail was
+// sent, so the endpoint does not reveal which addresses watch a product.
+func (h *WatchHandler) Create(w http.ResponseWriter, r *http.Request) {
+	request, validationErr := parseWatchRequest(r.Body)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	if err := h.service.Create(r.Context(), request); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "pending_confirmation"})
+}
+
+func (h *WatchHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	token, validationErr := parseWatchToken(r.Body)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	watch, err := h.service.Confirm(r.Context(), token)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, watch)
+}
+
+func (h *WatchHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	token, validationErr := parseWatchToken(r.Body)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+	if err := h.service.Delete(r.Context(), token); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tlamasite/apps/api-go/internal/alerts"
+)
+
+type fakeWatchService struct {
+	created   *alerts.WatchRequest
+	createErr error
+	confirmed string
+	deleted   string
+}
+
+func (f *fakeWatchService) Create(_ context.Context, request alerts.WatchRequest) error {
+	f.created = &request
+	return f.createErr
+}
+
+func (f *fakeWatchService) Confirm(_ context.Context, token string) (alerts.Watch, error) {
+	if token != "confirm-token" {
+		return alerts.Watch{}, alerts.ErrWatchNotFound
+	}
+	f.confirmed = token
+	return alerts.Watch{Slug: "azul", TargetPrice: 699, CurrencyCode: "CZK"}, nil
+}
+
+func (f *fakeWatchService) Delete(_ context.Context, token string) error {
+	if token != "manage-token" {
+		return alerts.ErrWatchNotFound
+	}
+	f.deleted = token
+	return nil
+}
+
+func newWatchTestRouter(service watchService) http.Handler {
+	return NewRouter(NewHandler(&fakeService{}, 200), RouterOptions{
+		AllowedOrigin: "https://www.deskovkylevne.test",
+		Watches:       NewWatchHandler(service),
+	})
+}
+
+func serveWatchRequest(router http.Handler, method string, path string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestCreateWatchNormalizesRequest(t *testing.T) {
+	service := &fakeWatchService{}
+	recorder := serveWatchRequest(
+		newWatchTestRouter(service),
+		http.MethodPost,
+		"/api/v1/watches",
+		`{"slug":" Azul ","seller":"TlamaGames","target_price":699.499,"email":"Hrac@Example.test"}`,
+	)
+	if recorder.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if recorder.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("unexpected Cache-Control %q", recorder.Header().Get("Cache-Control"))
+	}
+	request := service.created
+	if request == nil || request.Slug != "azul" || *request.Seller != "tlamagames" ||
+		request.TargetPrice != 699.5 || request.Email != "hrac@example.test" {
+		t.Fatalf("unexpected watch request: %#v", request)
+	}
+}
+
+func TestCreateWatchRejectsInvalidBodies(t *testing.T) {
+	router := newWatchTestRouter(&fakeWatchService{})
+	bodies := []string{
+		``,
+		`[]`,
+		`{"slug":"azul","target_price":500,"email":"hrac@example.test","unknown":1}`,
+		`{"slug":"","target_price":500,"email":"hrac@example.test"}`,
+		`{"slug":"azul","email":"hrac@example.test"}`,
+		`{"slug":"azul","target_price":0,"email":"hrac@example.test"}`,
+		`{"slug":"azul","target_price":2000000,"email":"hrac@example.test"}`,
+		`{"slug":"azul","target_price":500,"email":"Hráč <hrac@example.test>"}`,
+		`{"slug":"azul","target_price":500,"email":"hrac"}`,
+		`{"slug":"azul","seller":"Bad Seller!","target_price":500,"email":"hrac@example.test"}`,
+		`{"slug":"azul","target_price":500,"email":"hrac@example.test"}{}`,
+	}
+	for _, body := range bodies {
+		recorder := serveWatchRequest(router, http.MethodPost, "/api/v1/watches", body)
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", body, recorder.Code)
+		}
+	}
+}
+
+func TestCreateWatchMapsServiceErrors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{alerts.ErrProductNotFound, http.StatusNotFound, "not_found"},
+		{alerts.ErrOfferNotFound, http.StatusNotFound, "not_found"},
+		{alerts.ErrTooManyWatches, http.StatusTooManyRequests, "too_many_watches"},
+		{fmt.Errorf("%w: relay down", alerts.ErrDeliveryFailed), http.StatusServiceUnavailable, "delivery_failed"},
+	}
+	for _, testCase := range tests {
+		recorder := serveWatchRequest(
+			newWatchTestRouter(&fakeWatchService{createErr: testCase.err}),
+			http.MethodPost,
+			"/api/v1/watches",
+			`{"slug":"azul","target_price":500,"email":"hrac@example.test"}`,
+		)
+		var payload map[string]string
+		_ = json.Unmarshal(recorder.Body.Bytes(), &payload)
+		if recorder.Code != testCase.status || payload["code"] != testCase.code {
+			t.Fatalf("%v: expected %d %s, got %d %s", testCase.err, testCase.status, testCase.code, recorder.Code, recorder.Body.String())
+		}
+		if strings.Contains(recorder.Body.String(), "relay down") {
+			t.Fatalf("delivery errors must not leak relay details: %s", recorder.Body.String())
+		}
+	}
+}
+
+func TestConfirmAndDeleteWatchUseBodyTokens(t *testing.T) {
+	service := &fakeWatchService{}
+	router := newWatchTestRouter(service)
+
+	recorder := serveWatchRequest(router, http.MethodPost, "/api/v1/watches/confirm", `{"token":"confirm-token"}`)
+	if recorder.Code != http.StatusOK || service.confirmed != "confirm-token" {
+		t.Fatalf("expected confirmation, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var watch alerts.Watch
+	if err := json.Unmarshal(recorder.Body.Bytes(), &watch); err != nil || watch.Slug != "azul" {
+		t.Fatalf("unexpected confirm payload %s (%v)", recorder.Body.String(), err)
+	}
+	if strings.Contains(recorder.Body.String(), "email") {
+		t.Fatalf("confirm payload must not expose the address: %s", recorder.Body.String())
+	}
+
+	if recorder := serveWatchRequest(router, http.MethodPost, "/api/v1/watches/confirm", `{"token":"forged"}`); recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown token, got %d", recorder.Code)
+	}
+	if recorder := serveWatchRequest(router, http.MethodDelete, "/api/v1/watches", `{}`); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without token, got %d", recorder.Code)
+	}
+	recorder = serveWatchRequest(router, http.MethodDelete, "/api/v1/watches", `{"token":"manage-token"}`)
+	if recorder.Code != http.StatusNoContent || service.deleted != "manage-token" {
+		t.Fatalf("expected deletion, got %d", recorder.Code)
+	}
+}
+
+func TestWatchRoutesAreNotMountedWithoutConfiguration(t *testing.T) {
+	router := NewRouter(NewHandler(&fakeService{}, 200), RouterOptions{AllowedOrigin: "*"})
+	recorder := serveWatchRequest(router, http.MethodPost, "/api/v1/watches", `{}`)
+	if recorder.Code != http.StatusNotFound && recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected watch routes to be absent, got %d", recorder.Code)
+	}
+}
+
+func TestWatchRoutesAllowCrossOriginWrites(t *testing.T) {
+	router := newWatchTestRouter(&fakeWatchService{})
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodOptions, "/api/v1/watches", nil)
+	request.Header.Set("Origin", "https://www.deskovkylevne.test")
+	request.Header.Set("Access-Control-Request-Method", http.MethodDelete)
+	router.ServeHTTP(recorder, request)
+	if !strings.Contains(recorder.Header().Get("Access-Control-Allow-Methods"), http.MethodDelete) {
+		t.Fatalf("preflight did not allow DELETE: %#v", recorder.Header())
+	}
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"tlamasite/apps/api-go/internal/alerts"
+	"tlamasite/apps/api-go/internal/mail"
+)
+
+const (
+	maxWatchBodyBytes  = 4 << 10
+	maxWatchTarget     = 1000000
+	maxWatchTokenBytes = 128
+)
+
+type watchRequestBody struct {
+	Slug        string   `json:"slug"`
+	Seller      *string  `json:"seller"`
+	TargetPrice *float64 `json:"target_price"`
+	Email       string   `json:"email"`
+}
+
+type watchTokenBody struct {
+	Token string `json:"token"`
+}
+
+func parseWatchRequest(body io.Reader) (alerts.WatchRequest, error) {
+	var payload watchRequestBody
+	if err := decodeJSONObject(body, maxWatchBodyBytes, "a price watch", &payload); err != nil {
+		return alerts.WatchRequest{}, err
+	}
+	slug, err := validateProductSlug(payload.Slug)
+	if err != nil {
+		return alerts.WatchRequest{}, err
+	}
+	request := alerts.WatchRequest{Slug: slug}
+	if payload.Seller != nil {
+		seller, err := validateSeller(*payload.Seller)
+		if err != nil {
+			return alerts.WatchRequest{}, err
+		}
+		request.Seller = &seller
+	}
+	if payload.TargetPrice == nil {
+		return alerts.WatchRequest{}, errors.New("target_price is required")
+	}
+	target := *payload.TargetPrice
+	if target <= 0 || target > maxWatchTarget {
+		return alerts.WatchRequest{}, fmt.Errorf("target_price must be greater than 0 and at most %d", maxWatchTarget)
+	}
+	request.TargetPrice = math.Round(target*100) / 100
+	email, err := mail.ParseAddress(payload.Email)
+	if err != nil {
+		return alerts.WatchRequest{}, errors.New("email must be a plain email address")
+	}
+	request.Email = strings.ToLower(email)
+	return request, nil
+}
+
+func parseWatchToken(body io.Reader) (string, error) {
+	var payload watchTokenBody
+	if err := decodeJSONObject(body, maxWatchBodyBytes, "a watch token", &payload); err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(payload.Token)
+	if token == "" || len(token) > maxWatchTokenBytes {
+		return "", errors.New("token is required")
+	}
+	return token, nil
+}
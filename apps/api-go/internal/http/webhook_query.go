@@ -0,0 +1,89 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"tlamasite/apps/api-go/internal/admin"
+)
+
+const maxWebhookBodyBytes = 4 << 10
+
+var supportedDeliveryStatuses = map[string]struct{}{
+	"pending":   {},
+	"delivered": {},
+	"dead":      {},
+}
+
+type webhookSubscriptionBody struct {
+	URL         *string  `json:"url"`
+	EventTypes  []string `json:"event_types"`
+	Description *string  `json:"description"`
+}
+
+func parseWebhookSubscription(body io.Reader) (admin.WebhookSubscription, error) {
+	var payload webhookSubscriptionBody
+	if err := decodeJSONObject(body, maxWebhookBodyBytes, "a webhook subscription", &payload); err != nil {
+		return admin.WebhookSubscription{}, err
+	}
+	target, err := optionalHTTPSURL(payload.URL, "url")
+	if err != nil {
+		return admin.WebhookSubscription{}, err
+	}
+	if target == nil {
+		return admin.WebhookSubscription{}, errors.New("url is required")
+	}
+	subscription := admin.WebhookSubscription{URL: *target, EventTypes: make([]string, 0, len(payload.EventTypes))}
+	for _, raw := range payload.EventTypes {
+		eventType := strings.ToLower(strings.TrimSpace(raw))
+		if !slices.Contains(admin.WebhookEventTypes, eventType) {
+			return admin.WebhookSubscription{}, fmt.Errorf(
+				"event_types must contain only %s",
+				strings.Join(admin.WebhookEventTypes, ", "),
+			)
+		}
+		if !slices.Contains(subscription.EventTypes, eventType) {
+			subscription.EventTypes = append(subscription.EventTypes, eventType)
+		}
+	}
+	if len(subscription.EventTypes) == 0 {
+		return admin.WebhookSubscription{}, errors.New("event_types must not be empty")
+	}
+	if subscription.Description, err = optionalText(payload.Description, "description"); err != nil {
+		return admin.WebhookSubscription{}, err
+	}
+	return subscription, nil
+}
+
+func parseWebhookID(raw string, key string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+	return id, nil
+}
+
+func parseWebhookDeliveryFilters(rawID string, values url.Values) (admin.WebhookDeliveryFilters, error) {
+	id, err := parseWebhookID(rawID, "webhook id")
+	if err != nil {
+		return admin.WebhookDeliveryFilters{}, err
+	}
+	limit, err := parseBoundedInt(values, "limit", admin.DefaultDeliveryLimit, admin.MaxDeliveryLimit)
+	if err != nil {
+		return admin.WebhookDeliveryFilters{}, err
+	}
+	status, err := parseOptionalEnum(values.Get("status"), "status", supportedDeliveryStatuses)
+	if err != nil {
+		return admin.WebhookDeliveryFilters{}, err
+	}
+	filters := admin.WebhookDeliveryFilters{SubscriptionID: id, Limit: limit}
+	if status != "" {
+		filters.Status = &status
+	}
+	return filters, nil
+}
@@ -0,0 +1,93 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"strings"
+	"time"
+)
+
+var ErrInvalidAddress = errors.New("email address is invalid")
+
+// Message is a plain-text email. Text uses "\n" line breaks; the sender
+// converts them for the wire.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	// UnsubscribeURL sets List-Unsubscribe so mail clients can offer their own
+	// unsubscribe button.
+	UnsubscribeURL string
+}
+
+// Sender delivers a message. Implementations must be safe for concurrent use.
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// ParseAddress accepts a bare address without a display name, so stored
+// recipients are exactly what the user typed.
+func ParseAddress(raw string) (string, error) {
+	trimmed := strings.TrimSpace(raw)
+	parsed, err := netmail.ParseAddress(trimmed)
+	if err != nil || parsed.Name != "" || parsed.Address != trimmed || len(trimmed) > 254 {
+		return "", ErrInvalidAddress
+	}
+	return parsed.Address, nil
+}
+
+// compose renders an RFC 5322 message with a quoted-printable UTF-8 body.
+func compose(from *netmail.Address, to string, message Message, now time.Time) ([]byte, error) {
+	var buffer bytes.Buffer
+	writeHeader(&buffer, "From", from.String())
+	writeHeader(&buffer, "To", (&netmail.Address{Address: to}).String())
+	writeHeader(&buffer, "Subject", mime.QEncoding.Encode("utf-8", headerText(message.Subject)))
+	writeHeader(&buffer, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&buffer, "Message-ID", messageID(from.Address))
+	writeHeader(&buffer, "MIME-Version", "1.0")
+	writeHeader(&buffer, "Content-Type", "text/plain; charset=UTF-8")
+	writeHeader(&buffer, "Content-Transfer-Encoding", "quoted-printable")
+	if message.UnsubscribeURL != "" {
+		writeHeader(&buffer, "List-Unsubscribe", "<"+headerText(message.UnsubscribeURL)+">")
+	}
+	buffer.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&buffer)
+	text := strings.ReplaceAll(message.Text, "\r\n", "\n")
+	if _, err := body.Write([]byte(strings.ReplaceAll(text, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func writeHeader(buffer *bytes.Buffer, name string, value string) {
+	fmt.Fprintf(buffer, "%s: %s\r\n", name, value)
+}
+
+// headerText drops line breaks so user-controlled values such as product names
+// cannot inject headers.
+func headerText(value string) string {
+	return strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+		return r == '\r' || r == '\n'
+	}), " ")
+}
+
+func messageID(fromAddress string) string {
+	domain := "localhost"
+	if at := strings.LastIndexByte(fromAddress, '@'); at >= 0 {
+		domain = fromAddress[at+1:]
+	}
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	return "<" + hex.EncodeToString(random) + "@" + domain + ">"
+}
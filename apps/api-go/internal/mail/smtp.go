@@ -0,0 +1,124 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"time"
+)
+
+const defaultSMTPTimeout = 30 * time.Second
+
+var ErrTLSUnavailable = errors.New("smtp server does not offer STARTTLS")
+
+type SMTPOptions struct {
+	// Addr is the relay as host:port.
+	Addr     string
+	Username string
+	Password string
+	From     string
+	// RequireTLS refuses to send credentials or mail over a connection the
+	// server will not upgrade with STARTTLS. Only local relays should disable it.
+	RequireTLS bool
+	Timeout    time.Duration
+}
+
+// SMTPSender opens one connection per message. Alert volume is low enough
+// that pooling connections is not worth holding them open between refreshes.
+type SMTPSender struct {
+	options SMTPOptions
+	host    string
+	from    *netmail.Address
+	now     func() time.Time
+}
+
+func NewSMTPSender(options SMTPOptions) (*SMTPSender, error) {
+	host, _, err := net.SplitHostPort(options.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("smtp address must be host:port: %w", err)
+	}
+	from, err := netmail.ParseAddress(options.From)
+	if err != nil {
+		return nil, fmt.Errorf("smtp sender address is invalid: %w", err)
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultSMTPTimeout
+	}
+	return &SMTPSender{options: options, host: host, from: from, now: time.Now}, nil
+}
+
+func (sender *SMTPSender) Send(ctx context.Context, message Message) error {
+	recipient, err := ParseAddress(message.To)
+	if err != nil {
+		return err
+	}
+	payload, err := compose(sender.from, recipient, message, sender.now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sender.options.Timeout)
+	defer cancel()
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", sender.options.Addr)
+	if err != nil {
+		return err
+	}
+	// net/smtp has no context support; closing the connection unblocks it.
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, sender.host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+	if err := sender.deliver(client, recipient, payload); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+func (sender *SMTPSender) deliver(client *smtp.Client, recipient string, payload []byte) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: sender.host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	} else if sender.options.RequireTLS {
+		return ErrTLSUnavailable
+	}
+	if sender.options.Username != "" {
+		auth := smtp.PlainAuth("", sender.options.Username, sender.options.Password, sender.host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(sender.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(recipient); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(payload); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,215 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer speaks just enough ESMTP for net/smtp and records one
+// session per connection.
+type fakeSMTPServer struct {
+	listener net.Listener
+	auth     bool
+
+	mu       sync.Mutex
+	commands []string
+	data     []string
+	done     chan struct{}
+}
+
+func startFakeSMTPServer(t *testing.T, auth bool) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener, auth: auth, done: make(chan struct{})}
+	go server.serve()
+	t.Cleanup(func() {
+		_ = listener.Close()
+		<-server.done
+	})
+	return server
+}
+
+func (server *fakeSMTPServer) serve() {
+	defer close(server.done)
+	for {
+		conn, err := server.listener.Accept()
+		if err != nil {
+			return
+		}
+		server.session(conn)
+	}
+}
+
+func (server *fakeSMTPServer) session(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+	reply("220 fake.test ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimRight(line, "\r\n")
+		server.record(command)
+		verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0])
+		switch verb {
+		case "EHLO":
+			if server.auth {
+				reply("250-fake.test")
+				reply("250 AUTH PLAIN")
+			} else {
+				reply("250 fake.test")
+			}
+		case "AUTH":
+			reply("235 2.7.0 authenticated")
+		case "MAIL", "RCPT", "RSET", "NOOP":
+			reply("250 2.0.0 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var body strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				body.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			server.mu.Lock()
+			server.data = append(server.data, body.String())
+			server.mu.Unlock()
+			reply("250 2.0.0 queued")
+		case "QUIT":
+			reply("221 2.0.0 bye")
+			return
+		default:
+			reply("502 5.5.2 not implemented")
+		}
+	}
+}
+
+func (server *fakeSMTPServer) record(command string) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.commands = append(server.commands, command)
+}
+
+func (server *fakeSMTPServer) snapshot() ([]string, []string) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return append([]string(nil), server.commands...), append([]string(nil), server.data...)
+}
+
+func newTestSender(t *testing.T, server *fakeSMTPServer, options SMTPOptions) *SMTPSender {
+	t.Helper()
+	options.Addr = server.listener.Addr().String()
+	if options.From == "" {
+		options.From = "Deskovky Levně <hlidani@deskovkylevne.test>"
+	}
+	sender, err := NewSMTPSender(options)
+	if err != nil {
+		t.Fatalf("NewSMTPSender: %v", err)
+	}
+	sender.now = func() time.Time { return time.Date(2026, 3, 7, 8, 0, 0, 0, time.UTC) }
+	return sender
+}
+
+func TestSMTPSenderDeliversEncodedMessage(t *testing.T) {
+	server := startFakeSMTPServer(t, true)
+	sender := newTestSender(t, server, SMTPOptions{Username: "relay", Password: "secret"})
+
+	err := sender.Send(context.Background(), Message{
+		To:             "hrac@example.test",
+		Subject:        "Příšerně žluťoučký kůň\r\nBcc: evil@example.test",
+		Text:           "Cena klesla.\nDetail: https://www.deskovkylevne.test/deskove-hry/azul",
+		UnsubscribeURL: "https://www.deskovkylevne.test/hlidani-ceny/zrusit#token",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	commands, data := server.snapshot()
+	wantAuth := "AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00relay\x00secret"))
+	for _, want := range []string{wantAuth, "MAIL FROM:<hlidani@deskovkylevne.test>", "RCPT TO:<hrac@example.test>", "QUIT"} {
+		if !containsPrefix(commands, want) {
+			t.Fatalf("missing command %q in %q", want, commands)
+		}
+	}
+	if len(data) != 1 {
+		t.Fatalf("expected one message, got %d", len(data))
+	}
+	parsed, err := netmail.ReadMessage(strings.NewReader(data[0]))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "Příšerně žluťoučký kůň Bcc: evil@example.test" {
+		t.Fatalf("unexpected subject %q (%v)", subject, err)
+	}
+	if parsed.Header.Get("Bcc") != "" || parsed.Header.Get("To") != "<hrac@example.test>" {
+		t.Fatalf("unexpected recipients: %#v", parsed.Header)
+	}
+	if parsed.Header.Get("List-Unsubscribe") != "<https://www.deskovkylevne.test/hlidani-ceny/zrusit#token>" {
+		t.Fatalf("unexpected List-Unsubscribe %q", parsed.Header.Get("List-Unsubscribe"))
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	if err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if strings.TrimRight(string(body), "\r\n") != "Cena klesla.\r\nDetail: https://www.deskovkylevne.test/deskove-hry/azul" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestSMTPSenderRequiresTLSBeforeSending(t *testing.T) {
+	server := startFakeSMTPServer(t, true)
+	sender := newTestSender(t, server, SMTPOptions{Username: "relay", Password: "secret", RequireTLS: true})
+
+	err := sender.Send(context.Background(), Message{To: "hrac@example.test", Subject: "x", Text: "x"})
+	if !errors.Is(err, ErrTLSUnavailable) {
+		t.Fatalf("expected ErrTLSUnavailable, got %v", err)
+	}
+	commands, data := server.snapshot()
+	if containsPrefix(commands, "AUTH") || containsPrefix(commands, "MAIL") || len(data) != 0 {
+		t.Fatalf("credentials or mail sent without TLS: %q", commands)
+	}
+}
+
+func TestSMTPSenderRejectsInvalidRecipients(t *testing.T) {
+	server := startFakeSMTPServer(t, false)
+	sender := newTestSender(t, server, SMTPOptions{})
+	for _, recipient := range []string{"", "Hráč <hrac@example.test>", "hrac@example.test\r\nBcc: evil@example.test"} {
+		if err := sender.Send(context.Background(), Message{To: recipient}); !errors.Is(err, ErrInvalidAddress) {
+			t.Fatalf("recipient %q: expected ErrInvalidAddress, got %v", recipient, err)
+		}
+	}
+	if commands, _ := server.snapshot(); len(commands) != 0 {
+		t.Fatalf("invalid recipients must not open a session: %q", commands)
+	}
+}
+
+func containsPrefix(values []string, prefix string) bool {
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
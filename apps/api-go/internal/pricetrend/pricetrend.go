@@ -0,0 +1,231 @@
+// Package pricetrend derives buy-or-wait indicators from one seller's daily
+// prices. Callers analyze every seller separately; histories are never merged.
+package pricetrend
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	DirectionRising  = "rising"
+	DirectionFalling = "falling"
+	DirectionStable  = "stable"
+	DirectionUnknown = "unknown"
+)
+
+const (
+	day = 24 * time.Hour
+	// trendWindow bounds the direction and volatility indicators.
+	trendWindow    = 30 * day
+	minTrendPoints = 5
+	// stableChangeRatio is the fitted 30-day change still reported as stable.
+	stableChangeRatio = 0.02
+	// discountRatio is the minimum drop that counts as a discount or a drop.
+	discountRatio  = 0.05
+	dropHorizon    = 30 * day
+	minDropSamples = 30
+)
+
+type Point struct {
+	Date  time.Time
+	Price float64
+}
+
+// Trend summarizes historical behaviour; DropProbability30d is the share of
+// past days that were followed by a 5% lower price within 30 days, not a
+// guarantee. Indicators without enough history are nil.
+type Trend struct {
+	Direction          string   `json:"direction"`
+	ChangePercent30d   *float64 `json:"change_percent_30d"`
+	VolatilityPercent  *float64 `json:"volatility_percent_30d"`
+	DiscountCycleDays  *float64 `json:"discount_cycle_days"`
+	DropProbability30d *float64 `json:"drop_probability_30d"`
+	SampleDays         int      `json:"sample_days"`
+}
+
+// Analyze expects at most one price per day; for repeated dates the last
+// point in input order wins. Non-positive prices are ignored.
+func Analyze(points []Point) Trend {
+	series := normalizeSeries(points)
+	trend := Trend{Direction: DirectionUnknown, SampleDays: len(series)}
+	if len(series) == 0 {
+		return trend
+	}
+	window := trailingWindow(series)
+	if len(window) >= minTrendPoints {
+		change := fittedChangeRatio(window)
+		trend.Direction = directionFor(change)
+		trend.ChangePercent30d = roundedPtr(change*100, 1)
+	}
+	if len(window) >= 3 {
+		trend.VolatilityPercent = roundedPtr(volatility(window)*100, 2)
+	}
+	trend.DiscountCycleDays = discountCycleDays(series)
+	trend.DropProbability30d = dropProbability(series)
+	return trend
+}
+
+func normalizeSeries(points []Point) []Point {
+	byDate := make(map[time.Time]int, len(points))
+	series := make([]Point, 0, len(points))
+	for _, point := range points {
+		if point.Price <= 0 || math.IsNaN(point.Price) || math.IsInf(point.Price, 0) {
+			continue
+		}
+		date := truncateDay(point.Date)
+		if index, seen := byDate[date]; seen {
+			series[index].Price = point.Price
+			continue
+		}
+		byDate[date] = len(series)
+		series = append(series, Point{Date: date, Price: point.Price})
+	}
+	sort.Slice(series, func(left, right int) bool {
+		return series[left].Date.Before(series[right].Date)
+	})
+	return series
+}
+
+func truncateDay(value time.Time) time.Time {
+	year, month, dayOfMonth := value.Date()
+	return time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.UTC)
+}
+
+func trailingWindow(series []Point) []Point {
+	start := series[len(series)-1].Date.Add(-trendWindow)
+	index := sort.Search(len(series), func(position int) bool {
+		return series[position].Date.After(start)
+	})
+	return series[index:]
+}
+
+// fittedChangeRatio fits a least-squares line through the window and returns
+// its change across the window relative to the window's mean price.
+func fittedChangeRatio(window []Point) float64 {
+	origin := window[0].Date
+	var sumX, sumY float64
+	for _, point := range window {
+		sumX += point.Date.Sub(origin).Hours() / 24
+		sumY += point.Price
+	}
+	count := float64(len(window))
+	meanX, meanY := sumX/count, sumY/count
+	var covariance, variance float64
+	for _, point := range window {
+		x := point.Date.Sub(origin).Hours()/24 - meanX
+		covariance += x * (point.Price - meanY)
+		variance += x * x
+	}
+	if variance == 0 || meanY == 0 {
+		return 0
+	}
+	spanDays := window[len(window)-1].Date.Sub(origin).Hours() / 24
+	return covariance / variance * spanDays / meanY
+}
+
+func directionFor(change float64) string {
+	switch {
+	case change >= stableChangeRatio:
+		return DirectionRising
+	case change <= -stableChangeRatio:
+		return DirectionFalling
+	default:
+		return DirectionStable
+	}
+}
+
+// volatility is the population standard deviation of relative changes
+// between consecutive observed days.
+func volatility(window []Point) float64 {
+	changes := make([]float64, 0, len(window)-1)
+	var sum float64
+	for index := 1; index < len(window); index++ {
+		change := window[index].Price/window[index-1].Price - 1
+		changes = append(changes, change)
+		sum += change
+	}
+	mean := sum / float64(len(changes))
+	var squares float64
+	for _, change := range changes {
+		squares += (change - mean) * (change - mean)
+	}
+	return math.Sqrt(squares / float64(len(changes)))
+}
+
+// discountCycleDays returns the median number of days between discount
+// starts. A day is discounted when its price is at least 5% below the highest
+// price of the preceding 30 days.
+func discountCycleDays(series []Point) *float64 {
+	starts := make([]time.Time, 0, 8)
+	previouslyDiscounted := false
+	for index := range series {
+		discounted := isDiscounted(series, index)
+		if discounted && !previouslyDiscounted {
+			starts = append(starts, series[index].Date)
+		}
+		previouslyDiscounted = discounted
+	}
+	if len(starts) < 2 {
+		return nil
+	}
+	gaps := make([]float64, 0, len(starts)-1)
+	for index := 1; index < len(starts); index++ {
+		gaps = append(gaps, starts[index].Sub(starts[index-1]).Hours()/24)
+	}
+	return roundedPtr(median(gaps), 1)
+}
+
+func isDiscounted(series []Point, index int) bool {
+	start := series[index].Date.Add(-trendWindow)
+	reference := 0.0
+	for position := index - 1; position >= 0 && !series[position].Date.Before(start); position-- {
+		reference = math.Max(reference, series[position].Price)
+	}
+	return reference > 0 && series[index].Price <= reference*(1-discountRatio)
+}
+
+// dropProbability only samples days whose full 30-day horizon lies within
+// the observed history, so recent days cannot bias the share downwards.
+func dropProbability(series []Point) *float64 {
+	lastDate := series[len(series)-1].Date
+	samples, drops := 0, 0
+	for index, point := range series {
+		horizon := point.Date.Add(dropHorizon)
+		if horizon.After(lastDate) {
+			break
+		}
+		samples++
+		for next := index + 1; next < len(series) && !series[next].Date.After(horizon); next++ {
+			if series[next].Price <= point.Price*(1-discountRatio) {
+				drops++
+				break
+			}
+		}
+	}
+	if samples < minDropSamples {
+		return nil
+	}
+	return roundedPtr(float64(drops)/float64(samples), 2)
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return (sorted[middle-1] + sorted[middle]) / 2
+}
+
+func roundedPtr(value float64, decimals int) *float64 {
+	scale := math.Pow(10, float64(decimals))
+	rounded := math.Round(value*scale) / scale
+	if rounded == 0 {
+		// Normalize negative zero so JSON never reports -0.
+		rounded = 0
+	}
+	return &rounded
+}
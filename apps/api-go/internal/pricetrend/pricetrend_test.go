@@ -0,0 +1,81 @@
+package pricetrend
+
+import (
+	"testing"
+	"time"
+)
+
+var firstDay = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func dailySeries(prices ...float64) []Point {
+	points := make([]Point, 0, len(prices))
+	for index, price := range prices {
+		points = append(points, Point{Date: firstDay.AddDate(0, 0, index), Price: price})
+	}
+	return points
+}
+
+func repeatPrice(price float64, days int) []float64 {
+	prices := make([]float64, days)
+	for index := range prices {
+		prices[index] = price
+	}
+	return prices
+}
+
+func TestAnalyzeReportsUnknownWithoutEnoughHistory(t *testing.T) {
+	trend := Analyze(dailySeries(100, 90, 80))
+	if trend.Direction != DirectionUnknown || trend.ChangePercent30d != nil || trend.SampleDays != 3 {
+		t.Fatalf("unexpected short-history trend: %#v", trend)
+	}
+	if trend.DiscountCycleDays != nil || trend.DropProbability30d != nil {
+		t.Fatalf("expected no pattern indicators: %#v", trend)
+	}
+	if empty := Analyze(nil); empty.Direction != DirectionUnknown || empty.SampleDays != 0 {
+		t.Fatalf("unexpected empty trend: %#v", empty)
+	}
+}
+
+func TestAnalyzeClassifiesDirectionFromRecentWindow(t *testing.T) {
+	falling := append(repeatPrice(500, 60), 100, 98, 96, 94, 92, 90)
+	if trend := Analyze(dailySeries(falling...)); trend.Direction != DirectionFalling {
+		t.Fatalf("expected falling trend, got %#v", trend)
+	}
+	rising := Analyze(dailySeries(100, 102, 104, 106, 108, 110))
+	if rising.Direction != DirectionRising || *rising.ChangePercent30d != 9.5 {
+		t.Fatalf("expected rising trend, got %#v", rising)
+	}
+	stable := Analyze(dailySeries(100, 100.5, 100, 100.5, 100))
+	if stable.Direction != DirectionStable || *stable.VolatilityPercent == 0 {
+		t.Fatalf("expected stable but volatile trend, got %#v", stable)
+	}
+}
+
+func TestAnalyzeNormalizesDuplicateAndInvalidPoints(t *testing.T) {
+	points := []Point{
+		{Date: firstDay.Add(20 * time.Hour), Price: 120},
+		{Date: firstDay, Price: 0},
+		{Date: firstDay.Add(2 * time.Hour), Price: 100},
+	}
+	series := normalizeSeries(points)
+	if len(series) != 1 || series[0].Price != 100 || !series[0].Date.Equal(firstDay) {
+		t.Fatalf("unexpected normalized series: %#v", series)
+	}
+}
+
+func TestAnalyzeMeasuresDiscountCycleAndDropProbability(t *testing.T) {
+	prices := make([]float64, 0, 120)
+	for cycle := 0; cycle < 4; cycle++ {
+		prices = append(prices, repeatPrice(100, 25)...)
+		prices = append(prices, repeatPrice(80, 5)...)
+	}
+	trend := Analyze(dailySeries(prices...))
+	if trend.DiscountCycleDays == nil || *trend.DiscountCycleDays != 30 {
+		t.Fatalf("unexpected discount cycle: %#v", trend.DiscountCycleDays)
+	}
+	// Every full-price day is followed by a discount within 30 days; the
+	// sampled discount days are not.
+	if trend.DropProbability30d == nil || *trend.DropProbability30d != 0.83 {
+		t.Fatalf("unexpected drop probability: %#v", trend.DropProbability30d)
+	}
+}
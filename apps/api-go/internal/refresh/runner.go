@@ -0,0 +1,205 @@
+package refresh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LockKey names the transaction advisory lock held by every writer of catalog
+// state: refresh runs and alias approvals. Holders never overlap.
+const LockKey = "tlamasite:catalog_refresh"
+
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// ErrLockTimeout means another holder of LockKey did not finish within the
+// lock timeout.
+var ErrLockTimeout = errors.New("another catalog refresh is still running")
+
+const lockQuery = `select pg_advisory_xact_lock(hashtext($1));`
+
+// Lock takes the catalog refresh lock for the rest of tx.
+func Lock(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, lockQuery, LockKey)
+	return err
+}
+
+// LockWithin takes the catalog refresh lock like Lock but gives up with
+// ErrLockTimeout once timeout has passed; zero waits indefinitely.
+func LockWithin(ctx context.Context, tx pgx.Tx, timeout time.Duration) error {
+	var previousLockTimeout string
+	if err := tx.QueryRow(ctx, currentLockTimeoutQuery).Scan(&previousLockTimeout); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, setLockTimeoutQuery, lockTimeoutSetting(timeout)); err != nil {
+		return err
+	}
+	if err := Lock(ctx, tx); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "55P03" {
+			return ErrLockTimeout
+		}
+		return err
+	}
+	_, err := tx.Exec(ctx, setLockTimeoutQuery, previousLockTimeout)
+	return err
+}
+
+// startRunQuery fixes the refresh window on the database clock; a null
+// lookback means a full refresh.
+const startRunQuery = `
+insert into public.catalog_refresh_runs (since)
+values (now() - make_interval(secs => $1::double precision))
+returning id, started_at, since;`
+
+// lock_timeout only bounds the wait for LockKey. The previous value is
+// restored before the routines run so their own table locks wait as usual.
+const (
+	currentLockTimeoutQuery = `select current_setting('lock_timeout');`
+	setLockTimeoutQuery     = `select set_config('lock_timeout', $1, true);`
+)
+
+const (
+	refreshHistoryQuery = `select public.refresh_catalog_daily_price_history($1::timestamptz)::text;`
+	refreshStateQuery   = `select public.refresh_catalog_state_incremental($1::timestamptz)::text;`
+)
+
+const finishRunQuery = `
+update public.catalog_refresh_runs
+set
+  finished_at = now(),
+  status = $2,
+  duration_ms = $3,
+  history_result = $4::jsonb,
+  state_result = $5::jsonb,
+  error = $6
+where id = $1;`
+
+// finishTimeout bounds recording the outcome, which still runs after the
+// run's own context was canceled or timed out.
+const finishTimeout = 30 * time.Second
+
+type Options struct {
+	// Lookback is how far back snapshots are reprocessed; zero refreshes
+	// everything.
+	Lookback    time.Duration
+	LockTimeout time.Duration
+}
+
+// Run is one catalog_refresh_runs row as recorded at the end of a run.
+type Run struct {
+	ID        int64
+	StartedAt time.Time
+	Since     *time.Time
+	Status    string
+	Duration  time.Duration
+	History   json.RawMessage
+	State     json.RawMessage
+}
+
+type Runner struct {
+	db *pgxpool.Pool
+}
+
+func NewRunner(db *pgxpool.Pool) *Runner {
+	return &Runner{db: db}
+}
+
+// Run refreshes daily history, then catalog state, in one transaction under
+// LockKey, and records the outcome. The returned error is non-nil whenever the
+// refresh did not commit or its outcome could not be recorded.
+func (runner *Runner) Run(ctx context.Context, options Options) (Run, error) {
+	var run Run
+	if err := runner.db.QueryRow(ctx, startRunQuery, lookbackSeconds(options.Lookback)).
+		Scan(&run.ID, &run.StartedAt, &run.Since); err != nil {
+		return Run{}, fmt.Errorf("record refresh run: %w", err)
+	}
+
+	startedAt := time.Now()
+	runErr := runner.refresh(ctx, options.LockTimeout, &run)
+	run.Duration = time.Since(startedAt)
+	run.Status = StatusSucceeded
+	var message *string
+	if runErr != nil {
+		run.Status = StatusFailed
+		text := runErr.Error()
+		message = &text
+	}
+
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+	if _, err := runner.db.Exec(
+		finishCtx,
+		finishRunQuery,
+		run.ID,
+		run.Status,
+		run.Duration.Milliseconds(),
+		nullableJSON(run.History),
+		nullableJSON(run.State),
+		message,
+	); err != nil {
+		return run, errors.Join(runErr, fmt.Errorf("record refresh outcome: %w", err))
+	}
+	return run, runErr
+}
+
+func (runner *Runner) refresh(ctx context.Context, lockTimeout time.Duration, run *Run) error {
+	tx, err := runner.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := LockWithin(ctx, tx, lockTimeout); err != nil {
+		return err
+	}
+
+	var history, state string
+	if err := tx.QueryRow(ctx, refreshHistoryQuery, run.Since).Scan(&history); err != nil {
+		return fmt.Errorf("refresh daily price history: %w", err)
+	}
+	if err := tx.QueryRow(ctx, refreshStateQuery, run.Since).Scan(&state); err != nil {
+		return fmt.Errorf("refresh catalog state: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	run.History = json.RawMessage(history)
+	run.State = json.RawMessage(state)
+	return nil
+}
+
+func lookbackSeconds(lookback time.Duration) *float64 {
+	if lookback <= 0 {
+		return nil
+	}
+	seconds := lookback.Seconds()
+	return &seconds
+}
+
+// lockTimeoutSetting formats a lock_timeout value in milliseconds. Zero
+// disables the timeout, so the run waits for the lock indefinitely.
+func lockTimeoutSetting(timeout time.Duration) string {
+	if timeout <= 0 {
+		return "0"
+	}
+	return strconv.FormatInt(max(timeout.Milliseconds(), 1), 10) + "ms"
+}
+
+func nullableJSON(value json.RawMessage) *string {
+	if len(value) == 0 {
+		return nil
+	}
+	text := string(value)
+	return &text
+}
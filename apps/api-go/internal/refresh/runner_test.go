@@ -0,0 +1,57 @@
+package refresh
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockTimeoutSettingUsesMilliseconds(t *testing.T) {
+	for timeout, expected := range map[time.Duration]string{
+		0:                       "0",
+		-time.Second:            "0",
+		time.Microsecond:        "1ms",
+		1500 * time.Millisecond: "1500ms",
+		10 * time.Minute:        "600000ms",
+	} {
+		if actual := lockTimeoutSetting(timeout); actual != expected {
+			t.Fatalf("lock timeout %s: expected %q, got %q", timeout, expected, actual)
+		}
+	}
+}
+
+func TestLockWithinRestoresLockTimeout(t *testing.T) {
+	if !strings.Contains(setLockTimeoutQuery, "set_config('lock_timeout', $1, true)") {
+		t.Fatal("the lock timeout must be local to the transaction")
+	}
+	if !strings.Contains(currentLockTimeoutQuery, "current_setting('lock_timeout')") {
+		t.Fatal("the previous lock timeout must be read so it can be restored")
+	}
+}
+
+func TestZeroLookbackIsAFullRefresh(t *testing.T) {
+	if lookbackSeconds(0) != nil {
+		t.Fatal("expected a zero lookback to pass null")
+	}
+	if seconds := lookbackSeconds(48 * time.Hour); seconds == nil || *seconds != 172800 {
+		t.Fatalf("unexpected lookback seconds %v", seconds)
+	}
+}
+
+func TestRefreshQueriesRunHistoryBeforeStateUnderTheSharedLock(t *testing.T) {
+	if !strings.Contains(lockQuery, "pg_advisory_xact_lock(hashtext($1))") {
+		t.Fatal("refresh must take a transaction-scoped advisory lock")
+	}
+	if !strings.Contains(refreshHistoryQuery, "refresh_catalog_daily_price_history($1::timestamptz)") ||
+		!strings.Contains(refreshStateQuery, "refresh_catalog_state_incremental($1::timestamptz)") {
+		t.Fatal("both routines must receive the recorded since bound")
+	}
+	if !strings.Contains(startRunQuery, "make_interval(secs => $1::double precision)") {
+		t.Fatal("the refresh window must be computed on the database clock")
+	}
+	for _, fragment := range []string{"finished_at = now()", "status = $2", "duration_ms = $3", "error = $6"} {
+		if !strings.Contains(finishRunQuery, fragment) {
+			t.Fatalf("finish run query missing %q", fragment)
+		}
+	}
+}
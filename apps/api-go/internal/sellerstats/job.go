@@ -0,0 +1,73 @@
+package sellerstats
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	DefaultInterval = time.Hour
+	// retryDelay applies after a failed run so a cold start does not wait a
+	// full interval for its first report.
+	retryDelay = time.Minute
+)
+
+type loader interface {
+	load(ctx context.Context) (sourceRows, error)
+}
+
+// Job rebuilds the report on a fixed interval. Each API instance runs its own
+// job; the aggregates are small and reads never touch the database.
+type Job struct {
+	source   loader
+	store    *Store
+	interval time.Duration
+	timeout  time.Duration
+	now      func() time.Time
+}
+
+func NewJob(source loader, store *Store, interval time.Duration, timeout time.Duration) *Job {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if timeout <= 0 {
+		timeout = interval
+	}
+	return &Job{source: source, store: store, interval: interval, timeout: timeout, now: time.Now}
+}
+
+// Run refreshes immediately and then on every interval until ctx is done.
+func (job *Job) Run(ctx context.Context) {
+	for {
+		delay := job.interval
+		if err := job.refresh(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("seller stats refresh failed", "error", err)
+			delay = min(retryDelay, job.interval)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (job *Job) refresh(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, job.timeout)
+	defer cancel()
+	startedAt := job.now()
+	source, err := job.source.load(ctx)
+	if err != nil {
+		return err
+	}
+	report := assembleStats(source, startedAt.UTC())
+	job.store.replace(report)
+	slog.Info("seller stats refreshed", "sellers", len(report), "duration", job.now().Sub(startedAt).String())
+	return nil
+}
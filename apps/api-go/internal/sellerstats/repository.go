@@ -0,0 +1,139 @@
+package sellerstats
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// sharedDaysSelect keeps product-days offered by more than one seller and
+// attaches that day's market minimum. The caller supplies $1, the number of
+// days to look back.
+const sharedDaysSelect = `
+with priced_day as (
+  select
+    history.canonical_product_id,
+    history.price_date,
+    history.seller,
+    history.closing_price,
+    min(history.closing_price) over (
+      partition by history.canonical_product_id, history.price_date
+    ) as market_min_price,
+    count(*) over (
+      partition by history.canonical_product_id, history.price_date
+    ) as seller_count
+  from public.catalog_daily_price_history history
+  where history.closing_price > 0
+    and history.price_date > current_date - $1::integer
+),
+shared_day as (
+  select *
+  from priced_day
+  where seller_count > 1
+)`
+
+const competitivenessColumns = `
+  count(distinct canonical_product_id),
+  count(*),
+  count(*) filter (where closing_price <= market_min_price),
+  percentile_cont(0.5) within group (
+    order by (closing_price / market_min_price)::double precision
+  )`
+
+var summaryQuery = sharedDaysSelect + `
+select
+  seller,
+  '',` + competitivenessColumns + `
+from shared_day
+group by seller
+order by seller;`
+
+var monthlyQuery = sharedDaysSelect + `
+select
+  seller,
+  to_char(date_trunc('month', price_date), 'YYYY-MM'),` + competitivenessColumns + `
+from shared_day
+where price_date >= date_trunc('month', current_date) - make_interval(months => $2::integer - 1)
+group by seller, date_trunc('month', price_date)
+order by seller, date_trunc('month', price_date);`
+
+// knownSellersQuery lists sellers with offers or a registry entry, so a known
+// seller without shared product-days gets an empty report rather than a 404.
+const knownSellersQuery = `
+select seller from public.catalog_slug_seller_state
+union
+select seller from public.catalog_sellers
+order by seller;`
+
+// monthlyLookbackDays covers HistoryMonths calendar months including the
+// current one.
+const monthlyLookbackDays = HistoryMonths*31 + 1
+
+func (repository *Repository) aggregates(ctx context.Context, query string, args ...any) ([]aggregateRow, error) {
+	rows, err := repository.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]aggregateRow, 0, 32)
+	for rows.Next() {
+		var row aggregateRow
+		if err := rows.Scan(
+			&row.Seller,
+			&row.Month,
+			&row.SharedProducts,
+			&row.ProductDays,
+			&row.CheapestDays,
+			&row.MedianPriceRatio,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// load computes the report aggregates from catalog_daily_price_history.
+func (repository *Repository) load(ctx context.Context) (sourceRows, error) {
+	sellers, err := repository.sellers(ctx)
+	if err != nil {
+		return sourceRows{}, fmt.Errorf("seller stats sellers: %w", err)
+	}
+	summary, err := repository.aggregates(ctx, summaryQuery, SummaryWindowDays)
+	if err != nil {
+		return sourceRows{}, fmt.Errorf("seller stats summary: %w", err)
+	}
+	monthly, err := repository.aggregates(ctx, monthlyQuery, monthlyLookbackDays, HistoryMonths)
+	if err != nil {
+		return sourceRows{}, fmt.Errorf("seller stats monthly: %w", err)
+	}
+	return sourceRows{Sellers: sellers, Summary: summary, Monthly: monthly}, nil
+}
+
+func (repository *Repository) sellers(ctx context.Context) ([]string, error) {
+	rows, err := repository.db.Query(ctx, knownSellersQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sellers := make([]string, 0, 16)
+	for rows.Next() {
+		var seller string
+		if err := rows.Scan(&seller); err != nil {
+			return nil, err
+		}
+		sellers = append(sellers, seller)
+	}
+	return sellers, rows.Err()
+}
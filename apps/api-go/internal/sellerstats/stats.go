@@ -0,0 +1,154 @@
+// Package sellerstats precomputes how competitive each seller is on products
+// it shares with other sellers. The report is rebuilt periodically in the
+// background and served from memory.
+package sellerstats
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// SummaryWindowDays is the window behind the headline numbers.
+	SummaryWindowDays = 90
+	// HistoryMonths bounds the monthly evolution series.
+	HistoryMonths = 12
+)
+
+var (
+	ErrNotReady       = errors.New("seller stats are not computed yet")
+	ErrSellerNotFound = errors.New("seller not found")
+)
+
+// Competitiveness compares a seller's daily closing prices with the lowest
+// closing price any seller had for the same product on the same day. Only
+// product-days with at least two sellers count.
+type Competitiveness struct {
+	SharedProducts int64 `json:"shared_products"`
+	ProductDays    int64 `json:"product_days"`
+	CheapestDays   int64 `json:"cheapest_days"`
+	// CheapestShare is CheapestDays / ProductDays; ties for the lowest price
+	// count as cheapest.
+	CheapestShare float64 `json:"cheapest_share"`
+	// MedianPriceRatio is the median of price / market minimum, so 1.05 means
+	// the seller is typically 5% above the cheapest offer.
+	MedianPriceRatio *float64 `json:"median_price_ratio"`
+}
+
+type MonthlyCompetitiveness struct {
+	Month string `json:"month"`
+	Competitiveness
+}
+
+type SellerStats struct {
+	Seller     string                   `json:"seller"`
+	WindowDays int                      `json:"window_days"`
+	Summary    Competitiveness          `json:"summary"`
+	Monthly    []MonthlyCompetitiveness `json:"monthly"`
+	ComputedAt time.Time                `json:"computed_at"`
+}
+
+// aggregateRow is one seller aggregate as returned by SQL. Month is empty for
+// the summary window.
+type aggregateRow struct {
+	Seller           string
+	Month            string
+	SharedProducts   int64
+	ProductDays      int64
+	CheapestDays     int64
+	MedianPriceRatio *float64
+}
+
+func (row aggregateRow) competitiveness() Competitiveness {
+	result := Competitiveness{
+		SharedProducts:   row.SharedProducts,
+		ProductDays:      row.ProductDays,
+		CheapestDays:     row.CheapestDays,
+		MedianPriceRatio: row.MedianPriceRatio,
+	}
+	if row.ProductDays > 0 {
+		result.CheapestShare = float64(row.CheapestDays) / float64(row.ProductDays)
+	}
+	return result
+}
+
+// sourceRows is one load of the report inputs. Sellers lists every known
+// seller, including those without shared product-days.
+type sourceRows struct {
+	Sellers []string
+	Summary []aggregateRow
+	Monthly []aggregateRow
+}
+
+// assembleStats merges summary and monthly aggregates. A seller that only
+// shared products earlier in the history, or never shared one, still gets a
+// report with an empty summary.
+func assembleStats(source sourceRows, computedAt time.Time) map[string]SellerStats {
+	report := make(map[string]SellerStats, len(source.Sellers))
+	get := func(seller string) SellerStats {
+		stats, exists := report[seller]
+		if !exists {
+			stats = SellerStats{
+				Seller:     seller,
+				WindowDays: SummaryWindowDays,
+				Monthly:    []MonthlyCompetitiveness{},
+				ComputedAt: computedAt,
+			}
+		}
+		return stats
+	}
+	for _, seller := range source.Sellers {
+		report[seller] = get(seller)
+	}
+	for _, row := range source.Summary {
+		stats := get(row.Seller)
+		stats.Summary = row.competitiveness()
+		report[row.Seller] = stats
+	}
+	for _, row := range source.Monthly {
+		stats := get(row.Seller)
+		stats.Monthly = append(stats.Monthly, MonthlyCompetitiveness{
+			Month:           row.Month,
+			Competitiveness: row.competitiveness(),
+		})
+		report[row.Seller] = stats
+	}
+	for seller, stats := range report {
+		sort.Slice(stats.Monthly, func(i, j int) bool {
+			return stats.Monthly[i].Month < stats.Monthly[j].Month
+		})
+		report[seller] = stats
+	}
+	return report
+}
+
+// Store holds the latest report. It is safe for concurrent use.
+type Store struct {
+	mu     sync.RWMutex
+	report map[string]SellerStats
+}
+
+func NewStore() *Store {
+	return &Store{}
+}
+
+func (store *Store) replace(report map[string]SellerStats) {
+	store.mu.Lock()
+	store.report = report
+	store.mu.Unlock()
+}
+
+func (store *Store) Seller(seller string) (SellerStats, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	if store.report == nil {
+		return SellerStats{}, ErrNotReady
+	}
+	stats, exists := store.report[seller]
+	if !exists {
+		return SellerStats{}, ErrSellerNotFound
+	}
+	return stats, nil
+}
@@ -0,0 +1,122 @@
+package sellerstats
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeLoader struct {
+	source sourceRows
+	err    error
+	calls  int
+}
+
+func (loader *fakeLoader) load(context.Context) (sourceRows, error) {
+	loader.calls++
+	return loader.source, loader.err
+}
+
+func TestAssembleStatsComputesSharesAndOrdersMonths(t *testing.T) {
+	ratio := 1.04
+	computedAt := time.Date(2026, 7, 11, 12, 0, 0, 0, time.UTC)
+	report := assembleStats(sourceRows{
+		Summary: []aggregateRow{{Seller: "tlamagames", SharedProducts: 40, ProductDays: 200, CheapestDays: 150, MedianPriceRatio: &ratio}},
+		Monthly: []aggregateRow{
+			{Seller: "tlamagames", Month: "2026-07", ProductDays: 10, CheapestDays: 5},
+			{Seller: "tlamagames", Month: "2026-06", ProductDays: 0},
+			{Seller: "planetaher", Month: "2025-09", ProductDays: 4, CheapestDays: 1},
+		},
+	}, computedAt)
+	stats := report["tlamagames"]
+	if stats.Summary.CheapestShare != 0.75 || *stats.Summary.MedianPriceRatio != ratio || stats.WindowDays != SummaryWindowDays {
+		t.Fatalf("unexpected summary: %#v", stats.Summary)
+	}
+	if len(stats.Monthly) != 2 || stats.Monthly[0].Month != "2026-06" || stats.Monthly[0].CheapestShare != 0 || stats.Monthly[1].CheapestShare != 0.5 {
+		t.Fatalf("unexpected monthly series: %#v", stats.Monthly)
+	}
+	if historic := report["planetaher"]; historic.Summary.ProductDays != 0 || len(historic.Monthly) != 1 || !historic.ComputedAt.Equal(computedAt) {
+		t.Fatalf("seller without recent overlap lost its history: %#v", historic)
+	}
+}
+
+func TestStoreReportsReadinessAndUnknownSellers(t *testing.T) {
+	store := NewStore()
+	if _, err := store.Seller("tlamagames"); !errors.Is(err, ErrNotReady) {
+		t.Fatalf("expected ErrNotReady, got %v", err)
+	}
+	job := NewJob(&fakeLoader{source: sourceRows{
+		Sellers: []string{"tlamagames", "deskovehry"},
+		Summary: []aggregateRow{{Seller: "tlamagames", ProductDays: 1}},
+	}}, store, time.Hour, time.Second)
+	if err := job.refresh(context.Background()); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if _, err := store.Seller("tlamagames"); err != nil {
+		t.Fatalf("expected stats, got %v", err)
+	}
+	alone, err := store.Seller("deskovehry")
+	if err != nil || alone.Summary.ProductDays != 0 || alone.Monthly == nil || alone.WindowDays != SummaryWindowDays {
+		t.Fatalf("a known seller without shared products must get an empty report, got %#v (%v)", alone, err)
+	}
+	if _, err := store.Seller("unknown"); !errors.Is(err, ErrSellerNotFound) {
+		t.Fatalf("expected ErrSellerNotFound, got %v", err)
+	}
+}
+
+func TestFailedRefreshKeepsPreviousReport(t *testing.T) {
+	store := NewStore()
+	loader := &fakeLoader{source: sourceRows{Summary: []aggregateRow{{Seller: "tlamagames"}}}}
+	job := NewJob(loader, store, time.Hour, time.Second)
+	if err := job.refresh(context.Background()); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	loader.err = errors.New("database unavailable")
+	if err := job.refresh(context.Background()); err == nil {
+		t.Fatal("expected refresh error")
+	}
+	if _, err := store.Seller("tlamagames"); err != nil {
+		t.Fatalf("previous report was discarded: %v", err)
+	}
+}
+
+func TestRunStopsWhenContextIsCanceled(t *testing.T) {
+	loader := &fakeLoader{}
+	job := NewJob(loader, NewStore(), time.Hour, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		job.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job did not stop after cancellation")
+	}
+}
+
+func TestAggregateQueriesOnlyCountSharedProductDays(t *testing.T) {
+	for _, query := range []string{summaryQuery, monthlyQuery} {
+		for _, fragment := range []string{
+			"partition by history.canonical_product_id, history.price_date",
+			"where seller_count > 1",
+			"count(*) filter (where closing_price <= market_min_price)",
+			"order by (closing_price / market_min_price)::double precision",
+		} {
+			if !strings.Contains(query, fragment) {
+				t.Fatalf("query missing %q:\n%s", fragment, query)
+			}
+		}
+	}
+	if !strings.Contains(knownSellersQuery, "from public.catalog_slug_seller_state") ||
+		!strings.Contains(knownSellersQuery, "from public.catalog_sellers") {
+		t.Fatal("known sellers must cover offers and the registry")
+	}
+	if !strings.Contains(monthlyQuery, "make_interval(months => $2::integer - 1)") {
+		t.Fatal("monthly query must bound the series by month count")
+	}
+}
@@ -0,0 +1,99 @@
+package snapshots
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	AvailabilityAvailable   = "available"
+	AvailabilityPreorder    = "preorder"
+	AvailabilityUnavailable = "unavailable"
+	AvailabilityUnknown     = "unknown"
+)
+
+type ProductAvailability struct {
+	ProductNameNormalized string               `json:"product_name_normalized"`
+	Sellers               []SellerAvailability `json:"sellers"`
+}
+
+type SellerAvailability struct {
+	Seller  string              `json:"seller"`
+	Periods []AvailabilityRun   `json:"periods"`
+	Summary AvailabilitySummary `json:"summary"`
+}
+
+// AvailabilityRun covers consecutive checked days with one status. A day
+// without a check always ends the run, so unobserved days are never claimed.
+type AvailabilityRun struct {
+	Status string `json:"status"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Days   int    `json:"days"`
+}
+
+type AvailabilitySummary struct {
+	TrackedDays        int     `json:"tracked_days"`
+	AvailablePercent   float64 `json:"available_percent"`
+	PreorderPercent    float64 `json:"preorder_percent"`
+	UnavailablePercent float64 `json:"unavailable_percent"`
+	UnknownPercent     float64 `json:"unknown_percent"`
+}
+
+type availabilityDay struct {
+	date   string
+	status string
+}
+
+func buildSellerAvailability(seller string, days []availabilityDay) SellerAvailability {
+	result := SellerAvailability{Seller: seller, Periods: make([]AvailabilityRun, 0, 8)}
+	counts := make(map[string]int, 4)
+	var previousDate time.Time
+	for _, day := range days {
+		status := normalizeAvailabilityStatus(day.status)
+		counts[status]++
+		date, err := time.Parse(time.DateOnly, day.date)
+		if err != nil {
+			continue
+		}
+		last := len(result.Periods) - 1
+		continuesRun := last >= 0 &&
+			result.Periods[last].Status == status &&
+			date.Sub(previousDate) == 24*time.Hour
+		if continuesRun {
+			result.Periods[last].To = day.date
+			result.Periods[last].Days++
+		} else {
+			result.Periods = append(result.Periods, AvailabilityRun{
+				Status: status, From: day.date, To: day.date, Days: 1,
+			})
+		}
+		previousDate = date
+	}
+	result.Summary = summarizeAvailability(counts, len(days))
+	return result
+}
+
+func normalizeAvailabilityStatus(status string) string {
+	switch status {
+	case AvailabilityAvailable, AvailabilityPreorder, AvailabilityUnavailable:
+		return status
+	default:
+		return AvailabilityUnknown
+	}
+}
+
+func summarizeAvailability(counts map[string]int, trackedDays int) AvailabilitySummary {
+	summary := AvailabilitySummary{TrackedDays: trackedDays}
+	if trackedDays == 0 {
+		return summary
+	}
+	percent := func(status string) float64 {
+		return math.Round(float64(counts[status])*1000/float64(trackedDays)) / 10
+	}
+	summary.AvailablePercent = percent(AvailabilityAvailable)
+	summary.PreorderPercent = percent(AvailabilityPreorder)
+	summary.UnavailablePercent = percent(AvailabilityUnavailable)
+	summary.UnknownPercent = percent(AvailabilityUnknown)
+	return summary
+}
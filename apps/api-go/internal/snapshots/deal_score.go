@@ -0,0 +1,47 @@
+package snapshots
+
+// dealHistoryJoin exposes the reference prices a deal is scored against: the
+// seller's and the whole market's median daily closing price over the 90 days
+// up to the latest scrape, and the lowest closing price any seller ever
+// charged. The outer relation must be aliased offer and provide
+// product_name_normalized, seller and latest_scraped_at.
+const dealHistoryJoin = `
+left join lateral (
+  select
+    percentile_cont(0.5) within group (order by history.closing_price::double precision)
+      filter (
+        where history.seller = offer.seller
+          and history.price_date >= offer.latest_scraped_at::date - 90
+      ) as seller_median_price_90d,
+    percentile_cont(0.5) within group (order by history.closing_price::double precision)
+      filter (where history.price_date >= offer.latest_scraped_at::date - 90)
+      as market_median_price_90d,
+    min(history.closing_price)::double precision as all_time_low_price
+  from public.catalog_daily_price_history history
+  where history.canonical_product_id = offer.product_name_normalized
+    and history.closing_price is not null
+    and history.price_date <= offer.latest_scraped_at::date
+) deal_history on true`
+
+// dealScoreExpression rates the current price from 0 to 100. Savings against
+// the seller median and the market median each contribute 40 points and reach
+// full weight at 30% below the median; a price at the all-time low earns the
+// last 20 points, fading out at 20% above it. Being cheaper than a seller's
+// usual price therefore counts, while a large claimed discount on a product
+// that is normally sold even cheaper scores zero. The score is null without
+// history.
+const dealScoreExpression = `
+    round((
+      40 * least(greatest(
+        (deal_history.seller_median_price_90d - offer.current_price::double precision)
+          / nullif(deal_history.seller_median_price_90d, 0) / 0.3,
+        0), 1)
+      + 40 * least(greatest(
+        (deal_history.market_median_price_90d - offer.current_price::double precision)
+          / nullif(deal_history.market_median_price_90d, 0) / 0.3,
+        0), 1)
+      + 20 * least(greatest(
+        1 - (offer.current_price::double precision - deal_history.all_time_low_price)
+          / nullif(deal_history.all_time_low_price, 0) / 0.2,
+        0), 1)
+    )::numeric, 1)::double precision`
@@ -0,0 +1,221 @@
+package snapshots
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tlamasite/apps/api-go/internal/catalog"
+)
+
+var ErrInvalidCursor = errors.New("cursor is invalid")
+
+// DiscountCursor is the keyset position of the last returned discount. It
+// records the sort it was issued for so it cannot be replayed against the
+// other ordering.
+type DiscountCursor struct {
+	Sort            string    `json:"sort"`
+	ChangedAt       time.Time `json:"changed_at,omitzero"`
+	DiscountPercent float64   `json:"discount_percent,omitempty"`
+	DealScore       float64   `json:"deal_score,omitempty"`
+	Slug            string    `json:"slug"`
+	Seller          string    `json:"seller"`
+}
+
+func (cursor DiscountCursor) Encode() string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeDiscountCursor(encoded string, sort string) (DiscountCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return DiscountCursor{}, ErrInvalidCursor
+	}
+	var cursor DiscountCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return DiscountCursor{}, ErrInvalidCursor
+	}
+	if cursor.Sort != normalizeDiscountSort(sort) || cursor.Slug == "" || cursor.Seller == "" {
+		return DiscountCursor{}, ErrInvalidCursor
+	}
+	if cursor.Sort == DiscountSortRecent && cursor.ChangedAt.IsZero() {
+		return DiscountCursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+func normalizeDiscountSort(sort string) string {
+	switch sort {
+	case DiscountSortDiscount, DiscountSortDeal:
+		return sort
+	}
+	return DiscountSortRecent
+}
+
+func buildRecentDiscountsQuery(filters DiscountFilters) (string, []any) {
+	args := make([]any, 0, 8)
+	offerClauses := buildOfferClauses(&args, filters.Sellers, filters.Categories, filters.Availability)
+	if filters.MaxPrice != nil {
+		args = append(args, *filters.MaxPrice)
+		offerClauses = append(offerClauses, fmt.Sprintf("latest_price <= $%d", len(args)))
+	}
+
+	clauses := make([]string, 0, 3)
+	if filters.HideInflated {
+		clauses = append(clauses, "discount_verdict is distinct from 'inflated_reference'")
+	}
+	if filters.MinDiscountPercent != nil {
+		args = append(args, *filters.MinDiscountPercent)
+		clauses = append(clauses, fmt.Sprintf("discount_percent >= $%d", len(args)))
+	}
+	sort := normalizeDiscountSort(filters.Sort)
+	if filters.Cursor != nil {
+		clauses = append(clauses, buildDiscountKeysetClause(&args, sort, *filters.Cursor))
+	}
+
+	// The history lookups run per offer, so they join before the limit only
+	// when the inflated filter or the deal sort reads them.
+	verdictBeforeLimit := filters.HideInflated
+	dealBeforeLimit := sort == DiscountSortDeal
+
+	var builder strings.Builder
+	builder.WriteString(recentDiscountOffersSelect)
+	for _, clause := range offerClauses {
+		builder.WriteString("\n    and " + clause)
+	}
+	builder.WriteString(recentDiscountsClassify)
+	if verdictBeforeLimit {
+		builder.WriteString("," + discountVerdictColumn)
+	}
+	if dealBeforeLimit {
+		builder.WriteString("," + dealScoreColumns)
+	}
+	builder.WriteString("\n  from offer")
+	if verdictBeforeLimit {
+		builder.WriteString(recentSellerHighJoin)
+	}
+	if dealBeforeLimit {
+		builder.WriteString(dealHistoryJoin)
+	}
+	builder.WriteString("\n),\ndiscount_page as (\n  select *\n  from classified_offer")
+	if len(clauses) > 0 {
+		builder.WriteString("\n  where " + strings.Join(clauses, "\n    and "))
+	}
+	sortColumn, _ := discountSortColumn(sort)
+	orderBy := "\norder by " + sortColumn + " desc, product_name_normalized asc, seller asc"
+	builder.WriteString(strings.ReplaceAll(orderBy, "\n", "\n  "))
+	args = append(args, filters.Limit+1)
+	fmt.Fprintf(&builder, "\n  limit $%d\n)", len(args))
+
+	builder.WriteString(recentDiscountsPageSelect)
+	if verdictBeforeLimit {
+		builder.WriteString("\n  offer.discount_verdict,")
+	} else {
+		builder.WriteString(discountVerdictColumn + ",")
+	}
+	if dealBeforeLimit {
+		builder.WriteString(`
+  offer.deal_score,
+  offer.seller_median_price_90d,
+  offer.market_median_price_90d,
+  offer.all_time_low_price,`)
+	} else {
+		builder.WriteString(dealScoreExpression + ` as deal_score,
+  deal_history.seller_median_price_90d,
+  deal_history.market_median_price_90d,
+  deal_history.all_time_low_price,`)
+	}
+	builder.WriteString("\n  offer.latest_scraped_at\nfrom discount_page offer")
+	if !verdictBeforeLimit {
+		builder.WriteString(recentSellerHighJoin)
+	}
+	if !dealBeforeLimit {
+		builder.WriteString(dealHistoryJoin)
+	}
+	builder.WriteString(orderBy + ";")
+	return builder.String(), args
+}
+
+// buildOfferClauses filters catalog_slug_seller_state rows by seller, curated
+// category and availability.
+func buildOfferClauses(args *[]any, sellers []string, categories []string, availability string) []string {
+	clauses := make([]string, 0, 4)
+	if len(sellers) > 0 {
+		*args = append(*args, sellers)
+		clauses = append(clauses, fmt.Sprintf("seller = any($%d::text[])", len(*args)))
+	}
+	if categoryClause := catalog.CategoryClause(args, categories); categoryClause != "" {
+		clauses = append(clauses, categoryClause)
+	}
+	switch availability {
+	case "available":
+		clauses = append(clauses, "is_available = true")
+	case "preorder":
+		clauses = append(clauses, "is_preorder = true")
+	}
+	return clauses
+}
+
+// discountSortColumn returns the descending sort expression and its SQL type.
+// Nullable scores sort as zero so the keyset comparison stays total.
+func discountSortColumn(sort string) (string, string) {
+	switch sort {
+	case DiscountSortDiscount:
+		return "coalesce(discount_percent, 0)", "double precision"
+	case DiscountSortDeal:
+		return "coalesce(deal_score, 0)", "double precision"
+	}
+	return "latest_scraped_at", "timestamptz"
+}
+
+func buildDiscountKeysetClause(args *[]any, sort string, cursor DiscountCursor) string {
+	sortColumn, sortCast := discountSortColumn(sort)
+	var sortValue any = cursor.ChangedAt
+	switch sort {
+	case DiscountSortDiscount:
+		sortValue = cursor.DiscountPercent
+	case DiscountSortDeal:
+		sortValue = cursor.DealScore
+	}
+	*args = append(*args, sortValue, cursor.Slug, cursor.Seller)
+	value, slug, seller := len(*args)-2, len(*args)-1, len(*args)
+	return fmt.Sprintf(
+		"(%[1]s < $%[2]d::%[3]s or (%[1]s = $%[2]d::%[3]s and (product_name_normalized, seller) > ($%[4]d, $%[5]d)))",
+		sortColumn, value, sortCast, slug, seller,
+	)
+}
+
+// paginateDiscounts trims the lookahead row and derives the cursor from the
+// last row that is returned.
+func paginateDiscounts(rows []RecentDiscount, filters DiscountFilters) DiscountPage {
+	page := DiscountPage{Rows: rows}
+	if len(rows) <= filters.Limit {
+		return page
+	}
+	page.Rows = rows[:filters.Limit]
+	last := page.Rows[filters.Limit-1]
+	cursor := DiscountCursor{
+		Sort:   normalizeDiscountSort(filters.Sort),
+		Slug:   last.ProductNameNormalized,
+		Seller: last.Seller,
+	}
+	switch cursor.Sort {
+	case DiscountSortDiscount:
+		if last.DiscountPercent != nil {
+			cursor.DiscountPercent = *last.DiscountPercent
+		}
+	case DiscountSortDeal:
+		if last.DealScore != nil {
+			cursor.DealScore = *last.DealScore
+		}
+	default:
+		cursor.ChangedAt = last.changedAt
+	}
+	encoded := cursor.Encode()
+	page.NextCursor = &encoded
+	return page
+}
@@ -0,0 +1,38 @@
+package snapshots
+
+// Discount verdicts compare a claimed reference price with the prices the same
+// seller actually charged during the 30 days before its latest check.
+const (
+	DiscountVerdictGenuine           = "genuine"
+	DiscountVerdictInflatedReference = "inflated_reference"
+	DiscountVerdictNoRealChange      = "no_real_change"
+)
+
+// recentSellerHighJoin exposes recent_high_price, the highest intraday price of
+// one seller in the 30 days preceding its latest scrape. The outer relation must
+// be aliased offer and provide product_name_normalized, seller and
+// latest_scraped_at.
+const recentSellerHighJoin = `
+left join lateral (
+  select max(history.max_price) as recent_high_price
+  from public.catalog_daily_price_history history
+  where history.canonical_product_id = offer.product_name_normalized
+    and history.seller = offer.seller
+    and history.price_date >= offer.latest_scraped_at::date - 30
+    and history.price_date < offer.latest_scraped_at::date
+) recent_history on true`
+
+// discountVerdictCase additionally reads offer.current_price and
+// offer.reference_price. It is null when no discount is claimed or the seller
+// has no earlier history to verify it against. A 1% tolerance absorbs rounding.
+const discountVerdictCase = `
+  case
+    when offer.reference_price is null
+      or offer.current_price is null
+      or offer.current_price >= offer.reference_price then null
+    when recent_history.recent_high_price is null then null
+    when offer.current_price >= recent_history.recent_high_price then 'no_real_change'
+    when offer.reference_price > recent_history.recent_high_price * 1.01
+      then 'inflated_reference'
+    else 'genuine'
+  end`
@@ -3,43 +3,75 @@ package snapshots
 import (
 	"encoding/json"
 	"errors"
+	"time"
+
+	"tlamasite/apps/api-go/internal/pricetrend"
 )
 
 var ErrProductNotFound = errors.New("product not found")
 
 type ProductDetail struct {
 	ProductNameNormalized string   `json:"product_name_normalized"`
+	RequestedSlug         string   `json:"requested_slug"`
 	Sellers               []Seller `json:"sellers"`
 }
 
 type Seller struct {
-	Seller                  string          `json:"seller"`
-	ProductCode             *string         `json:"product_code"`
-	ProductName             *string         `json:"product_name"`
-	CurrencyCode            *string         `json:"currency_code"`
-	AvailabilityLabel       *string         `json:"availability_label"`
-	StockStatusLabel        *string         `json:"stock_status_label"`
-	LatestPrice             *float64        `json:"latest_price"`
-	PreviousPrice           *float64        `json:"previous_price"`
-	FirstPrice              *float64        `json:"first_price"`
-	ListPriceWithVat        *float64        `json:"list_price_with_vat"`
-	SourceURL               *string         `json:"source_url"`
-	LatestScrapedAt         *string         `json:"latest_scraped_at"`
-	HeroImageURL            *string         `json:"hero_image_url"`
-	GalleryImageURLs        []string        `json:"gallery_image_urls"`
-	ShortDescription        *string         `json:"short_description"`
-	SupplementaryParameters json.RawMessage `json:"supplementary_parameters"`
-	Metadata                json.RawMessage `json:"metadata"`
-	History                 []PricePoint    `json:"history"`
+	Seller                  string            `json:"seller"`
+	ProductCode             *string           `json:"product_code"`
+	ProductName             *string           `json:"product_name"`
+	CurrencyCode            *string           `json:"currency_code"`
+	AvailabilityLabel       *string           `json:"availability_label"`
+	StockStatusLabel        *string           `json:"stock_status_label"`
+	LatestPrice             *float64          `json:"latest_price"`
+	PreviousPrice           *float64          `json:"previous_price"`
+	FirstPrice              *float64          `json:"first_price"`
+	ListPriceWithVat        *float64          `json:"list_price_with_vat"`
+	SourceURL               *string           `json:"source_url"`
+	LatestScrapedAt         *string           `json:"latest_scraped_at"`
+	HeroImageURL            *string           `json:"hero_image_url"`
+	GalleryImageURLs        []string          `json:"gallery_image_urls"`
+	ShortDescription        *string           `json:"short_description"`
+	SupplementaryParameters json.RawMessage   `json:"supplementary_parameters"`
+	Metadata                json.RawMessage   `json:"metadata"`
+	DiscountVerdict         *string           `json:"discount_verdict"`
+	Stale                   bool              `json:"stale"`
+	Profile                 SellerProfile     `json:"profile"`
+	EstimatedTotalPrice     *float64          `json:"estimated_total_price"`
+	PriceTrend              *pricetrend.Trend `json:"price_trend"`
+	History                 []PricePoint      `json:"history"`
+}
+
+// HistoryOptions bounds and shapes the per-seller history attached to a
+// product detail. Intraday fields are opt-in to keep the default payload small.
+type HistoryOptions struct {
+	Points   int
+	Intraday bool
 }
 
 type PricePoint struct {
-	PriceDate        string   `json:"price_date"`
-	PriceWithVat     *float64 `json:"price_with_vat"`
-	ListPriceWithVat *float64 `json:"list_price_with_vat"`
-	CurrencyCode     *string  `json:"currency_code"`
-	ScrapedAt        string   `json:"scraped_at"`
-	SnapshotCount    int32    `json:"snapshot_count"`
+	PriceDate          string   `json:"price_date"`
+	PriceWithVat       *float64 `json:"price_with_vat"`
+	ListPriceWithVat   *float64 `json:"list_price_with_vat"`
+	CurrencyCode       *string  `json:"currency_code"`
+	ScrapedAt          string   `json:"scraped_at"`
+	SnapshotCount      int32    `json:"snapshot_count"`
+	OpeningPrice       *float64 `json:"opening_price,omitempty"`
+	MinPrice           *float64 `json:"min_price,omitempty"`
+	MaxPrice           *float64 `json:"max_price,omitempty"`
+	AvailabilityStatus *string  `json:"availability_status,omitempty"`
+	IsAvailable        *bool    `json:"is_available,omitempty"`
+	IsPreorder         *bool    `json:"is_preorder,omitempty"`
+}
+
+func (point PricePoint) withoutIntraday() PricePoint {
+	point.OpeningPrice = nil
+	point.MinPrice = nil
+	point.MaxPrice = nil
+	point.AvailabilityStatus = nil
+	point.IsAvailable = nil
+	point.IsPreorder = nil
+	return point
 }
 
 type RecentDiscount struct {
@@ -50,6 +82,64 @@ type RecentDiscount struct {
 	CurrencyCode          *string  `json:"currency_code"`
 	CurrentPrice          *float64 `json:"current_price"`
 	ReferencePrice        *float64 `json:"reference_price"`
+	DiscountPercent       *float64 `json:"discount_percent"`
 	SourceURL             *string  `json:"source_url"`
 	ChangedAt             *string  `json:"changed_at"`
+	DiscountVerdict       *string  `json:"discount_verdict"`
+	DealScore             *float64 `json:"deal_score"`
+	SellerMedianPrice90d  *float64 `json:"seller_median_price_90d"`
+	MarketMedianPrice90d  *float64 `json:"market_median_price_90d"`
+	AllTimeLowPrice       *float64 `json:"all_time_low_price"`
+
+	// changedAt is the exact scrape time behind ChangedAt; recency cursors
+	// need it at full precision.
+	changedAt time.Time
+}
+
+const (
+	DiscountSortRecent   = "recent"
+	DiscountSortDiscount = "discount"
+	DiscountSortDeal     = "deal_score"
+)
+
+type DiscountFilters struct {
+	Limit              int
+	HideInflated       bool
+	Sellers            []string
+	Categories         []string
+	Availability       string
+	MinDiscountPercent *float64
+	MaxPrice           *float64
+	// Sort is one of the DiscountSort values; empty means recent.
+	Sort   string
+	Cursor *DiscountCursor
+}
+
+type DiscountPage struct {
+	Rows       []RecentDiscount `json:"rows"`
+	NextCursor *string          `json:"next_cursor"`
+}
+
+const DefaultArrivalWindowDays = 30
+
+// NewArrival is a seller offer whose first daily history row is recent. A
+// product that gains a new seller therefore appears once per seller.
+type NewArrival struct {
+	ProductNameNormalized string    `json:"product_name_normalized"`
+	Seller                string    `json:"seller"`
+	ProductCode           *string   `json:"product_code"`
+	ProductName           *string   `json:"product_name"`
+	CurrencyCode          *string   `json:"currency_code"`
+	CurrentPrice          *float64  `json:"current_price"`
+	SourceURL             *string   `json:"source_url"`
+	HeroImageURL          *string   `json:"hero_image_url"`
+	FirstSeenAt           time.Time `json:"first_seen_at"`
+}
+
+type ArrivalFilters struct {
+	Limit        int
+	Sellers      []string
+	Categories   []string
+	Availability string
+	WindowDays   int
 }
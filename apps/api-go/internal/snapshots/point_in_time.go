@@ -0,0 +1,21 @@
+package snapshots
+
+type ProductPriceAt struct {
+	ProductNameNormalized string          `json:"product_name_normalized"`
+	Date                  string          `json:"date"`
+	Sellers               []SellerPriceAt `json:"sellers"`
+}
+
+// SellerPriceAt is a seller's last checked day on or before the requested
+// date. CarriedForward reports that the seller was not checked on that date.
+type SellerPriceAt struct {
+	Seller             string   `json:"seller"`
+	PriceDate          string   `json:"price_date"`
+	CarriedForward     bool     `json:"carried_forward"`
+	CurrencyCode       *string  `json:"currency_code"`
+	ClosingPrice       *float64 `json:"closing_price"`
+	ListPriceWithVat   *float64 `json:"list_price_with_vat"`
+	AvailabilityStatus *string  `json:"availability_status"`
+	IsAvailable        *bool    `json:"is_available"`
+	IsPreorder         *bool    `json:"is_preorder"`
+}
@@ -1,37 +1,59 @@
 package snapshots
 
+import (
+	"fmt"
+	"strings"
+)
+
 const sellerMetadataQuery = `
 with requested_product as (
   select public.canonical_product_slug(null, null, $1) as canonical_product_id
+),
+offer as (
+  select
+    seller_state.*,
+    seller_state.latest_price as current_price,
+    seller_state.list_price_with_vat as reference_price
+  from requested_product requested
+  join public.catalog_slug_seller_state seller_state
+    on seller_state.product_name_normalized = requested.canonical_product_id
 )
 select
-  requested.canonical_product_id,
-  seller_state.seller,
-  seller_state.product_code,
-  seller_state.product_name,
-  seller_state.currency_code,
-  seller_state.availability_label,
-  seller_state.stock_status_label,
-  seller_state.latest_price::double precision,
-  seller_state.previous_price::double precision,
-  seller_state.first_price::double precision,
-  seller_state.list_price_with_vat::double precision,
-  seller_state.source_url,
-  seller_state.latest_scraped_at::text,
-  seller_state.hero_image_url,
-  coalesce(seller_state.gallery_image_urls, '{}'::text[]),
-  seller_state.short_description,
-  coalesce(seller_state.supplementary_parameters, '[]'::jsonb),
-  coalesce(seller_state.metadata, '{}'::jsonb)
-from requested_product requested
-join public.catalog_slug_seller_state seller_state
-  on seller_state.product_name_normalized = requested.canonical_product_id
+  offer.product_name_normalized,
+  offer.seller,
+  offer.product_code,
+  offer.product_name,
+  offer.currency_code,
+  offer.availability_label,
+  offer.stock_status_label,
+  offer.latest_price::double precision,
+  offer.previous_price::double precision,
+  offer.first_price::double precision,
+  offer.list_price_with_vat::double precision,
+  offer.source_url,
+  offer.latest_scraped_at::text,
+  offer.hero_image_url,
+  coalesce(offer.gallery_image_urls, '{}'::text[]),
+  offer.short_description,
+  coalesce(offer.supplementary_parameters, '[]'::jsonb),
+  coalesce(offer.metadata, '{}'::jsonb),` + discountVerdictCase + ` as discount_verdict,
+  coalesce(
+    offer.latest_scraped_at < now() - public.catalog_seller_stale_after(offer.seller),
+    true
+  ) as stale,
+  coalesce(nullif(trim(registry.display_name), ''), offer.seller),
+  registry.homepage_url,
+  registry.logo_path,
+  registry.shipping_currency_code,
+  registry.free_shipping_threshold::double precision,
+  registry.flat_shipping_cost::double precision,
+  coalesce(registry.pickup_options, '{}'::text[])
+from offer` + recentSellerHighJoin + `
+left join public.catalog_sellers registry
+  on registry.seller = offer.seller
 order by
-  case
-    when seller_state.seller in ('tlamagames', 'tlamagase') then 0
-    else 1
-  end,
-  seller_state.seller asc;`
+  public.seller_priority(offer.seller) asc,
+  offer.seller asc;`
 
 const priceHistoryQuery = `
 with requested_product as (
@@ -55,31 +77,295 @@ select
   list_price_with_vat::double precision,
   currency_code,
   last_scraped_at::text,
-  snapshot_count
+  snapshot_count,
+  opening_price::double precision,
+  min_price::double precision,
+  max_price::double precision,
+  availability_status,
+  is_available,
+  is_preorder
 from ranked_history
 where $2 = 0 or seller_row_number <= $2
 order by seller asc, price_date asc;`
 
-const recentDiscountsQuery = `
+// recentDiscountOffersSelect is left open so seller-state filters can be
+// appended before recentDiscountsClassify closes the offer CTE.
+const recentDiscountOffersSelect = `
+with offer as (
+  select
+    product_name_normalized,
+    seller,
+    product_code,
+    product_name,
+    currency_code,
+    latest_price as current_price,
+    case
+      when previous_price is not null and latest_price < previous_price
+        then previous_price
+      else list_price_with_vat
+    end as reference_price,
+    source_url,
+    latest_scraped_at
+  from public.catalog_slug_seller_state
+  where latest_price is not null
+    and latest_scraped_at is not null
+    and (
+      (previous_price is not null and latest_price < previous_price)
+      or (list_price_with_vat is not null and latest_price < list_price_with_vat)
+    )`
+
+// recentDiscountsClassify closes the offer CTE. The verdict and deal columns
+// read lateral history lookups, so buildRecentDiscountsQuery adds them here
+// only when a filter or the sort needs them before the limit, and otherwise
+// computes them for the returned page alone.
+const recentDiscountsClassify = `
+),
+classified_offer as (
+  select
+    offer.*,
+    round(
+      (offer.reference_price - offer.current_price) * 100 / nullif(offer.reference_price, 0),
+      2
+    )::double precision as discount_percent`
+
+const discountVerdictColumn = discountVerdictCase + ` as discount_verdict`
+
+const dealScoreColumns = `
+    deal_history.seller_median_price_90d,
+    deal_history.market_median_price_90d,
+    deal_history.all_time_low_price,` + dealScoreExpression + ` as deal_score`
+
+// recentDiscountsPageSelect reads the page back under the offer alias the
+// history joins expect.
+const recentDiscountsPageSelect = `
+select
+  offer.product_name_normalized,
+  offer.seller,
+  offer.product_code,
+  offer.product_name,
+  offer.currency_code,
+  offer.current_price::double precision,
+  offer.reference_price::double precision,
+  offer.discount_percent,
+  offer.source_url,
+  offer.latest_scraped_at::text as changed_at,`
+
+const trendHistoryQuery = `
+with requested_product as (
+  select public.canonical_product_slug(null, null, $1) as canonical_product_id
+),
+seller_history as (
+  select
+    history.seller,
+    history.price_date,
+    history.closing_price,
+    max(history.price_date) over (partition by history.seller) as latest_price_date
+  from public.catalog_daily_price_history history
+  join requested_product requested
+    on requested.canonical_product_id = history.canonical_product_id
+  where history.closing_price is not null
+)
 select
-  product_name_normalized,
   seller,
-  product_code,
-  product_name,
-  currency_code,
-  latest_price::double precision,
-  case
-    when previous_price is not null and latest_price < previous_price
-      then previous_price::double precision
-    else list_price_with_vat::double precision
-  end as reference_price,
-  source_url,
-  latest_scraped_at::text
-from public.catalog_slug_seller_state
-where latest_price is not null
-  and (
-    (previous_price is not null and latest_price < previous_price)
-    or (list_price_with_vat is not null and latest_price < list_price_with_vat)
+  price_date,
+  closing_price::double precision
+from seller_history
+where price_date > latest_price_date - 365
+order by seller asc, price_date asc;`
+
+const priceStatsQuery = `
+with requested_product as (
+  select public.canonical_product_slug(null, null, $1) as canonical_product_id
+),
+seller_history as (
+  select
+    history.canonical_product_id,
+    history.seller,
+    history.price_date,
+    history.currency_code,
+    history.closing_price,
+    history.min_price,
+    history.max_price,
+    max(history.price_date) over (partition by history.seller) as latest_price_date
+  from public.catalog_daily_price_history history
+  join requested_product requested
+    on requested.canonical_product_id = history.canonical_product_id
+)
+select
+  canonical_product_id,
+  seller,
+  (array_agg(currency_code order by price_date desc))[1]::text,
+  (array_agg(closing_price order by price_date desc))[1]::double precision,
+  min(min_price)::double precision,
+  max(max_price)::double precision,
+  (min(min_price) filter (
+    where price_date >= latest_price_date - 30 and price_date < latest_price_date
+  ))::double precision,
+  percentile_cont(0.5) within group (order by closing_price) filter (
+    where price_date > latest_price_date - 90
+  ),
+  (avg(closing_price) filter (where price_date > latest_price_date - 90))::double precision,
+  count(*)::integer,
+  min(price_date)::text,
+  max(price_date)::text
+from seller_history
+group by canonical_product_id, seller
+order by seller asc;`
+
+// The alias lookup mirrors the slug-only branch of canonical_product_slug and
+// is used only to describe the alias; the canonical slug comes from the function.
+const slugResolutionQuery = `
+with requested_product as (
+  select public.canonical_product_slug(null, null, $1) as canonical_product_id
+),
+matched_alias as (
+  select
+    case
+      when nullif(trim(coalesce(alias.seller, '')), '') is not null
+        and nullif(trim(coalesce(alias.product_code, '')), '') is not null
+        then '` + AliasKindSellerProductCode + `'
+      else '` + AliasKindSlugOnly + `'
+    end as alias_kind
+  from public.canonical_product_aliases alias
+  where lower(trim(alias.product_name_normalized)) = lower(trim($1))
+  order by alias.confidence desc, alias.updated_at desc
+  limit 1
+)
+select
+  requested.canonical_product_id,
+  (select alias_kind from matched_alias),
+  exists (
+    select 1
+    from public.catalog_slug_state state
+    where state.product_name_normalized = requested.canonical_product_id
+  )
+from requested_product requested;`
+
+const priceAtDateQuery = `
+with requested_product as (
+  select public.canonical_product_slug(null, null, $1) as canonical_product_id
+)
+select distinct on (history.seller)
+  history.canonical_product_id,
+  history.seller,
+  history.price_date::text,
+  history.price_date < $2::date,
+  history.currency_code,
+  history.closing_price::double precision,
+  history.list_price_with_vat::double precision,
+  history.availability_status,
+  history.is_available,
+  history.is_preorder
+from public.catalog_daily_price_history history
+join requested_product requested
+  on requested.canonical_product_id = history.canonical_product_id
+where history.price_date <= $2::date
+order by history.seller asc, history.price_date desc;`
+
+// productHistoryExistsQuery tells a product that is known to the catalog or
+// its history apart from an unknown slug when no seller has a price on or
+// before the requested date.
+const productHistoryExistsQuery = `
+with requested_product as (
+  select public.canonical_product_slug(null, null, $1) as canonical_product_id
+)
+select
+  requested.canonical_product_id,
+  exists (
+    select 1
+    from public.catalog_slug_state state
+    where state.product_name_normalized = requested.canonical_product_id
+  ) or exists (
+    select 1
+    from public.catalog_daily_price_history history
+    where history.canonical_product_id = requested.canonical_product_id
   )
-order by latest_scraped_at desc nulls last, product_name_normalized asc, seller asc
-limit $1;`
+from requested_product requested;`
+
+const availabilityHistoryQuery = `
+with requested_product as (
+  select public.canonical_product_slug(null, null, $1) as canonical_product_id
+)
+select
+  history.canonical_product_id,
+  history.seller,
+  history.price_date::text,
+  history.availability_status
+from public.catalog_daily_price_history history
+join requested_product requested
+  on requested.canonical_product_id = history.canonical_product_id
+order by history.seller asc, history.price_date asc;`
+
+const newArrivalOffersSelect = `
+with offer as (
+  select
+    product_name_normalized,
+    seller,
+    product_code,
+    product_name,
+    currency_code,
+    latest_price,
+    source_url,
+    hero_image_url
+  from public.catalog_slug_seller_state
+  where latest_price is not null`
+
+// newArrivalsSelect is a format string taking the window-days placeholder
+// number. Candidates come from history rows dated inside the window; an offer
+// with any row before the window start is not new, which the primary key
+// answers without reading the offer's whole history.
+const newArrivalsSelect = `
+),
+window_history as (
+  select
+    history.canonical_product_id,
+    history.seller,
+    min(history.first_scraped_at) as first_scraped_at
+  from public.catalog_daily_price_history history
+  where history.price_date >= (now() - make_interval(days => $%[1]d))::date
+  group by history.canonical_product_id, history.seller
+)
+select
+  offer.product_name_normalized,
+  offer.seller,
+  offer.product_code,
+  offer.product_name,
+  offer.currency_code,
+  offer.latest_price::double precision,
+  offer.source_url,
+  offer.hero_image_url,
+  first_seen.first_scraped_at
+from window_history first_seen
+join offer
+  on offer.product_name_normalized = first_seen.canonical_product_id
+  and offer.seller = first_seen.seller
+where first_seen.first_scraped_at >= now() - make_interval(days => $%[1]d)
+  and not exists (
+    select 1
+    from public.catalog_daily_price_history earlier
+    where earlier.canonical_product_id = first_seen.canonical_product_id
+      and earlier.seller = first_seen.seller
+      and earlier.price_date < (now() - make_interval(days => $%[1]d))::date
+  )`
+
+func buildNewArrivalsQuery(filters ArrivalFilters) (string, []any) {
+	args := make([]any, 0, 6)
+	var builder strings.Builder
+	builder.WriteString(newArrivalOffersSelect)
+	for _, clause := range buildOfferClauses(&args, filters.Sellers, filters.Categories, filters.Availability) {
+		builder.WriteString("\n    and " + clause)
+	}
+	windowDays := filters.WindowDays
+	if windowDays < 1 {
+		windowDays = DefaultArrivalWindowDays
+	}
+	args = append(args, windowDays, filters.Limit)
+	fmt.Fprintf(&builder, newArrivalsSelect, len(args)-1)
+	fmt.Fprintf(
+		&builder,
+		"\norder by first_seen.first_scraped_at desc, offer.product_name_normalized asc, offer.seller asc"+
+			"\nlimit $%d;",
+		len(args),
+	)
+	return builder.String(), args
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"tlamasite/apps/api-go/internal/pricetrend"
 )
 
 type Repository struct {
@@ -19,7 +21,7 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 func (repository *Repository) BySlug(
 	ctx context.Context,
 	slug string,
-	historyPoints int,
+	history HistoryOptions,
 ) (ProductDetail, error) {
 	detail, err := repository.fetchSellerMetadata(ctx, slug)
 	if err != nil {
@@ -28,23 +30,48 @@ func (repository *Repository) BySlug(
 	if len(detail.Sellers) == 0 {
 		return ProductDetail{}, ErrProductNotFound
 	}
-	if err := repository.attachPriceHistory(ctx, &detail, slug, historyPoints); err != nil {
+	if err := repository.attachPriceHistory(ctx, &detail, slug, history); err != nil {
+		return ProductDetail{}, err
+	}
+	if err := repository.attachPriceTrends(ctx, &detail, slug); err != nil {
 		return ProductDetail{}, err
 	}
+	detail.RequestedSlug = slug
 	return detail, nil
 }
 
+func (repository *Repository) ResolveSlug(ctx context.Context, slug string) (SlugResolution, error) {
+	resolution := SlugResolution{RequestedSlug: slug}
+	var exists bool
+	if err := repository.db.QueryRow(ctx, slugResolutionQuery, slug).Scan(
+		&resolution.Canonical,
+		&resolution.AliasKind,
+		&exists,
+	); err != nil {
+		return SlugResolution{}, err
+	}
+	if !exists {
+		return SlugResolution{}, ErrProductNotFound
+	}
+	resolution.IsAlias = resolution.Canonical != slug
+	if !resolution.IsAlias {
+		resolution.AliasKind = nil
+	}
+	return resolution, nil
+}
+
 func (repository *Repository) RecentDiscounts(
 	ctx context.Context,
-	limit int,
-) ([]RecentDiscount, error) {
-	rows, err := repository.db.Query(ctx, recentDiscountsQuery, limit)
+	filters DiscountFilters,
+) (DiscountPage, error) {
+	query, args := buildRecentDiscountsQuery(filters)
+	rows, err := repository.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return DiscountPage{}, err
 	}
 	defer rows.Close()
 
-	discounts := make([]RecentDiscount, 0, limit)
+	discounts := make([]RecentDiscount, 0, filters.Limit+1)
 	for rows.Next() {
 		var discount RecentDiscount
 		if err := rows.Scan(
@@ -55,14 +82,196 @@ func (repository *Repository) RecentDiscounts(
 			&discount.CurrencyCode,
 			&discount.CurrentPrice,
 			&discount.ReferencePrice,
+			&discount.DiscountPercent,
 			&discount.SourceURL,
 			&discount.ChangedAt,
+			&discount.DiscountVerdict,
+			&discount.DealScore,
+			&discount.SellerMedianPrice90d,
+			&discount.MarketMedianPrice90d,
+			&discount.AllTimeLowPrice,
+			&discount.changedAt,
 		); err != nil {
-			return nil, err
+			return DiscountPage{}, err
 		}
 		discounts = append(discounts, discount)
 	}
-	return discounts, rows.Err()
+	if err := rows.Err(); err != nil {
+		return DiscountPage{}, err
+	}
+	return paginateDiscounts(discounts, filters), nil
+}
+
+func (repository *Repository) NewArrivals(
+	ctx context.Context,
+	filters ArrivalFilters,
+) ([]NewArrival, error) {
+	query, args := buildNewArrivalsQuery(filters)
+	rows, err := repository.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	arrivals := make([]NewArrival, 0, filters.Limit)
+	for rows.Next() {
+		var arrival NewArrival
+		if err := rows.Scan(
+			&arrival.ProductNameNormalized,
+			&arrival.Seller,
+			&arrival.ProductCode,
+			&arrival.ProductName,
+			&arrival.CurrencyCode,
+			&arrival.CurrentPrice,
+			&arrival.SourceURL,
+			&arrival.HeroImageURL,
+			&arrival.FirstSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		arrivals = append(arrivals, arrival)
+	}
+	return arrivals, rows.Err()
+}
+
+func (repository *Repository) PriceStats(
+	ctx context.Context,
+	slug string,
+) (ProductPriceStats, error) {
+	rows, err := repository.db.Query(ctx, priceStatsQuery, slug)
+	if err != nil {
+		return ProductPriceStats{}, err
+	}
+	defer rows.Close()
+
+	stats := ProductPriceStats{Sellers: make([]SellerPriceStats, 0, 8)}
+	for rows.Next() {
+		var sellerStats SellerPriceStats
+		if err := rows.Scan(
+			&stats.ProductNameNormalized,
+			&sellerStats.Seller,
+			&sellerStats.CurrencyCode,
+			&sellerStats.CurrentPrice,
+			&sellerStats.AllTimeLow,
+			&sellerStats.AllTimeHigh,
+			&sellerStats.LowestPrice30d,
+			&sellerStats.MedianPrice90d,
+			&sellerStats.AveragePrice90d,
+			&sellerStats.TrackedDays,
+			&sellerStats.FirstPriceDate,
+			&sellerStats.LastPriceDate,
+		); err != nil {
+			return ProductPriceStats{}, err
+		}
+		stats.Sellers = append(stats.Sellers, sellerStats)
+	}
+	if err := rows.Err(); err != nil {
+		return ProductPriceStats{}, err
+	}
+	if len(stats.Sellers) == 0 {
+		return ProductPriceStats{}, ErrProductNotFound
+	}
+	stats.Summary = summarizeSellerStats(stats.Sellers)
+	return stats, nil
+}
+
+func (repository *Repository) Availability(
+	ctx context.Context,
+	slug string,
+) (ProductAvailability, error) {
+	rows, err := repository.db.Query(ctx, availabilityHistoryQuery, slug)
+	if err != nil {
+		return ProductAvailability{}, err
+	}
+	defer rows.Close()
+
+	availability := ProductAvailability{Sellers: make([]SellerAvailability, 0, 8)}
+	currentSeller := ""
+	days := make([]availabilityDay, 0, 256)
+	for rows.Next() {
+		var seller string
+		var day availabilityDay
+		if err := rows.Scan(
+			&availability.ProductNameNormalized,
+			&seller,
+			&day.date,
+			&day.status,
+		); err != nil {
+			return ProductAvailability{}, err
+		}
+		if seller != currentSeller && len(days) > 0 {
+			availability.Sellers = append(
+				availability.Sellers,
+				buildSellerAvailability(currentSeller, days),
+			)
+			days = days[:0]
+		}
+		currentSeller = seller
+		days = append(days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return ProductAvailability{}, err
+	}
+	if len(days) == 0 {
+		return ProductAvailability{}, ErrProductNotFound
+	}
+	availability.Sellers = append(
+		availability.Sellers,
+		buildSellerAvailability(currentSeller, days),
+	)
+	return availability, nil
+}
+
+func (repository *Repository) PriceAt(
+	ctx context.Context,
+	slug string,
+	date string,
+) (ProductPriceAt, error) {
+	rows, err := repository.db.Query(ctx, priceAtDateQuery, slug, date)
+	if err != nil {
+		return ProductPriceAt{}, err
+	}
+	defer rows.Close()
+
+	snapshot := ProductPriceAt{Date: date, Sellers: make([]SellerPriceAt, 0, 8)}
+	for rows.Next() {
+		var seller SellerPriceAt
+		if err := rows.Scan(
+			&snapshot.ProductNameNormalized,
+			&seller.Seller,
+			&seller.PriceDate,
+			&seller.CarriedForward,
+			&seller.CurrencyCode,
+			&seller.ClosingPrice,
+			&seller.ListPriceWithVat,
+			&seller.AvailabilityStatus,
+			&seller.IsAvailable,
+			&seller.IsPreorder,
+		); err != nil {
+			return ProductPriceAt{}, err
+		}
+		snapshot.Sellers = append(snapshot.Sellers, seller)
+	}
+	if err := rows.Err(); err != nil {
+		return ProductPriceAt{}, err
+	}
+	if len(snapshot.Sellers) > 0 {
+		return snapshot, nil
+	}
+
+	// A date before the first recorded day is an empty answer for a known
+	// product, not a missing product.
+	var exists bool
+	if err := repository.db.QueryRow(ctx, productHistoryExistsQuery, slug).Scan(
+		&snapshot.ProductNameNormalized,
+		&exists,
+	); err != nil {
+		return ProductPriceAt{}, err
+	}
+	if !exists {
+		return ProductPriceAt{}, ErrProductNotFound
+	}
+	return snapshot, nil
 }
 
 func (repository *Repository) Ping(ctx context.Context) error {
@@ -93,7 +302,9 @@ func (repository *Repository) fetchSellerMetadata(
 }
 
 func scanSeller(rows pgx.Rows, canonicalSlug *string, seller *Seller) error {
-	return rows.Scan(
+	var shipping SellerShipping
+	var shippingCurrency *string
+	err := rows.Scan(
 		canonicalSlug,
 		&seller.Seller,
 		&seller.ProductCode,
@@ -112,16 +323,36 @@ func scanSeller(rows pgx.Rows, canonicalSlug *string, seller *Seller) error {
 		&seller.ShortDescription,
 		&seller.SupplementaryParameters,
 		&seller.Metadata,
+		&seller.DiscountVerdict,
+		&seller.Stale,
+		&seller.Profile.DisplayName,
+		&seller.Profile.HomepageURL,
+		&seller.Profile.LogoPath,
+		&shippingCurrency,
+		&shipping.FreeShippingThreshold,
+		&shipping.FlatShippingCost,
+		&shipping.PickupOptions,
 	)
+	if err != nil {
+		return err
+	}
+	// The currency column is not null, so it is only missing for sellers
+	// without a registry row.
+	if shippingCurrency != nil {
+		shipping.CurrencyCode = *shippingCurrency
+		seller.Profile.Shipping = &shipping
+	}
+	seller.EstimatedTotalPrice = estimateTotalPrice(seller.LatestPrice, seller.CurrencyCode, seller.Profile.Shipping)
+	return nil
 }
 
 func (repository *Repository) attachPriceHistory(
 	ctx context.Context,
 	detail *ProductDetail,
 	slug string,
-	historyPoints int,
+	history HistoryOptions,
 ) error {
-	rows, err := repository.db.Query(ctx, priceHistoryQuery, slug, historyPoints)
+	rows, err := repository.db.Query(ctx, priceHistoryQuery, slug, history.Points)
 	if err != nil {
 		return err
 	}
@@ -131,17 +362,12 @@ func (repository *Repository) attachPriceHistory(
 	for rows.Next() {
 		var sellerID string
 		var point PricePoint
-		if err := rows.Scan(
-			&sellerID,
-			&point.PriceDate,
-			&point.PriceWithVat,
-			&point.ListPriceWithVat,
-			&point.CurrencyCode,
-			&point.ScrapedAt,
-			&point.SnapshotCount,
-		); err != nil {
+		if err := scanPricePoint(rows, &sellerID, &point); err != nil {
 			return err
 		}
+		if !history.Intraday {
+			point = point.withoutIntraday()
+		}
 		if sellerIndex, exists := sellerIndexes[sellerID]; exists {
 			detail.Sellers[sellerIndex].History = append(
 				detail.Sellers[sellerIndex].History,
@@ -152,6 +378,56 @@ func (repository *Repository) attachPriceHistory(
 	return rows.Err()
 }
 
+// attachPriceTrends analyzes a year of closing prices per seller, independent
+// of the history_points bound applied to the returned chart history.
+func (repository *Repository) attachPriceTrends(
+	ctx context.Context,
+	detail *ProductDetail,
+	slug string,
+) error {
+	rows, err := repository.db.Query(ctx, trendHistoryQuery, slug)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	pointsBySeller := make(map[string][]pricetrend.Point, len(detail.Sellers))
+	for rows.Next() {
+		var sellerID string
+		var point pricetrend.Point
+		if err := rows.Scan(&sellerID, &point.Date, &point.Price); err != nil {
+			return err
+		}
+		pointsBySeller[sellerID] = append(pointsBySeller[sellerID], point)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for index := range detail.Sellers {
+		trend := pricetrend.Analyze(pointsBySeller[detail.Sellers[index].Seller])
+		detail.Sellers[index].PriceTrend = &trend
+	}
+	return nil
+}
+
+func scanPricePoint(rows pgx.Rows, sellerID *string, point *PricePoint) error {
+	return rows.Scan(
+		sellerID,
+		&point.PriceDate,
+		&point.PriceWithVat,
+		&point.ListPriceWithVat,
+		&point.CurrencyCode,
+		&point.ScrapedAt,
+		&point.SnapshotCount,
+		&point.OpeningPrice,
+		&point.MinPrice,
+		&point.MaxPrice,
+		&point.AvailabilityStatus,
+		&point.IsAvailable,
+		&point.IsPreorder,
+	)
+}
+
 func indexSellers(sellers []Seller) map[string]int {
 	indexes := make(map[string]int, len(sellers))
 	for index := range sellers {
@@ -3,6 +3,7 @@ package snapshots
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func assertQueryContains(t *testing.T, query string, expected string) {
@@ -16,7 +17,12 @@ func TestSellerMetadataQueryUsesCanonicalSlugAndSellerPriority(t *testing.T) {
 	assertQueryContains(t, sellerMetadataQuery, "public.canonical_product_slug")
 	assertQueryContains(t, sellerMetadataQuery, "public.catalog_slug_seller_state")
 	assertQueryContains(t, sellerMetadataQuery, "seller_state.product_name_normalized")
-	assertQueryContains(t, sellerMetadataQuery, "'tlamagames', 'tlamagase'")
+	assertQueryContains(t, sellerMetadataQuery, "left join public.catalog_sellers registry")
+	assertQueryContains(t, sellerMetadataQuery, "public.seller_priority(offer.seller) asc")
+	assertQueryContains(t, sellerMetadataQuery, "as discount_verdict")
+	assertQueryContains(t, sellerMetadataQuery, "public.catalog_seller_stale_after(offer.seller)")
+	assertQueryContains(t, sellerMetadataQuery, "registry.free_shipping_threshold::double precision")
+	assertQueryContains(t, sellerMetadataQuery, "coalesce(registry.pickup_options, '{}'::text[])")
 }
 
 func TestPriceHistoryQueryLimitsEachSellerIndependently(t *testing.T) {
@@ -26,11 +32,181 @@ func TestPriceHistoryQueryLimitsEachSellerIndependently(t *testing.T) {
 	assertQueryContains(t, priceHistoryQuery, "order by seller asc, price_date asc")
 }
 
+func TestPriceHistoryQuerySelectsIntradayColumns(t *testing.T) {
+	for _, column := range []string{
+		"opening_price", "min_price", "max_price", "availability_status", "is_available", "is_preorder",
+	} {
+		assertQueryContains(t, priceHistoryQuery, column)
+	}
+}
+
+func TestPricePointWithoutIntradayKeepsCompactFields(t *testing.T) {
+	price := 799.0
+	available := true
+	point := PricePoint{
+		PriceDate: "2026-07-11", PriceWithVat: &price,
+		OpeningPrice: &price, MinPrice: &price, MaxPrice: &price, IsAvailable: &available,
+	}.withoutIntraday()
+	if point.PriceWithVat == nil || point.PriceDate != "2026-07-11" {
+		t.Fatalf("compact fields were cleared: %#v", point)
+	}
+	if point.OpeningPrice != nil || point.MinPrice != nil || point.MaxPrice != nil || point.IsAvailable != nil {
+		t.Fatalf("intraday fields were kept: %#v", point)
+	}
+}
+
 func TestRecentDiscountsQueryKeepsSellerGranularity(t *testing.T) {
-	assertQueryContains(t, recentDiscountsQuery, "product_name_normalized")
-	assertQueryContains(t, recentDiscountsQuery, "seller")
-	assertQueryContains(t, recentDiscountsQuery, "latest_price < previous_price")
-	assertQueryContains(t, recentDiscountsQuery, "latest_price < list_price_with_vat")
+	query, args := buildRecentDiscountsQuery(DiscountFilters{Limit: 10})
+	assertQueryContains(t, query, "product_name_normalized")
+	assertQueryContains(t, query, "seller")
+	assertQueryContains(t, query, "latest_price < previous_price")
+	assertQueryContains(t, query, "latest_price < list_price_with_vat")
+	assertQueryContains(t, query, "limit $1")
+	if strings.Contains(query, "is distinct from 'inflated_reference'") {
+		t.Fatal("inflated discounts must be kept unless requested")
+	}
+	// One lookahead row decides whether another page exists.
+	if len(args) != 1 || args[0] != 11 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestRecentDiscountsQueryCanHideInflatedReferences(t *testing.T) {
+	query, _ := buildRecentDiscountsQuery(DiscountFilters{Limit: 10, HideInflated: true})
+	assertQueryContains(t, query, "where discount_verdict is distinct from 'inflated_reference'")
+}
+
+func TestRecentDiscountsQueryAppliesOfferFilters(t *testing.T) {
+	minDiscount := 15.0
+	maxPrice := 900.0
+	query, args := buildRecentDiscountsQuery(DiscountFilters{
+		Limit:              10,
+		Sellers:            []string{"tlamagames"},
+		Categories:         []string{"rodinna"},
+		Availability:       "available",
+		MinDiscountPercent: &minDiscount,
+		MaxPrice:           &maxPrice,
+	})
+	assertQueryContains(t, query, "and seller = any($1::text[])")
+	assertQueryContains(t, query, "coalesce(game_type_tags, '{}'::text[]) && $2::text[]")
+	assertQueryContains(t, query, "and is_available = true")
+	assertQueryContains(t, query, "and latest_price <= $3")
+	assertQueryContains(t, query, "where discount_percent >= $4")
+	assertQueryContains(t, query, "limit $5")
+	if strings.Index(query, "latest_price <= $3") > strings.Index(query, "classified_offer as") {
+		t.Fatal("offer filters must narrow seller state before verdicts are classified")
+	}
+	if len(args) != 5 || args[3] != minDiscount || args[4] != 11 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestRecentDiscountsQueryUsesSortSpecificKeyset(t *testing.T) {
+	changedAt := time.Date(2026, 7, 11, 13, 26, 17, 123456000, time.UTC)
+	query, args := buildRecentDiscountsQuery(DiscountFilters{
+		Limit:  10,
+		Cursor: &DiscountCursor{Sort: DiscountSortRecent, ChangedAt: changedAt, Slug: "alpha", Seller: "tlama"},
+	})
+	assertQueryContains(t, query, "(latest_scraped_at < $1::timestamptz or (latest_scraped_at = $1::timestamptz and (product_name_normalized, seller) > ($2, $3)))")
+	assertQueryContains(t, query, "order by latest_scraped_at desc, product_name_normalized asc, seller asc")
+	if len(args) != 4 || args[0] != changedAt {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	query, args = buildRecentDiscountsQuery(DiscountFilters{
+		Limit:  10,
+		Sort:   DiscountSortDiscount,
+		Cursor: &DiscountCursor{Sort: DiscountSortDiscount, DiscountPercent: 25.5, Slug: "alpha", Seller: "tlama"},
+	})
+	assertQueryContains(t, query, "coalesce(discount_percent, 0) < $1::double precision")
+	assertQueryContains(t, query, "order by coalesce(discount_percent, 0) desc, product_name_normalized asc, seller asc")
+	if args[0] != 25.5 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestRecentDiscountsQueryScoresDealsAgainstMediansAndLow(t *testing.T) {
+	query, args := buildRecentDiscountsQuery(DiscountFilters{
+		Limit:  10,
+		Sort:   DiscountSortDeal,
+		Cursor: &DiscountCursor{Sort: DiscountSortDeal, DealScore: 62.5, Slug: "alpha", Seller: "tlama"},
+	})
+	assertQueryContains(t, query, "history.seller = offer.seller")
+	assertQueryContains(t, query, "history.price_date >= offer.latest_scraped_at::date - 90")
+	assertQueryContains(t, query, "as market_median_price_90d")
+	assertQueryContains(t, query, "min(history.closing_price)::double precision as all_time_low_price")
+	assertQueryContains(t, query, "as deal_score")
+	assertQueryContains(t, query, "coalesce(deal_score, 0) < $1::double precision")
+	assertQueryContains(t, query, "order by coalesce(deal_score, 0) desc, product_name_normalized asc, seller asc")
+	if args[0] != 62.5 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	score := 62.5
+	page := paginateDiscounts([]RecentDiscount{
+		{ProductNameNormalized: "alpha", Seller: "tlama", DealScore: &score},
+		{ProductNameNormalized: "beta", Seller: "tlama"},
+	}, DiscountFilters{Limit: 1, Sort: DiscountSortDeal})
+	cursor, err := DecodeDiscountCursor(*page.NextCursor, DiscountSortDeal)
+	if err != nil || cursor.DealScore != score {
+		t.Fatalf("unexpected deal cursor: %#v (%v)", cursor, err)
+	}
+}
+
+func TestRecentDiscountsQueryLooksUpHistoryAfterTheLimitUnlessNeeded(t *testing.T) {
+	query, _ := buildRecentDiscountsQuery(DiscountFilters{Limit: 10})
+	limit := strings.Index(query, "limit $1")
+	if strings.Index(query, ") deal_history on true") < limit || strings.Index(query, ") recent_history on true") < limit {
+		t.Fatal("history lookups must only run for the returned page")
+	}
+	assertQueryContains(t, query, "from discount_page offer")
+
+	query, _ = buildRecentDiscountsQuery(DiscountFilters{Limit: 10, Sort: DiscountSortDeal, HideInflated: true})
+	limit = strings.Index(query, "limit $1")
+	if strings.Index(query, ") deal_history on true") > limit || strings.Index(query, ") recent_history on true") > limit {
+		t.Fatal("the deal sort and inflated filter must read history before the limit")
+	}
+	if strings.Count(query, ") deal_history on true") != 1 || strings.Count(query, ") recent_history on true") != 1 {
+		t.Fatal("each history lookup must run once")
+	}
+}
+
+func TestPaginateDiscountsEncodesCursorForRequestedSort(t *testing.T) {
+	changedAt := time.Date(2026, 7, 11, 13, 26, 17, 123456000, time.UTC)
+	percent := 20.0
+	rows := []RecentDiscount{
+		{ProductNameNormalized: "alpha", Seller: "tlama", DiscountPercent: &percent, changedAt: changedAt},
+		{ProductNameNormalized: "beta", Seller: "tlama", DiscountPercent: &percent, changedAt: changedAt},
+	}
+	page := paginateDiscounts(rows, DiscountFilters{Limit: 1})
+	if len(page.Rows) != 1 || page.NextCursor == nil {
+		t.Fatalf("unexpected page: %#v", page)
+	}
+	cursor, err := DecodeDiscountCursor(*page.NextCursor, DiscountSortRecent)
+	if err != nil || cursor.Slug != "alpha" || !cursor.ChangedAt.Equal(changedAt) {
+		t.Fatalf("unexpected cursor: %#v (%v)", cursor, err)
+	}
+	if _, err := DecodeDiscountCursor(*page.NextCursor, DiscountSortDiscount); err != ErrInvalidCursor {
+		t.Fatalf("cursor must not be reusable across sorts, got %v", err)
+	}
+	if _, err := DecodeDiscountCursor("not-base64!", DiscountSortRecent); err != ErrInvalidCursor {
+		t.Fatalf("expected invalid cursor, got %v", err)
+	}
+	if last := paginateDiscounts(rows, DiscountFilters{Limit: 2}); last.NextCursor != nil {
+		t.Fatal("expected final page to omit next cursor")
+	}
+}
+
+func TestDiscountVerdictComparesSameSellerRecentHistory(t *testing.T) {
+	recentQuery, _ := buildRecentDiscountsQuery(DiscountFilters{Limit: 10})
+	for _, query := range []string{sellerMetadataQuery, recentQuery} {
+		assertQueryContains(t, query, "history.seller = offer.seller")
+		assertQueryContains(t, query, "history.price_date >= offer.latest_scraped_at::date - 30")
+		assertQueryContains(t, query, "history.price_date < offer.latest_scraped_at::date")
+		assertQueryContains(t, query, "'"+DiscountVerdictNoRealChange+"'")
+		assertQueryContains(t, query, "'"+DiscountVerdictInflatedReference+"'")
+		assertQueryContains(t, query, "'"+DiscountVerdictGenuine+"'")
+	}
 }
 
 func TestIndexSellersInitializesHistory(t *testing.T) {
@@ -45,3 +221,141 @@ func TestIndexSellersInitializesHistory(t *testing.T) {
 		}
 	}
 }
+
+func TestPriceStatsQueryKeepsSellersSeparate(t *testing.T) {
+	assertQueryContains(t, priceStatsQuery, "public.canonical_product_slug")
+	assertQueryContains(t, priceStatsQuery, "partition by history.seller")
+	assertQueryContains(t, priceStatsQuery, "group by canonical_product_id, seller")
+	assertQueryContains(
+		t,
+		priceStatsQuery,
+		"price_date >= latest_price_date - 30 and price_date < latest_price_date",
+	)
+	assertQueryContains(t, priceStatsQuery, "percentile_cont(0.5)")
+}
+
+func TestSummarizeSellerStatsComparesWithinCurrency(t *testing.T) {
+	czk, eur := "CZK", "EUR"
+	summaries := summarizeSellerStats([]SellerPriceStats{
+		{
+			Seller: "alpha", CurrencyCode: &czk, CurrentPrice: floatPtr(899),
+			AllTimeLow: floatPtr(699), AllTimeHigh: floatPtr(999), LowestPrice30d: floatPtr(849),
+		},
+		{
+			Seller: "beta", CurrencyCode: &czk, CurrentPrice: floatPtr(799),
+			AllTimeLow: floatPtr(749), AllTimeHigh: floatPtr(1099),
+		},
+		{Seller: "gamma", CurrencyCode: &eur, CurrentPrice: floatPtr(30), AllTimeLow: floatPtr(25)},
+	})
+	if len(summaries) != 2 || *summaries[0].CurrencyCode != "CZK" || *summaries[1].CurrencyCode != "EUR" {
+		t.Fatalf("unexpected summaries: %#v", summaries)
+	}
+	czkSummary := summaries[0]
+	if czkSummary.SellerCount != 2 ||
+		*czkSummary.LowestCurrentPrice != 799 ||
+		*czkSummary.LowestCurrentBy != "beta" {
+		t.Fatalf("unexpected current price summary: %#v", czkSummary)
+	}
+	if *czkSummary.AllTimeLow != 699 ||
+		*czkSummary.AllTimeLowBy != "alpha" ||
+		*czkSummary.AllTimeHigh != 1099 {
+		t.Fatalf("unexpected all-time summary: %#v", czkSummary)
+	}
+	if *czkSummary.LowestPrice30d != 849 {
+		t.Fatalf("unexpected 30-day low: %v", *czkSummary.LowestPrice30d)
+	}
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func TestTrendHistoryQueryBoundsEachSellerToOneYear(t *testing.T) {
+	assertQueryContains(t, trendHistoryQuery, "public.canonical_product_slug(null, null, $1)")
+	assertQueryContains(t, trendHistoryQuery, "max(history.price_date) over (partition by history.seller)")
+	assertQueryContains(t, trendHistoryQuery, "where price_date > latest_price_date - 365")
+	assertQueryContains(t, trendHistoryQuery, "order by seller asc, price_date asc")
+}
+
+func TestSlugResolutionQueryUsesCanonicalFunctionAndCatalogState(t *testing.T) {
+	assertQueryContains(t, slugResolutionQuery, "public.canonical_product_slug(null, null, $1)")
+	assertQueryContains(t, slugResolutionQuery, "lower(trim(alias.product_name_normalized)) = lower(trim($1))")
+	assertQueryContains(t, slugResolutionQuery, "'seller_product_code'")
+	assertQueryContains(t, slugResolutionQuery, "else 'slug_only'")
+	assertQueryContains(t, slugResolutionQuery, "from public.catalog_slug_state state")
+}
+
+func TestPriceAtDateQueryCarriesLastKnownDayForward(t *testing.T) {
+	assertQueryContains(t, priceAtDateQuery, "public.canonical_product_slug(null, null, $1)")
+	assertQueryContains(t, priceAtDateQuery, "select distinct on (history.seller)")
+	assertQueryContains(t, priceAtDateQuery, "where history.price_date <= $2::date")
+	assertQueryContains(t, priceAtDateQuery, "history.price_date < $2::date")
+	assertQueryContains(t, priceAtDateQuery, "order by history.seller asc, history.price_date desc")
+}
+
+func TestProductHistoryExistsQueryChecksCatalogAndHistory(t *testing.T) {
+	assertQueryContains(t, productHistoryExistsQuery, "public.canonical_product_slug(null, null, $1)")
+	assertQueryContains(t, productHistoryExistsQuery, "from public.catalog_slug_state state")
+	assertQueryContains(t, productHistoryExistsQuery, "from public.catalog_daily_price_history history")
+}
+
+func TestAvailabilityHistoryQueryKeepsSellersSeparate(t *testing.T) {
+	assertQueryContains(t, availabilityHistoryQuery, "public.canonical_product_slug")
+	assertQueryContains(t, availabilityHistoryQuery, "history.availability_status")
+	assertQueryContains(t, availabilityHistoryQuery, "order by history.seller asc, history.price_date asc")
+}
+
+func TestBuildSellerAvailabilityEncodesRunsAndGaps(t *testing.T) {
+	availability := buildSellerAvailability("alpha", []availabilityDay{
+		{date: "2026-03-01", status: "available"},
+		{date: "2026-03-02", status: "available"},
+		{date: "2026-03-03", status: "unavailable"},
+		{date: "2026-03-04", status: "unavailable"},
+		{date: "2026-03-07", status: "unavailable"},
+		{date: "2026-03-08", status: "preorder"},
+		{date: "2026-03-09", status: ""},
+	})
+	expected := []AvailabilityRun{
+		{Status: "available", From: "2026-03-01", To: "2026-03-02", Days: 2},
+		{Status: "unavailable", From: "2026-03-03", To: "2026-03-04", Days: 2},
+		{Status: "unavailable", From: "2026-03-07", To: "2026-03-07", Days: 1},
+		{Status: "preorder", From: "2026-03-08", To: "2026-03-08", Days: 1},
+		{Status: "unknown", From: "2026-03-09", To: "2026-03-09", Days: 1},
+	}
+	if len(availability.Periods) != len(expected) {
+		t.Fatalf("unexpected periods: %#v", availability.Periods)
+	}
+	for index, period := range expected {
+		if availability.Periods[index] != period {
+			t.Fatalf("period %d: expected %#v, got %#v", index, period, availability.Periods[index])
+		}
+	}
+	summary := availability.Summary
+	if summary.TrackedDays != 7 || summary.AvailablePercent != 28.6 || summary.UnavailablePercent != 42.9 {
+		t.Fatalf("unexpected summary: %#v", summary)
+	}
+	if summary.PreorderPercent != 14.3 || summary.UnknownPercent != 14.3 {
+		t.Fatalf("unexpected summary: %#v", summary)
+	}
+}
+
+func TestNewArrivalsQueryPrefiltersHistoryByWindow(t *testing.T) {
+	query, args := buildNewArrivalsQuery(ArrivalFilters{
+		Limit:      25,
+		Sellers:    []string{"tlamagames"},
+		Categories: []string{"fantasy"},
+	})
+	assertQueryContains(t, query, "and seller = any($1::text[])")
+	assertQueryContains(t, query, "coalesce(genre_tags, '{}'::text[]) && $2::text[]")
+	assertQueryContains(t, query, "where history.price_date >= (now() - make_interval(days => $5))::date")
+	assertQueryContains(t, query, "group by history.canonical_product_id, history.seller")
+	assertQueryContains(t, query, "where first_seen.first_scraped_at >= now() - make_interval(days => $5)")
+	assertQueryContains(t, query, "and earlier.price_date < (now() - make_interval(days => $5))::date")
+	if strings.Contains(query, "join lateral") {
+		t.Fatal("new arrivals must not look up history per offer")
+	}
+	assertQueryContains(t, query, "limit $6;")
+	if args[4] != DefaultArrivalWindowDays || args[5] != 25 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
@@ -0,0 +1,40 @@
+package snapshots
+
+import "math"
+
+// SellerProfile is the registry metadata attached to each offer. Shipping is
+// nil for unregistered sellers.
+type SellerProfile struct {
+	DisplayName string          `json:"display_name"`
+	HomepageURL *string         `json:"homepage_url"`
+	LogoPath    *string         `json:"logo_path"`
+	Shipping    *SellerShipping `json:"shipping"`
+}
+
+// SellerShipping holds confirmed shipping terms. Null amounts mean the terms
+// are unknown, not free.
+type SellerShipping struct {
+	CurrencyCode          string   `json:"currency_code"`
+	FreeShippingThreshold *float64 `json:"free_shipping_threshold"`
+	FlatShippingCost      *float64 `json:"flat_shipping_cost"`
+	PickupOptions         []string `json:"pickup_options"`
+}
+
+// estimateTotalPrice adds delivery to an offer only when the registry states
+// it in the offer's currency: a price at or above the free-shipping threshold
+// ships free, otherwise the flat cost applies. Pickup is not assumed, and
+// anything else leaves the estimate unknown.
+func estimateTotalPrice(price *float64, currencyCode *string, shipping *SellerShipping) *float64 {
+	if price == nil || currencyCode == nil || shipping == nil || *currencyCode != shipping.CurrencyCode {
+		return nil
+	}
+	if shipping.FreeShippingThreshold != nil && *price >= *shipping.FreeShippingThreshold {
+		total := *price
+		return &total
+	}
+	if shipping.FlatShippingCost == nil {
+		return nil
+	}
+	total := math.Round((*price+*shipping.FlatShippingCost)*100) / 100
+	return &total
+}
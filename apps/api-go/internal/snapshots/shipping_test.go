@@ -0,0 +1,30 @@
+package snapshots
+
+import "testing"
+
+func TestEstimateTotalPriceOnlyUsesStatedShipping(t *testing.T) {
+	czk, eur := "CZK", "EUR"
+	threshold, flat := 1500.0, 89.0
+	complete := &SellerShipping{CurrencyCode: czk, FreeShippingThreshold: &threshold, FlatShippingCost: &flat}
+	tests := []struct {
+		name     string
+		price    float64
+		currency *string
+		shipping *SellerShipping
+		expected *float64
+	}{
+		{"below threshold adds flat cost", 799.9, &czk, complete, floatPtr(888.9)},
+		{"threshold reached ships free", 1500, &czk, complete, floatPtr(1500)},
+		{"threshold only stays unknown below it", 799, &czk, &SellerShipping{CurrencyCode: czk, FreeShippingThreshold: &threshold}, nil},
+		{"currency mismatch", 799, &eur, complete, nil},
+		{"unregistered seller", 799, &czk, nil, nil},
+		{"missing currency", 799, nil, complete, nil},
+	}
+	for _, testCase := range tests {
+		price := testCase.price
+		actual := estimateTotalPrice(&price, testCase.currency, testCase.shipping)
+		if (actual == nil) != (testCase.expected == nil) || (actual != nil && *actual != *testCase.expected) {
+			t.Fatalf("%s: expected %v, got %v", testCase.name, testCase.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package snapshots
+
+const (
+	AliasKindSlugOnly          = "slug_only"
+	AliasKindSellerProductCode = "seller_product_code"
+)
+
+// SlugResolution explains how a requested slug maps to its canonical product.
+// AliasKind is nil when the requested slug is already canonical.
+type SlugResolution struct {
+	RequestedSlug string  `json:"requested_slug"`
+	Canonical     string  `json:"canonical"`
+	IsAlias       bool    `json:"is_alias"`
+	AliasKind     *string `json:"alias_kind"`
+}
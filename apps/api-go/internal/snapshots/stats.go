@@ -0,0 +1,84 @@
+package snapshots
+
+import "sort"
+
+type ProductPriceStats struct {
+	ProductNameNormalized string              `json:"product_name_normalized"`
+	Sellers               []SellerPriceStats  `json:"sellers"`
+	Summary               []PriceStatsSummary `json:"summary"`
+}
+
+// SellerPriceStats is computed from one seller's daily history only. The
+// 30-day low covers the 30 days preceding the seller's latest checked day.
+type SellerPriceStats struct {
+	Seller          string   `json:"seller"`
+	CurrencyCode    *string  `json:"currency_code"`
+	CurrentPrice    *float64 `json:"current_price"`
+	AllTimeLow      *float64 `json:"all_time_low"`
+	AllTimeHigh     *float64 `json:"all_time_high"`
+	LowestPrice30d  *float64 `json:"lowest_price_30d"`
+	MedianPrice90d  *float64 `json:"median_price_90d"`
+	AveragePrice90d *float64 `json:"average_price_90d"`
+	TrackedDays     int32    `json:"tracked_days"`
+	FirstPriceDate  string   `json:"first_price_date"`
+	LastPriceDate   string   `json:"last_price_date"`
+}
+
+// PriceStatsSummary compares sellers that price in the same currency.
+type PriceStatsSummary struct {
+	CurrencyCode       *string  `json:"currency_code"`
+	SellerCount        int      `json:"seller_count"`
+	LowestCurrentPrice *float64 `json:"lowest_current_price"`
+	LowestCurrentBy    *string  `json:"lowest_current_seller"`
+	AllTimeLow         *float64 `json:"all_time_low"`
+	AllTimeLowBy       *string  `json:"all_time_low_seller"`
+	AllTimeHigh        *float64 `json:"all_time_high"`
+	LowestPrice30d     *float64 `json:"lowest_price_30d"`
+}
+
+func summarizeSellerStats(sellers []SellerPriceStats) []PriceStatsSummary {
+	byCurrency := make(map[string]*PriceStatsSummary)
+	currencies := make([]string, 0, 2)
+	for index := range sellers {
+		seller := &sellers[index]
+		currency := ""
+		if seller.CurrencyCode != nil {
+			currency = *seller.CurrencyCode
+		}
+		summary, exists := byCurrency[currency]
+		if !exists {
+			summary = &PriceStatsSummary{CurrencyCode: seller.CurrencyCode}
+			byCurrency[currency] = summary
+			currencies = append(currencies, currency)
+		}
+		summary.SellerCount++
+		if lowerPrice(seller.CurrentPrice, summary.LowestCurrentPrice) {
+			summary.LowestCurrentPrice = seller.CurrentPrice
+			summary.LowestCurrentBy = &seller.Seller
+		}
+		if lowerPrice(seller.AllTimeLow, summary.AllTimeLow) {
+			summary.AllTimeLow = seller.AllTimeLow
+			summary.AllTimeLowBy = &seller.Seller
+		}
+		if higherPrice(seller.AllTimeHigh, summary.AllTimeHigh) {
+			summary.AllTimeHigh = seller.AllTimeHigh
+		}
+		if lowerPrice(seller.LowestPrice30d, summary.LowestPrice30d) {
+			summary.LowestPrice30d = seller.LowestPrice30d
+		}
+	}
+	sort.Strings(currencies)
+	summaries := make([]PriceStatsSummary, 0, len(currencies))
+	for _, currency := range currencies {
+		summaries = append(summaries, *byCurrency[currency])
+	}
+	return summaries
+}
+
+func lowerPrice(candidate *float64, current *float64) bool {
+	return candidate != nil && (current == nil || *candidate < *current)
+}
+
+func higherPrice(candidate *float64, current *float64) bool {
+	return candidate != nil && (current == nil || *candidate > *current)
+}
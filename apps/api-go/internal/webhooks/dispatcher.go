@@ -0,0 +1,292 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultPollInterval = 10 * time.Second
+	DefaultTimeout      = 10 * time.Second
+	DefaultMaxAttempts  = 8
+	// RetentionPeriod bounds how long events, including dead deliveries,
+	// stay queryable from the admin API.
+	RetentionPeriod = 30 * 24 * time.Hour
+
+	claimBatchSize   = 20
+	concurrency      = 4
+	backoffBase      = time.Minute
+	backoffCap       = 6 * time.Hour
+	pruneInterval    = time.Hour
+	maxErrorLength   = 500
+	maxDrainedBody   = 4 << 10
+	signatureVersion = "sha256="
+)
+
+const (
+	statusPending   = "pending"
+	statusDelivered = "delivered"
+	statusDead      = "dead"
+)
+
+type deliveryStore interface {
+	claim(ctx context.Context, limit int, lease time.Duration) ([]delivery, error)
+	record(ctx context.Context, result attemptResult) error
+	prune(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// Event is the JSON envelope posted to subscribers. Data carries the payload
+// the read-model trigger recorded.
+type Event struct {
+	ID         int64           `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+type delivery struct {
+	ID      int64
+	Attempt int
+	URL     string
+	Secret  string
+	Event   Event
+}
+
+type attemptResult struct {
+	DeliveryID    int64
+	Attempt       int
+	Status        string
+	StatusCode    *int
+	Error         *string
+	Duration      time.Duration
+	NextAttemptAt *time.Time
+}
+
+// Dispatcher posts queued webhook deliveries. Every instance may run one; the
+// claim query leases rows with skip locked so a delivery is attempted by one
+// dispatcher at a time. Delivery is at least once: subscribers deduplicate on
+// the X-Tlamasite-Delivery header.
+type Dispatcher struct {
+	store       deliveryStore
+	client      *http.Client
+	interval    time.Duration
+	timeout     time.Duration
+	maxAttempts int
+	now         func() time.Time
+	lastPrune   time.Time
+}
+
+func NewDispatcher(
+	repository *Repository,
+	client *http.Client,
+	interval time.Duration,
+	maxAttempts int,
+) *Dispatcher {
+	return newDispatcher(repository, client, interval, maxAttempts)
+}
+
+func newDispatcher(
+	store deliveryStore,
+	client *http.Client,
+	interval time.Duration,
+	maxAttempts int,
+) *Dispatcher {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	timeout := client.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Dispatcher{
+		store:       store,
+		client:      client,
+		interval:    interval,
+		timeout:     timeout,
+		maxAttempts: maxAttempts,
+		now:         time.Now,
+	}
+}
+
+// Run dispatches immediately and then on every interval until ctx is done.
+// A full batch is followed by another claim straight away so a backlog drains
+// without waiting for the next tick.
+func (dispatcher *Dispatcher) Run(ctx context.Context) {
+	for {
+		full, err := dispatcher.dispatch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("webhook dispatch failed", "error", err)
+		}
+		dispatcher.pruneIfDue(ctx)
+		if full && err == nil {
+			continue
+		}
+		timer := time.NewTimer(dispatcher.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatch claims one batch and attempts it with bounded concurrency. It
+// reports whether the batch was full.
+func (dispatcher *Dispatcher) dispatch(ctx context.Context) (bool, error) {
+	deliveries, err := dispatcher.store.claim(ctx, claimBatchSize, dispatcher.lease())
+	if err != nil {
+		return false, err
+	}
+	slots := make(chan struct{}, concurrency)
+	var wait sync.WaitGroup
+	for _, claimed := range deliveries {
+		slots <- struct{}{}
+		wait.Go(func() {
+			defer func() { <-slots }()
+			dispatcher.attempt(ctx, claimed)
+		})
+	}
+	wait.Wait()
+	return len(deliveries) == claimBatchSize, ctx.Err()
+}
+
+// lease covers the request timeout of every delivery in a batch plus a
+// margin, since a slot may wait behind other requests before it starts.
+func (dispatcher *Dispatcher) lease() time.Duration {
+	return dispatcher.timeout*time.Duration(claimBatchSize/concurrency+1) + time.Minute
+}
+
+func (dispatcher *Dispatcher) attempt(ctx context.Context, claimed delivery) {
+	startedAt := time.Now()
+	statusCode, sendErr := dispatcher.send(ctx, claimed)
+	result := attemptResult{
+		DeliveryID: claimed.ID,
+		Attempt:    claimed.Attempt,
+		Status:     statusDelivered,
+		Duration:   time.Since(startedAt),
+	}
+	if statusCode != 0 {
+		result.StatusCode = &statusCode
+	}
+	if sendErr != nil {
+		message := truncate(sendErr.Error(), maxErrorLength)
+		result.Error = &message
+		result.Status = statusDead
+		if claimed.Attempt < dispatcher.maxAttempts {
+			next := dispatcher.now().Add(backoff(claimed.Attempt))
+			result.Status = statusPending
+			result.NextAttemptAt = &next
+		}
+		slog.Warn("webhook delivery failed",
+			"delivery_id", claimed.ID,
+			"attempt", claimed.Attempt,
+			"status", result.Status,
+			"error", sendErr,
+		)
+	}
+	// The attempt happened whether or not ctx is still alive; losing the
+	// record would resend it once the lease expires.
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dispatcher.timeout)
+	defer cancel()
+	if err := dispatcher.store.record(recordCtx, result); err != nil {
+		slog.Error("webhook attempt not recorded", "delivery_id", claimed.ID, "error", err)
+	}
+}
+
+func (dispatcher *Dispatcher) send(ctx context.Context, claimed delivery) (int, error) {
+	body, err := json.Marshal(claimed.Event)
+	if err != nil {
+		return 0, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, claimed.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(dispatcher.now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "tlamasite-webhooks/1")
+	request.Header.Set("X-Tlamasite-Event", claimed.Event.Type)
+	request.Header.Set("X-Tlamasite-Delivery", strconv.FormatInt(claimed.ID, 10))
+	request.Header.Set("X-Tlamasite-Timestamp", timestamp)
+	request.Header.Set("X-Tlamasite-Signature", Sign(claimed.Secret, timestamp, body))
+
+	response, err := dispatcher.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxDrainedBody))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("subscriber answered %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+func (dispatcher *Dispatcher) pruneIfDue(ctx context.Context) {
+	if dispatcher.now().Sub(dispatcher.lastPrune) < pruneInterval {
+		return
+	}
+	pruneCtx, cancel := context.WithTimeout(ctx, dispatcher.timeout)
+	defer cancel()
+	pruned, err := dispatcher.store.prune(pruneCtx, RetentionPeriod)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("webhook event pruning failed", "error", err)
+		}
+		return
+	}
+	dispatcher.lastPrune = dispatcher.now()
+	if pruned > 0 {
+		slog.Info("webhook events pruned", "events", pruned)
+	}
+}
+
+// Sign returns the X-Tlamasite-Signature value: an HMAC-SHA256 over the
+// timestamp header, a dot and the raw body, keyed with the subscription
+// secret. Binding the timestamp lets subscribers reject replays.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff doubles the delay after every failed attempt: 1m, 2m, 4m and so on,
+// capped at six hours.
+func backoff(attempt int) time.Duration {
+	delay := backoffBase
+	for range attempt - 1 {
+		delay *= 2
+		if delay >= backoffCap {
+			return backoffCap
+		}
+	}
+	return delay
+}
+
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	return strings.ToValidUTF8(value[:limit], "")
+}
@@ -0,0 +1,203 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeDeliveryStore struct {
+	mu       sync.Mutex
+	due      []delivery
+	recorded []attemptResult
+	pruned   int
+}
+
+func (store *fakeDeliveryStore) claim(_ context.Context, limit int, _ time.Duration) ([]delivery, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	claimed := store.due[:min(limit, len(store.due))]
+	store.due = store.due[len(claimed):]
+	return claimed, nil
+}
+
+func (store *fakeDeliveryStore) record(_ context.Context, result attemptResult) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.recorded = append(store.recorded, result)
+	return nil
+}
+
+func (store *fakeDeliveryStore) prune(context.Context, time.Duration) (int64, error) {
+	store.pruned++
+	return 0, nil
+}
+
+func testDelivery(id int64, attempt int, url string) delivery {
+	return delivery{
+		ID:      id,
+		Attempt: attempt,
+		URL:     url,
+		Secret:  "whsec_test",
+		Event: Event{
+			ID:         7,
+			Type:       "price_drop",
+			OccurredAt: time.Date(2026, 3, 8, 6, 0, 0, 0, time.UTC),
+			Data:       json.RawMessage(`{"slug":"azul","price":649}`),
+		},
+	}
+}
+
+// testClient trusts the test server's certificate but keeps the redirect
+// policy of NewClient; the dial guard would refuse the loopback listener.
+func testClient(server *httptest.Server) *http.Client {
+	client := server.Client()
+	client.CheckRedirect = NewClient(time.Second).CheckRedirect
+	return client
+}
+
+func TestDispatchSignsAndPostsEvents(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := &fakeDeliveryStore{due: []delivery{testDelivery(11, 1, server.URL+"/hooks")}}
+	dispatcher := newDispatcher(store, testClient(server), time.Second, 3)
+	dispatcher.now = func() time.Time { return time.Unix(1772950000, 0) }
+
+	if _, err := dispatcher.dispatch(context.Background()); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if received == nil {
+		t.Fatal("subscriber received nothing")
+	}
+	if received.Header.Get("X-Tlamasite-Event") != "price_drop" ||
+		received.Header.Get("X-Tlamasite-Delivery") != "11" ||
+		received.Header.Get("X-Tlamasite-Timestamp") != "1772950000" {
+		t.Fatalf("unexpected headers %#v", received.Header)
+	}
+	if received.Header.Get("X-Tlamasite-Signature") != Sign("whsec_test", "1772950000", body) {
+		t.Fatalf("signature does not cover the body: %q", received.Header.Get("X-Tlamasite-Signature"))
+	}
+	var envelope Event
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.ID != 7 || string(envelope.Data) != `{"slug":"azul","price":649}` {
+		t.Fatalf("unexpected envelope %s (%v)", body, err)
+	}
+	if len(store.recorded) != 1 || store.recorded[0].Status != statusDelivered || *store.recorded[0].StatusCode != 204 {
+		t.Fatalf("unexpected record %#v", store.recorded)
+	}
+}
+
+func TestDispatchBacksOffAndDeadLettersFailures(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "/elsewhere", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	now := time.Unix(1772950000, 0)
+	store := &fakeDeliveryStore{due: []delivery{
+		testDelivery(1, 2, server.URL),
+		testDelivery(2, 3, server.URL),
+		testDelivery(3, 1, server.URL+"/moved"),
+	}}
+	dispatcher := newDispatcher(store, testClient(server), time.Second, 3)
+	dispatcher.now = func() time.Time { return now }
+	if _, err := dispatcher.dispatch(context.Background()); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+
+	results := make(map[int64]attemptResult, len(store.recorded))
+	for _, result := range store.recorded {
+		results[result.DeliveryID] = result
+	}
+	retried := results[1]
+	if retried.Status != statusPending || !retried.NextAttemptAt.Equal(now.Add(2*time.Minute)) ||
+		*retried.StatusCode != 500 || !strings.Contains(*retried.Error, "500") {
+		t.Fatalf("unexpected retry %#v", retried)
+	}
+	if dead := results[2]; dead.Status != statusDead || dead.NextAttemptAt != nil {
+		t.Fatalf("expected the last attempt to dead-letter: %#v", dead)
+	}
+	if redirected := results[3]; redirected.Status != statusPending || *redirected.StatusCode != http.StatusFound {
+		t.Fatalf("redirects must count as failures: %#v", redirected)
+	}
+}
+
+func TestBackoffDoublesUpToCap(t *testing.T) {
+	for attempt, expected := range map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		4:  8 * time.Minute,
+		9:  256 * time.Minute,
+		10: 6 * time.Hour,
+		40: 6 * time.Hour,
+	} {
+		if got := backoff(attempt); got != expected {
+			t.Fatalf("attempt %d: expected %s, got %s", attempt, expected, got)
+		}
+	}
+}
+
+func TestClientRefusesNonPublicAddresses(t *testing.T) {
+	for _, address := range []string{
+		"127.0.0.1:443",
+		"10.0.0.5:443",
+		"169.254.169.254:80",
+		"100.64.1.1:443",
+		"[::1]:443",
+		"[::ffff:192.168.1.1]:443",
+		"[fd00::1]:443",
+	} {
+		if err := dialControl("tcp", address, nil); !errors.Is(err, ErrForbiddenAddress) {
+			t.Fatalf("%s: expected ErrForbiddenAddress, got %v", address, err)
+		}
+	}
+	if !isPublicAddr(netip.MustParseAddr("93.184.216.34")) {
+		t.Fatal("public address rejected")
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+	client := NewClient(time.Second)
+	client.Transport.(*http.Transport).TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+	if _, err := client.Get(server.URL); !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("expected loopback subscriber to be refused, got %v", err)
+	}
+}
+
+func TestClaimQueryLeasesAndCountsAttempts(t *testing.T) {
+	for _, fragment := range []string{
+		"delivery.status = 'pending'",
+		"for update skip locked",
+		"attempts = delivery.attempts + 1",
+		"next_attempt_at = now() + make_interval(secs => $2)",
+	} {
+		if !strings.Contains(claimDeliveriesQuery, fragment) {
+			t.Fatalf("claim query lacks %q", fragment)
+		}
+	}
+	if !strings.Contains(recordAttemptQuery, "and attempts = $2") ||
+		!strings.Contains(recordAttemptQuery, "insert into public.catalog_webhook_delivery_attempts") {
+		t.Fatal("record query must log the attempt and guard against requeues")
+	}
+	if !strings.Contains(pruneEventsQuery, "delivery.status = 'pending'") {
+		t.Fatal("prune query must keep events with pending deliveries")
+	}
+}
@@ -0,0 +1,135 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository runs as the maintenance role: subscription secrets and the
+// delivery queue are not readable by the public API role.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// claimDeliveriesQuery leases due deliveries by pushing next_attempt_at past
+// the request timeout and counting the attempt up front. A dispatcher that
+// dies mid-request leaves the row due again once the lease runs out.
+const claimDeliveriesQuery = `
+with due as (
+  select delivery.id
+  from public.catalog_webhook_deliveries delivery
+  where delivery.status = 'pending'
+    and delivery.next_attempt_at <= now()
+  order by delivery.next_attempt_at asc, delivery.id asc
+  limit $1
+  for update skip locked
+)
+update public.catalog_webhook_deliveries delivery
+set
+  attempts = delivery.attempts + 1,
+  last_attempt_at = now(),
+  next_attempt_at = now() + make_interval(secs => $2)
+from due, public.catalog_webhook_subscriptions subscription, public.catalog_webhook_events event
+where delivery.id = due.id
+  and subscription.id = delivery.subscription_id
+  and event.id = delivery.event_id
+returning
+  delivery.id,
+  delivery.attempts,
+  subscription.url,
+  subscription.secret,
+  event.id,
+  event.event_type,
+  event.occurred_at,
+  event.payload;`
+
+// recordAttemptQuery logs the attempt and settles the delivery in one
+// statement. The attempts guard skips the update when an operator requeued
+// the delivery while the request was in flight.
+const recordAttemptQuery = `
+with logged as (
+  insert into public.catalog_webhook_delivery_attempts (
+    delivery_id, attempt, status_code, error, duration_ms
+  )
+  values ($1, $2, $3, $4, $5)
+)
+update public.catalog_webhook_deliveries
+set
+  status = $6::text,
+  last_status_code = $3,
+  last_error = $4,
+  next_attempt_at = coalesce($7::timestamptz, next_attempt_at),
+  delivered_at = case when $6::text = 'delivered' then now() end
+where id = $1
+  and attempts = $2;`
+
+// pruneEventsQuery drops old events with their deliveries and attempt logs,
+// keeping any event that still has a pending delivery.
+const pruneEventsQuery = `
+delete from public.catalog_webhook_events event
+where event.occurred_at < now() - make_interval(secs => $1)
+  and not exists (
+    select 1
+    from public.catalog_webhook_deliveries delivery
+    where delivery.event_id = event.id
+      and delivery.status = 'pending'
+  );`
+
+func (repository *Repository) claim(ctx context.Context, limit int, lease time.Duration) ([]delivery, error) {
+	rows, err := repository.db.Query(ctx, claimDeliveriesQuery, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]delivery, 0, limit)
+	for rows.Next() {
+		var claimed delivery
+		var payload []byte
+		if err := rows.Scan(
+			&claimed.ID,
+			&claimed.Attempt,
+			&claimed.URL,
+			&claimed.Secret,
+			&claimed.Event.ID,
+			&claimed.Event.Type,
+			&claimed.Event.OccurredAt,
+			&payload,
+		); err != nil {
+			return nil, err
+		}
+		claimed.Event.Data = json.RawMessage(payload)
+		deliveries = append(deliveries, claimed)
+	}
+	return deliveries, rows.Err()
+}
+
+func (repository *Repository) record(ctx context.Context, result attemptResult) error {
+	_, err := repository.db.Exec(
+		ctx,
+		recordAttemptQuery,
+		result.DeliveryID,
+		result.Attempt,
+		result.StatusCode,
+		result.Error,
+		result.Duration.Milliseconds(),
+		result.Status,
+		result.NextAttemptAt,
+	)
+	return err
+}
+
+func (repository *Repository) prune(ctx context.Context, olderThan time.Duration) (int64, error) {
+	tag, err := repository.db.Exec(ctx, pruneEventsQuery, olderThan.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
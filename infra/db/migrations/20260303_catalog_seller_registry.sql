@@ -0,0 +1,54 @@
+-- Seller registry. Priority drives API seller ordering, the primary seller of
+-- catalog_slug_state, and field-level presentation fallback, so partner shops
+-- can be promoted or demoted with a row update instead of a deploy.
+
+create table if not exists public.catalog_sellers (
+  seller text primary key,
+  priority integer not null default 100,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  constraint catalog_sellers_seller_lowercase check (seller = lower(trim(seller)))
+);
+
+insert into public.catalog_sellers (seller, priority)
+values
+  ('tlamagames', 1),
+  ('tlamagase', 2),
+  ('planetaher', 3)
+on conflict (seller) do nothing;
+
+-- Unregistered sellers keep the previous default of 100. The function now
+-- reads a table, so it is stable rather than immutable.
+create or replace function public.seller_priority(seller_id text)
+returns integer
+language sql
+stable
+as $$
+  select coalesce(
+    (
+      select registry.priority
+      from public.catalog_sellers registry
+      where registry.seller = lower(coalesce(seller_id, ''))
+    ),
+    100
+  );
+$$;
+
+revoke all privileges on table public.catalog_sellers from public;
+do $$
+declare
+  restricted_role text;
+begin
+  foreach restricted_role in array array['anon', 'authenticated'] loop
+    if exists (select 1 from pg_roles where rolname = restricted_role) then
+      execute format(
+        'revoke all privileges on table public.catalog_sellers from %I',
+        restricted_role
+      );
+    end if;
+  end loop;
+end $$;
+
+grant select on table public.catalog_sellers to tlamasite_api;
+grant select, insert, update, delete on table public.catalog_sellers
+to tlamasite_maintenance;
@@ -0,0 +1,22 @@
+-- Seller display metadata for the public seller directory. Names move here from
+-- the frontend so newly tracked shops only need a registry row.
+
+alter table public.catalog_sellers
+  add column if not exists display_name text;
+
+insert into public.catalog_sellers (seller, display_name)
+values
+  ('tlamagames', 'Tlama Games'),
+  ('tlamagase', 'TlamaGase'),
+  ('planetaher', 'Planeta Her'),
+  ('albi', 'Albi'),
+  ('imago', 'imago'),
+  ('knihydobrovsky', 'Knihy Dobrovský'),
+  ('ludopolis', 'Ludopolis'),
+  ('najada', 'Najáda'),
+  ('svet-her', 'Svět her'),
+  ('svether', 'Svět her')
+on conflict (seller) do update
+set
+  display_name = coalesce(public.catalog_sellers.display_name, excluded.display_name),
+  updated_at = now();
@@ -0,0 +1,17 @@
+-- Shop metadata and shipping terms for the seller registry. Nothing is seeded:
+-- shipping stays null until it is confirmed with the shop, and the API only
+-- estimates a total price from values that are set.
+
+alter table public.catalog_sellers
+  add column if not exists homepage_url text,
+  add column if not exists logo_path text,
+  add column if not exists shipping_currency_code text not null default 'CZK'
+    constraint catalog_sellers_shipping_currency_code_format
+    check (shipping_currency_code ~ '^[A-Z]{3}$'),
+  add column if not exists free_shipping_threshold numeric(12, 2)
+    constraint catalog_sellers_free_shipping_threshold_non_negative
+    check (free_shipping_threshold >= 0),
+  add column if not exists flat_shipping_cost numeric(12, 2)
+    constraint catalog_sellers_flat_shipping_cost_non_negative
+    check (flat_shipping_cost >= 0),
+  add column if not exists pickup_options text[] not null default '{}'::text[];
@@ -0,0 +1,78 @@
+-- Price watches: "tell me when this game drops below X". The API creates and
+-- confirms watches itself, so this is the one relation the API role may write.
+-- Confirm and unsubscribe tokens are not stored; links carry an HMAC of the
+-- watch id that only the API can verify.
+
+create table if not exists public.catalog_price_watches (
+  id bigserial primary key,
+  product_name_normalized text not null,
+  seller text,
+  target_price numeric(12, 2) not null
+    constraint catalog_price_watches_target_price_positive
+    check (target_price > 0),
+  currency_code text not null default 'CZK'
+    constraint catalog_price_watches_currency_code_format
+    check (currency_code ~ '^[A-Z]{3}$'),
+  email text not null
+    constraint catalog_price_watches_email_lowercase
+    check (email = lower(trim(email))),
+  confirmed_at timestamptz,
+  last_notified_at timestamptz,
+  last_notified_price numeric(12, 2),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  constraint catalog_price_watches_seller_lowercase
+    check (seller is null or seller = lower(trim(seller)))
+);
+
+-- One watch per address, product and seller scope; a repeated request
+-- replaces the target and asks for confirmation again.
+create unique index if not exists catalog_price_watches_subscription_idx
+  on public.catalog_price_watches (email, product_name_normalized, coalesce(seller, ''));
+
+create index if not exists catalog_price_watches_confirmed_product_idx
+  on public.catalog_price_watches (product_name_normalized)
+  where confirmed_at is not null;
+
+create index if not exists catalog_price_watches_pending_idx
+  on public.catalog_price_watches (created_at)
+  where confirmed_at is null;
+
+-- The evaluator polls the newest seller-state write to notice a finished
+-- refresh without a queue between the refresh job and the API.
+create index if not exists catalog_slug_seller_state_updated_at_idx
+  on public.catalog_slug_seller_state (updated_at);
+
+revoke all privileges on table public.catalog_price_watches from public;
+revoke all privileges on sequence public.catalog_price_watches_id_seq from public;
+do $$
+declare
+  restricted_role text;
+begin
+  foreach restricted_role in array array['anon', 'authenticated'] loop
+    if exists (select 1 from pg_roles where rolname = restricted_role) then
+      execute format(
+        'revoke all privileges on table public.catalog_price_watches from %I',
+        restricted_role
+      );
+      execute format(
+        'revoke all privileges on sequence public.catalog_price_watches_id_seq from %I',
+        restricted_role
+      );
+    end if;
+  end loop;
+end $$;
+
+grant select, insert, update, delete on table public.catalog_price_watches
+to tlamasite_api;
+grant usage, select on sequence public.catalog_price_watches_id_seq
+to tlamasite_api;
+
+alter table public.catalog_price_watches enable row level security;
+
+drop policy if exists catalog_price_watches_api on public.catalog_price_watches;
+create policy catalog_price_watches_api
+on public.catalog_price_watches for all
+to tlamasite_api
+using (true)
+with check (true);
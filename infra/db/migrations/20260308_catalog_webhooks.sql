@@ -0,0 +1,225 @@
+-- Outbound webhooks. Row triggers on the read models record price_drop,
+-- back_in_stock and new_product events while the refresh writes them, and fan
+-- each event out to one delivery per interested subscription. The API
+-- dispatcher signs and posts deliveries under the maintenance role; the public
+-- API role cannot read subscription secrets.
+
+create table if not exists public.catalog_webhook_subscriptions (
+  id bigserial primary key,
+  url text not null
+    constraint catalog_webhook_subscriptions_url_https
+    check (url ~ '^https://'),
+  event_types text[] not null
+    constraint catalog_webhook_subscriptions_event_types_known
+    check (
+      cardinality(event_types) > 0
+      and event_types <@ array['price_drop', 'back_in_stock', 'new_product']::text[]
+    ),
+  secret text not null,
+  description text,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create table if not exists public.catalog_webhook_events (
+  id bigserial primary key,
+  event_type text not null,
+  product_name_normalized text not null,
+  seller text,
+  payload jsonb not null,
+  occurred_at timestamptz not null default now()
+);
+
+create index if not exists catalog_webhook_events_occurred_at_idx
+  on public.catalog_webhook_events (occurred_at);
+
+-- status moves pending -> delivered, or pending -> dead once the dispatcher
+-- gives up. Dead deliveries stay until an operator retries or they age out.
+create table if not exists public.catalog_webhook_deliveries (
+  id bigserial primary key,
+  subscription_id bigint not null
+    references public.catalog_webhook_subscriptions (id) on delete cascade,
+  event_id bigint not null
+    references public.catalog_webhook_events (id) on delete cascade,
+  status text not null default 'pending'
+    constraint catalog_webhook_deliveries_status_known
+    check (status in ('pending', 'delivered', 'dead')),
+  attempts integer not null default 0,
+  next_attempt_at timestamptz not null default now(),
+  last_attempt_at timestamptz,
+  last_status_code integer,
+  last_error text,
+  delivered_at timestamptz,
+  created_at timestamptz not null default now(),
+  constraint catalog_webhook_deliveries_once unique (subscription_id, event_id)
+);
+
+create index if not exists catalog_webhook_deliveries_due_idx
+  on public.catalog_webhook_deliveries (next_attempt_at, id)
+  where status = 'pending';
+
+create index if not exists catalog_webhook_deliveries_subscription_idx
+  on public.catalog_webhook_deliveries (subscription_id, id desc);
+
+create index if not exists catalog_webhook_deliveries_event_idx
+  on public.catalog_webhook_deliveries (event_id);
+
+create table if not exists public.catalog_webhook_delivery_attempts (
+  id bigserial primary key,
+  delivery_id bigint not null
+    references public.catalog_webhook_deliveries (id) on delete cascade,
+  attempt integer not null,
+  status_code integer,
+  error text,
+  duration_ms integer not null,
+  attempted_at timestamptz not null default now()
+);
+
+create index if not exists catalog_webhook_delivery_attempts_delivery_idx
+  on public.catalog_webhook_delivery_attempts (delivery_id, attempt);
+
+-- Events are only stored when a subscription wants them, so the log does not
+-- grow while nobody listens.
+create or replace function public.record_catalog_webhook_event(
+  p_event_type text,
+  p_slug text,
+  p_seller text,
+  p_payload jsonb
+) returns void
+language plpgsql
+as $$
+declare
+  v_event_id bigint;
+begin
+  if not exists (
+    select 1
+    from public.catalog_webhook_subscriptions subscription
+    where p_event_type = any(subscription.event_types)
+  ) then
+    return;
+  end if;
+
+  insert into public.catalog_webhook_events (
+    event_type, product_name_normalized, seller, payload
+  )
+  values (p_event_type, p_slug, p_seller, p_payload)
+  returning id into v_event_id;
+
+  insert into public.catalog_webhook_deliveries (subscription_id, event_id)
+  select subscription.id, v_event_id
+  from public.catalog_webhook_subscriptions subscription
+  where p_event_type = any(subscription.event_types);
+end;
+$$;
+
+create or replace function public.catalog_seller_state_webhook_events()
+returns trigger
+language plpgsql
+as $$
+begin
+  if new.currency_code is not distinct from old.currency_code then
+    perform public.record_catalog_webhook_event(
+      'price_drop',
+      new.product_name_normalized,
+      new.seller,
+      jsonb_build_object(
+        'slug', new.product_name_normalized,
+        'seller', new.seller,
+        'product_name', new.product_name,
+        'previous_price', old.latest_price,
+        'price', new.latest_price,
+        'currency_code', new.currency_code,
+        'url', new.source_url
+      )
+    );
+  end if;
+  return null;
+end;
+$$;
+
+create or replace function public.catalog_slug_state_webhook_events()
+returns trigger
+language plpgsql
+as $$
+begin
+  perform public.record_catalog_webhook_event(
+    case when tg_op = 'INSERT' then 'new_product' else 'back_in_stock' end,
+    new.product_name_normalized,
+    new.primary_seller,
+    jsonb_build_object(
+      'slug', new.product_name_normalized,
+      'seller', new.primary_seller,
+      'product_name', new.product_name,
+      'price', new.latest_price,
+      'currency_code', new.currency_code,
+      'url', new.source_url
+    )
+  );
+  return null;
+end;
+$$;
+
+drop trigger if exists catalog_seller_state_price_drop
+on public.catalog_slug_seller_state;
+create trigger catalog_seller_state_price_drop
+after update of latest_price on public.catalog_slug_seller_state
+for each row
+when (new.latest_price < old.latest_price)
+execute function public.catalog_seller_state_webhook_events();
+
+drop trigger if exists catalog_slug_state_new_product on public.catalog_slug_state;
+create trigger catalog_slug_state_new_product
+after insert on public.catalog_slug_state
+for each row
+execute function public.catalog_slug_state_webhook_events();
+
+drop trigger if exists catalog_slug_state_back_in_stock on public.catalog_slug_state;
+create trigger catalog_slug_state_back_in_stock
+after update of is_available on public.catalog_slug_state
+for each row
+when (new.is_available and not old.is_available)
+execute function public.catalog_slug_state_webhook_events();
+
+revoke execute on function public.record_catalog_webhook_event(text, text, text, jsonb)
+from public;
+revoke execute on function public.catalog_seller_state_webhook_events() from public;
+revoke execute on function public.catalog_slug_state_webhook_events() from public;
+grant execute on function public.record_catalog_webhook_event(text, text, text, jsonb)
+to tlamasite_maintenance;
+
+do $$
+declare
+  webhook_relation text;
+  restricted_role text;
+begin
+  foreach webhook_relation in array array[
+    'public.catalog_webhook_subscriptions',
+    'public.catalog_webhook_events',
+    'public.catalog_webhook_deliveries',
+    'public.catalog_webhook_delivery_attempts'
+  ] loop
+    execute format('revoke all privileges on table %s from public', webhook_relation);
+    foreach restricted_role in array array['anon', 'authenticated'] loop
+      if exists (select 1 from pg_roles where rolname = restricted_role) then
+        execute format(
+          'revoke all privileges on table %s from %I',
+          webhook_relation,
+          restricted_role
+        );
+      end if;
+    end loop;
+  end loop;
+end $$;
+
+grant select, insert, update, delete on table
+  public.catalog_webhook_subscriptions,
+  public.catalog_webhook_events,
+  public.catalog_webhook_deliveries,
+  public.catalog_webhook_delivery_attempts
+to tlamasite_maintenance;
+grant usage, select on sequence
+  public.catalog_webhook_subscriptions_id_seq,
+  public.catalog_webhook_events_id_seq,
+  public.catalog_webhook_deliveries_id_seq,
+  public.catalog_webhook_delivery_attempts_id_seq
+to tlamasite_maintenance;
@@ -0,0 +1,79 @@
+-- Anonymous collections: wishlists and game-night lists shared by link. Anyone
+-- with the public id may read a collection; edits need the edit token handed
+-- out once at creation. Only a SHA-256 digest of that token is stored.
+
+create table if not exists public.catalog_collections (
+  id bigserial primary key,
+  public_id text not null
+    constraint catalog_collections_public_id_format
+    check (public_id ~ '^[A-Za-z0-9_-]{16}$'),
+  edit_token_sha256 bytea not null
+    constraint catalog_collections_edit_token_sha256_length
+    check (octet_length(edit_token_sha256) = 32),
+  title text
+    constraint catalog_collections_title_length
+    check (title is null or char_length(title) between 1 and 120),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  constraint catalog_collections_public_id_key unique (public_id)
+);
+
+-- Items keep the canonical slug they were added under. Reads resolve it
+-- again, so aliases approved later still land on the canonical product.
+create table if not exists public.catalog_collection_items (
+  collection_id bigint not null
+    references public.catalog_collections (id) on delete cascade,
+  product_name_normalized text not null,
+  added_at timestamptz not null default now(),
+  primary key (collection_id, product_name_normalized)
+);
+
+create index if not exists catalog_collection_items_added_idx
+  on public.catalog_collection_items (collection_id, added_at);
+
+do $$
+declare
+  collection_relation text;
+  restricted_role text;
+begin
+  foreach collection_relation in array array[
+    'public.catalog_collections',
+    'public.catalog_collection_items'
+  ] loop
+    execute format('revoke all privileges on table %s from public', collection_relation);
+    foreach restricted_role in array array['anon', 'authenticated'] loop
+      if exists (select 1 from pg_roles where rolname = restricted_role) then
+        execute format(
+          'revoke all privileges on table %s from %I',
+          collection_relation,
+          restricted_role
+        );
+      end if;
+    end loop;
+  end loop;
+end $$;
+revoke all privileges on sequence public.catalog_collections_id_seq from public;
+
+grant select, insert, update, delete on table
+  public.catalog_collections,
+  public.catalog_collection_items
+to tlamasite_api;
+grant usage, select on sequence public.catalog_collections_id_seq
+to tlamasite_api;
+
+alter table public.catalog_collections enable row level security;
+alter table public.catalog_collection_items enable row level security;
+
+drop policy if exists catalog_collections_api on public.catalog_collections;
+create policy catalog_collections_api
+on public.catalog_collections for all
+to tlamasite_api
+using (true)
+with check (true);
+
+drop policy if exists catalog_collection_items_api on public.catalog_collection_items;
+create policy catalog_collection_items_api
+on public.catalog_collection_items for all
+to tlamasite_api
+using (true)
+with check (true);
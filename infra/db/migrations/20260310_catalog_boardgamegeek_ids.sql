@@ -0,0 +1,37 @@
+-- BoardGameGeek ids for catalog products. The collection import matches BGG
+-- exports by object id first, then by name. Rows are curated by maintainers;
+-- nothing here is fetched from BGG.
+
+create table if not exists public.catalog_boardgamegeek_ids (
+  boardgamegeek_id integer primary key
+    constraint catalog_boardgamegeek_ids_positive check (boardgamegeek_id > 0),
+  product_name_normalized text not null
+    constraint catalog_boardgamegeek_ids_slug_lowercase
+    check (product_name_normalized = lower(trim(product_name_normalized))),
+  source text not null default 'manual',
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+-- Several BGG ids may point at one product, e.g. a game and its reprint.
+create index if not exists catalog_boardgamegeek_ids_slug_idx
+  on public.catalog_boardgamegeek_ids (product_name_normalized);
+
+revoke all privileges on table public.catalog_boardgamegeek_ids from public;
+do $$
+declare
+  restricted_role text;
+begin
+  foreach restricted_role in array array['anon', 'authenticated'] loop
+    if exists (select 1 from pg_roles where rolname = restricted_role) then
+      execute format(
+        'revoke all privileges on table public.catalog_boardgamegeek_ids from %I',
+        restricted_role
+      );
+    end if;
+  end loop;
+end $$;
+
+grant select on table public.catalog_boardgamegeek_ids to tlamasite_api;
+grant select, insert, update, delete on table public.catalog_boardgamegeek_ids
+to tlamasite_maintenance;
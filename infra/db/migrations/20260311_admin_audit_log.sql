@@ -0,0 +1,39 @@
+-- Audit trail of mutating /admin/v1 calls. The admin pool runs as
+-- tlamasite_maintenance, which may append and read entries but not change or
+-- remove them.
+
+create table if not exists public.admin_audit_log (
+  id bigserial primary key,
+  occurred_at timestamptz not null default now(),
+  actor text not null,
+  method text not null,
+  route text not null,
+  path text not null,
+  status integer not null,
+  request_id text not null default '',
+  client_ip text not null default ''
+);
+
+create index if not exists admin_audit_log_occurred_idx
+  on public.admin_audit_log (occurred_at desc);
+create index if not exists admin_audit_log_actor_idx
+  on public.admin_audit_log (actor, occurred_at desc);
+
+revoke all privileges on table public.admin_audit_log from public;
+revoke all privileges on sequence public.admin_audit_log_id_seq from public;
+do $$
+declare
+  restricted_role text;
+begin
+  foreach restricted_role in array array['anon', 'authenticated', 'tlamasite_api'] loop
+    if exists (select 1 from pg_roles where rolname = restricted_role) then
+      execute format(
+        'revoke all privileges on table public.admin_audit_log from %I',
+        restricted_role
+      );
+    end if;
+  end loop;
+end $$;
+
+grant select, insert on table public.admin_audit_log to tlamasite_maintenance;
+grant usage, select on sequence public.admin_audit_log_id_seq to tlamasite_maintenance;
@@ -0,0 +1,40 @@
+-- One row per run of the refresh command (cmd/refresh). A row is inserted as
+-- 'running' before the refresh transaction starts, so failed and interrupted
+-- runs stay visible after the transaction rolls back.
+
+create table if not exists public.catalog_refresh_runs (
+  id bigserial primary key,
+  started_at timestamptz not null default now(),
+  finished_at timestamptz,
+  -- Lower bound passed to both refresh routines; null is a full refresh.
+  since timestamptz,
+  status text not null default 'running'
+    constraint catalog_refresh_runs_status_check
+    check (status in ('running', 'succeeded', 'failed')),
+  duration_ms bigint,
+  history_result jsonb,
+  state_result jsonb,
+  error text
+);
+
+create index if not exists catalog_refresh_runs_started_idx
+  on public.catalog_refresh_runs (started_at desc);
+
+revoke all privileges on table public.catalog_refresh_runs from public;
+revoke all privileges on sequence public.catalog_refresh_runs_id_seq from public;
+do $$
+declare
+  restricted_role text;
+begin
+  foreach restricted_role in array array['anon', 'authenticated', 'tlamasite_api'] loop
+    if exists (select 1 from pg_roles where rolname = restricted_role) then
+      execute format(
+        'revoke all privileges on table public.catalog_refresh_runs from %I',
+        restricted_role
+      );
+    end if;
+  end loop;
+end $$;
+
+grant select, insert, update on table public.catalog_refresh_runs to tlamasite_maintenance;
+grant usage, select on sequence public.catalog_refresh_runs_id_seq to tlamasite_maintenance;
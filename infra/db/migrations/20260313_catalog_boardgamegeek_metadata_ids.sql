@@ -0,0 +1,29 @@
+-- Seller feeds that know a product's BoardGameGeek object id put it in the
+-- offer metadata as boardgamegeek_id (or bgg_id). The collection import reads
+-- it from there; catalog_boardgamegeek_ids only overrides or fills gaps.
+
+-- Returns null for a missing or non-numeric id so a malformed feed value never
+-- matches anything.
+create or replace function public.catalog_boardgamegeek_id(metadata jsonb)
+returns bigint
+language sql
+immutable
+as $$
+  select case
+    when candidate ~ '^[0-9]{1,10}$' and candidate::bigint > 0 then candidate::bigint
+  end
+  from (
+    select trim(coalesce(metadata ->> 'boardgamegeek_id', metadata ->> 'bgg_id')) as candidate
+  ) source;
+$$;
+
+revoke execute on function public.catalog_boardgamegeek_id(jsonb) from public;
+grant execute on function public.catalog_boardgamegeek_id(jsonb)
+to tlamasite_api, tlamasite_maintenance;
+
+create index if not exists catalog_slug_seller_state_boardgamegeek_id_idx
+  on public.catalog_slug_seller_state (public.catalog_boardgamegeek_id(metadata))
+  where public.catalog_boardgamegeek_id(metadata) is not null;
+
+comment on table public.catalog_boardgamegeek_ids is
+  'Maintainer overrides for BoardGameGeek ids; they win over ids in seller offer metadata.';
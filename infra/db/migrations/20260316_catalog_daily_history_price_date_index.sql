@@ -0,0 +1,4 @@
+-- New arrivals read only the history rows dated inside the arrival window and
+-- check earlier rows through the primary key.
+create index if not exists catalog_daily_price_history_price_date_idx
+on public.catalog_daily_price_history (price_date);
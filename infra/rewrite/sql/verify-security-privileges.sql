@@ -38,6 +38,14 @@ select table_name, privilege_type
 from information_schema.table_privileges
 where table_schema = 'public'
   and grantee = 'tlamasite_api'
+  and not (
+    table_name in (
+      'catalog_price_watches',
+      'catalog_collections',
+      'catalog_collection_items'
+    )
+    and privilege_type in ('SELECT', 'INSERT', 'UPDATE', 'DELETE')
+  )
   and (
     privilege_type <> 'SELECT'
     or table_name not in (
@@ -46,7 +54,9 @@ where table_schema = 'public'
       'catalog_daily_price_history',
       'canonical_product_aliases',
       'catalog_slug_summary',
-      'catalog_slug_seller_summary'
+      'catalog_slug_seller_summary',
+      'catalog_sellers',
+      'catalog_boardgamegeek_ids'
     )
   );
 
@@ -55,7 +65,12 @@ from (values
   ('public.product_price_snapshots'),
   ('public.product_price_snapshots_partitioned'),
   ('public.canonical_products'),
-  ('public.canonical_product_alias_candidates')
+  ('public.canonical_product_alias_candidates'),
+  ('public.catalog_webhook_subscriptions'),
+  ('public.catalog_webhook_events'),
+  ('public.catalog_webhook_deliveries'),
+  ('public.catalog_webhook_delivery_attempts'),
+  ('public.admin_audit_log')
 ) as forbidden(forbidden_relation)
 where to_regclass(forbidden_relation) is not null
   and has_table_privilege(
@@ -67,5 +82,11 @@ where to_regclass(forbidden_relation) is not null
 select tablename
 from pg_tables
 where schemaname = 'public'
-  and tablename in ('catalog_slug_state', 'catalog_slug_seller_state')
+  and tablename in (
+    'catalog_slug_state',
+    'catalog_slug_seller_state',
+    'catalog_price_watches',
+    'catalog_collections',
+    'catalog_collection_items'
+  )
   and not rowsecurity;
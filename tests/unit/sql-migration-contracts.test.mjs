@@ -98,3 +98,201 @@ test("security migration keeps public, API, and maintenance capabilities separat
   assert.match(sql, /on public\.catalog_slug_state for select to anon, tlamasite_api/);
   assert.match(sql, /on public\.catalog_slug_state for all to tlamasite_maintenance/);
 });
+
+test("seller registry drives seller priority without widening API access", async () => {
+  const sql = await readNormalizedMigration(
+    "20260303_catalog_seller_registry.sql"
+  );
+
+  assert.match(sql, /create table if not exists public\.catalog_sellers/);
+  assert.match(sql, /\('tlamagames', 1\), \('tlamagase', 2\), \('planetaher', 3\)/);
+  assert.match(
+    sql,
+    /create or replace function public\.seller_priority\(seller_id text\) returns integer language sql stable/
+  );
+  assert.match(sql, /from public\.catalog_sellers registry/);
+  assert.match(sql, /grant select on table public\.catalog_sellers to tlamasite_api;/);
+  assert.doesNotMatch(sql, /grant [^;]*(insert|update|delete)[^;]* to tlamasite_api/);
+});
+
+test("seller display names extend the registry without changing priorities", async () => {
+  const sql = await readNormalizedMigration(
+    "20260304_catalog_seller_display_names.sql"
+  );
+
+  assert.match(sql, /alter table public\.catalog_sellers add column if not exists display_name text;/);
+  assert.match(sql, /on conflict \(seller\) do update set display_name = coalesce\(/);
+  assert.doesNotMatch(sql, /priority =/);
+  assert.doesNotMatch(sql, /grant /);
+});
+
+test("stale seller offers never select the catalog price or availability", async () => {
+  const sql = await readNormalizedMigration(
+    "20260305_catalog_seller_freshness.sql"
+  );
+
+  assert.match(
+    sql,
+    /add column if not exists stale_after interval not null default interval '72 hours'/
+  );
+  assert.match(sql, /order by offer\.is_stale, public\.seller_priority\(offer\.seller\)/);
+  assert.match(sql, /bool_or\(css\.is_available\) filter \(where not css\.is_stale\) as is_available/);
+  assert.match(sql, /where state\.fresh_until <= now\(\)/);
+  assert.match(
+    sql,
+    /grant execute on function public\.catalog_seller_stale_after\(text\) to tlamasite_api, tlamasite_maintenance;/
+  );
+  assert.doesNotMatch(sql, /grant [^;]*on table[^;]* to tlamasite_api/);
+});
+
+test("a slug with only stale offers carries no catalog price", async () => {
+  const sql = await readNormalizedMigration(
+    "20260314_catalog_stale_slug_prices.sql"
+  );
+
+  assert.match(sql, /order by offer\.is_stale, public\.seller_priority\(offer\.seller\)/);
+  assert.match(sql, /case when p\.is_stale then null else p\.latest_price end as latest_price/);
+  assert.match(sql, /case when p\.is_stale then null else p\.previous_price end as previous_price/);
+  assert.match(sql, /case when p\.is_stale then null else p\.price_movement end as price_movement/);
+  assert.match(
+    sql,
+    /set latest_price = null, previous_price = null, price_movement = null, updated_at = now\(\) where state\.latest_price is not null and not exists/
+  );
+  assert.doesNotMatch(sql, /grant /);
+});
+
+test("alias approvals refresh only the requested slugs", async () => {
+  const sql = await readNormalizedMigration(
+    "20260315_catalog_scoped_state_refresh.sql"
+  );
+
+  assert.match(
+    sql,
+    /create or replace function public\.refresh_catalog_state_scoped\( p_since timestamptz, p_slugs text\[\] \)/
+  );
+  assert.match(sql, /and \(p_slugs is null or s\.product_name_normalized = any\(p_slugs\)\)/);
+  assert.match(sql, /if p_slugs is null then .* where state\.fresh_until <= now\(\)/);
+  assert.match(sql, /select public\.canonical_product_slug\(null, null, requested\.slug\) from unnest\(p_slugs\)/);
+  assert.match(sql, /select public\.refresh_catalog_state_scoped\(p_since, null\);/);
+  assert.match(
+    sql,
+    /grant execute on function public\.refresh_catalog_state_scoped\(timestamptz, text\[\]\) to tlamasite_maintenance;/
+  );
+  assert.doesNotMatch(sql, /to tlamasite_api/);
+});
+
+test("seller shipping terms are registry columns without seeded values", async () => {
+  const sql = await readNormalizedMigration(
+    "20260306_catalog_seller_shipping.sql"
+  );
+
+  assert.match(sql, /add column if not exists free_shipping_threshold numeric\(12, 2\)/);
+  assert.match(sql, /add column if not exists flat_shipping_cost numeric\(12, 2\)/);
+  assert.match(sql, /add column if not exists pickup_options text\[\] not null default/);
+  assert.doesNotMatch(sql, /insert into/);
+  assert.doesNotMatch(sql, /grant /);
+});
+
+test("price watches are writable by the API role without stored tokens", async () => {
+  const sql = await readNormalizedMigration(
+    "20260307_catalog_price_watches.sql"
+  );
+
+  assert.match(sql, /create table if not exists public\.catalog_price_watches/);
+  assert.match(
+    sql,
+    /on public\.catalog_price_watches \(email, product_name_normalized, coalesce\(seller, ''\)\)/
+  );
+  assert.match(
+    sql,
+    /grant select, insert, update, delete on table public\.catalog_price_watches to tlamasite_api;/
+  );
+  assert.match(sql, /alter table public\.catalog_price_watches enable row level security;/);
+  assert.doesNotMatch(sql, /\w+_token(_hash)? (text|bytea)/);
+  assert.doesNotMatch(sql, /to (anon|authenticated)\b/);
+});
+
+test("webhook events come from read-model triggers and stay off the API role", async () => {
+  const sql = await readNormalizedMigration("20260308_catalog_webhooks.sql");
+
+  assert.match(
+    sql,
+    /after update of latest_price on public\.catalog_slug_seller_state for each row when \(new\.latest_price < old\.latest_price\)/
+  );
+  assert.match(sql, /after insert on public\.catalog_slug_state for each row/);
+  assert.match(
+    sql,
+    /after update of is_available on public\.catalog_slug_state for each row when \(new\.is_available and not old\.is_available\)/
+  );
+  assert.match(sql, /constraint catalog_webhook_deliveries_once unique \(subscription_id, event_id\)/);
+  assert.match(sql, /check \(status in \('pending', 'delivered', 'dead'\)\)/);
+  assert.doesNotMatch(sql, /to tlamasite_api/);
+  assert.doesNotMatch(sql, /security definer/);
+});
+
+test("collections store only a digest of the edit token", async () => {
+  const sql = await readNormalizedMigration("20260309_catalog_collections.sql");
+
+  assert.match(sql, /edit_token_sha256 bytea not null/);
+  assert.match(sql, /check \(octet_length\(edit_token_sha256\) = 32\)/);
+  assert.doesNotMatch(sql, /edit_token text/);
+  assert.match(
+    sql,
+    /references public\.catalog_collections \(id\) on delete cascade/
+  );
+  assert.match(
+    sql,
+    /grant select, insert, update, delete on table public\.catalog_collections, public\.catalog_collection_items to tlamasite_api;/
+  );
+  assert.match(sql, /alter table public\.catalog_collections enable row level security;/);
+  assert.match(sql, /alter table public\.catalog_collection_items enable row level security;/);
+  assert.doesNotMatch(sql, /to (anon|authenticated)\b/);
+});
+
+test("BoardGameGeek ids are curated by maintenance and only read by the API", async () => {
+  const sql = await readNormalizedMigration(
+    "20260310_catalog_boardgamegeek_ids.sql"
+  );
+
+  assert.match(sql, /create table if not exists public\.catalog_boardgamegeek_ids/);
+  assert.match(sql, /boardgamegeek_id integer primary key/);
+  assert.match(sql, /grant select on table public\.catalog_boardgamegeek_ids to tlamasite_api;/);
+  assert.doesNotMatch(sql, /grant [^;]*(insert|update|delete)[^;]* to tlamasite_api/);
+});
+
+test("BoardGameGeek ids are read from offer metadata through an indexed function", async () => {
+  const sql = await readNormalizedMigration(
+    "20260313_catalog_boardgamegeek_metadata_ids.sql"
+  );
+
+  assert.match(sql, /create or replace function public\.catalog_boardgamegeek_id\(metadata jsonb\)/);
+  assert.match(sql, /metadata ->> 'boardgamegeek_id', metadata ->> 'bgg_id'/);
+  assert.match(sql, /candidate ~ '\^\[0-9\]\{1,10\}\$'/);
+  assert.match(
+    sql,
+    /on public\.catalog_slug_seller_state \(public\.catalog_boardgamegeek_id\(metadata\)\)/
+  );
+  assert.match(sql, /revoke execute on function public\.catalog_boardgamegeek_id\(jsonb\) from public;/);
+});
+
+test("admin audit log is append-only for the maintenance role", async () => {
+  const sql = await readNormalizedMigration("20260311_admin_audit_log.sql");
+
+  assert.match(sql, /create table if not exists public\.admin_audit_log/);
+  assert.match(sql, /grant select, insert on table public\.admin_audit_log to tlamasite_maintenance;/);
+  assert.doesNotMatch(sql, /grant [^;]*(update|delete)[^;]* on table public\.admin_audit_log/);
+  assert.doesNotMatch(sql, /grant [^;]* to tlamasite_api/);
+});
+
+test("refresh runs are recorded by maintenance and stay off the API role", async () => {
+  const sql = await readNormalizedMigration("20260312_catalog_refresh_runs.sql");
+
+  assert.match(sql, /create table if not exists public\.catalog_refresh_runs/);
+  assert.match(sql, /check \(status in \('running', 'succeeded', 'failed'\)\)/);
+  assert.match(
+    sql,
+    /grant select, insert, update on table public\.catalog_refresh_runs to tlamasite_maintenance;/
+  );
+  assert.doesNotMatch(sql, /grant [^;]*delete[^;]* on table public\.catalog_refresh_runs/);
+  assert.doesNotMatch(sql, /grant [^;]* to tlamasite_api/);
+});